@@ -26,6 +26,7 @@ cp ${BGO_SPACE}/bin/linux_arm64/ssm-session-logger ${BGO_SPACE}/bin/debian_arm64
 cd ${BGO_SPACE}/bin/debian_arm64/debian/usr/bin/; strip --strip-unneeded amazon-ssm-agent; strip --strip-unneeded ssm-agent-worker; strip --strip-unneeded ssm-cli; strip --strip-unneeded ssm-document-worker; strip --strip-unneeded ssm-session-worker; strip --strip-unneeded ssm-session-logger; cd ~-
 cp ${BGO_SPACE}/seelog_unix.xml ${BGO_SPACE}/bin/debian_arm64/debian/etc/amazon/ssm/seelog.xml.template
 cp ${BGO_SPACE}/amazon-ssm-agent.json.template ${BGO_SPACE}/bin/debian_arm64/debian/etc/amazon/ssm/
+cp ${BGO_SPACE}/packaging/keys/ssm-agent-manifest-public-key.pem ${BGO_SPACE}/bin/debian_arm64/debian/etc/amazon/ssm/
 cp ${BGO_SPACE}/packaging/ubuntu/amazon-ssm-agent.conf ${BGO_SPACE}/bin/debian_arm64/debian/etc/init/
 cp ${BGO_SPACE}/packaging/ubuntu/amazon-ssm-agent.service ${BGO_SPACE}/bin/debian_arm64/debian/lib/systemd/system/
 
@@ -29,6 +29,7 @@ cp ${BGO_SPACE}/bin/linux_arm64/ssm-session-logger ${BGO_SPACE}/bin/linux_arm64/
 cp ${BGO_SPACE}/bin/linux_arm64/ssm-cli ${BGO_SPACE}/bin/linux_arm64/linux/usr/bin/
 cp ${BGO_SPACE}/seelog_unix.xml ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/seelog.xml.template
 cp ${BGO_SPACE}/amazon-ssm-agent.json.template ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/
+cp ${BGO_SPACE}/packaging/keys/ssm-agent-manifest-public-key.pem ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/
 cp ${BGO_SPACE}/RELEASENOTES.md ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/
 cp ${BGO_SPACE}/README.md ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/
 cp ${BGO_SPACE}/NOTICE.md ${BGO_SPACE}/bin/linux_arm64/linux/etc/amazon/ssm/
@@ -24,6 +24,7 @@ cp ${BUILD_FOLDER}/ssm-session-logger.exe ${PACKAGE_FOLDER}/ssm-session-logger.e
 cp ${BUILD_FOLDER}/ssm-cli.exe ${PACKAGE_FOLDER}/ssm-cli.exe
 cp ${BGO_SPACE}/seelog_windows.xml.template ${PACKAGE_FOLDER}/seelog.xml.template
 cp ${BGO_SPACE}/amazon-ssm-agent.json.template ${PACKAGE_FOLDER}/amazon-ssm-agent.json.template
+cp ${BGO_SPACE}/packaging/keys/ssm-agent-manifest-public-key.pem ${PACKAGE_FOLDER}/
 
 echo "Copying windows package config files"
 
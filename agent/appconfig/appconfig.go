@@ -105,13 +105,17 @@ func DefaultConfig() SsmagentConfig {
 		StopTimeoutMillis:   DefaultStopTimeoutMillis,
 	}
 	var ssm = SsmCfg{
-		HealthFrequencyMinutes:                DefaultSsmHealthFrequencyMinutes,
-		AssociationFrequencyMinutes:           DefaultSsmAssociationFrequencyMinutes,
-		AssociationRetryLimit:                 5,
-		CustomInventoryDefaultLocation:        DefaultCustomInventoryFolder,
-		AssociationLogsRetentionDurationHours: DefaultAssociationLogsRetentionDurationHours,
-		RunCommandLogsRetentionDurationHours:  DefaultRunCommandLogsRetentionDurationHours,
-		SessionLogsRetentionDurationHours:     DefaultSessionLogsRetentionDurationHours,
+		HealthFrequencyMinutes:                 DefaultSsmHealthFrequencyMinutes,
+		AssociationFrequencyMinutes:            DefaultSsmAssociationFrequencyMinutes,
+		AssociationRetryLimit:                  5,
+		CustomInventoryDefaultLocation:         DefaultCustomInventoryFolder,
+		AssociationLogsRetentionDurationHours:  DefaultAssociationLogsRetentionDurationHours,
+		RunCommandLogsRetentionDurationHours:   DefaultRunCommandLogsRetentionDurationHours,
+		SessionLogsRetentionDurationHours:      DefaultSessionLogsRetentionDurationHours,
+		LongRunningPluginsPollFrequencyMinutes: DefaultLongRunningPluginsPollFrequencyMinutes,
+		LongRunningPluginStartWorkers:          DefaultLongRunningPluginStartWorkers,
+		LongRunningPluginStopWorkers:           DefaultLongRunningPluginStopWorkers,
+		LongRunningPluginsHealthPort:           DefaultLongRunningPluginsHealthPort,
 	}
 	var agent = AgentInfo{
 		Name:                                    "amazon-ssm-agent",
@@ -123,6 +127,12 @@ func DefaultConfig() SsmagentConfig {
 		TelemetryMetricsNamespace:               DefaultTelemetryNamespace,
 		AuditExpirationDay:                      DefaultAuditExpirationDay,
 		LongRunningWorkerMonitorIntervalSeconds: defaultLongRunningWorkerMonitorIntervalSeconds,
+		SelfUpdateDownloadMaxAttempts:           DefaultSelfUpdateDownloadMaxAttempts,
+		SelfUpdateDownloadRetryDelaySeconds:     DefaultSelfUpdateDownloadRetryDelaySeconds,
+		SelfUpdateDownloadTimeoutSeconds:        DefaultSelfUpdateDownloadTimeoutSeconds,
+		UpdateDiskSpaceHeadroomMb:               DefaultUpdateDiskSpaceHeadroomMb,
+		UpdateMaxAttempts:                       DefaultUpdateMaxAttempts,
+		UpdateRetryDelayMillis:                  DefaultUpdateRetryDelayMillis,
 	}
 	var os = OsInfo{
 		Lang:    "en-US",
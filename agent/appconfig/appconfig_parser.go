@@ -38,6 +38,39 @@ func parser(config *SsmagentConfig) {
 		DefaultSsmSelfUpdateFrequencyDaysMin,
 		DefaultSsmSelfUpdateFrequencyDaysMax,
 		DefaultSsmSelfUpdateFrequencyDays)
+	config.Agent.SelfUpdateDownloadMaxAttempts = getNumericValue(
+		config.Agent.SelfUpdateDownloadMaxAttempts,
+		DefaultSelfUpdateDownloadMaxAttemptsMin,
+		DefaultSelfUpdateDownloadMaxAttemptsMax,
+		DefaultSelfUpdateDownloadMaxAttempts)
+	config.Agent.SelfUpdateDownloadRetryDelaySeconds = getNumericValue(
+		config.Agent.SelfUpdateDownloadRetryDelaySeconds,
+		DefaultSelfUpdateDownloadRetryDelaySecondsMin,
+		DefaultSelfUpdateDownloadRetryDelaySecondsMax,
+		DefaultSelfUpdateDownloadRetryDelaySeconds)
+	config.Agent.SelfUpdateDownloadTimeoutSeconds = getNumericValue(
+		config.Agent.SelfUpdateDownloadTimeoutSeconds,
+		DefaultSelfUpdateDownloadTimeoutSecondsMin,
+		DefaultSelfUpdateDownloadTimeoutSecondsMax,
+		DefaultSelfUpdateDownloadTimeoutSeconds)
+	config.Agent.UpdateDiskSpaceHeadroomMb = getNumericValue(
+		config.Agent.UpdateDiskSpaceHeadroomMb,
+		DefaultUpdateDiskSpaceHeadroomMbMin,
+		DefaultUpdateDiskSpaceHeadroomMbMax,
+		DefaultUpdateDiskSpaceHeadroomMb)
+	config.Agent.UpdateManifestLocation = getStringValue(config.Agent.UpdateManifestLocation, "")
+	config.Agent.UpdateMaxAttempts = getNumericValue(
+		config.Agent.UpdateMaxAttempts,
+		DefaultUpdateMaxAttemptsMin,
+		DefaultUpdateMaxAttemptsMax,
+		DefaultUpdateMaxAttempts)
+	config.Agent.UpdateRetryDelayMillis = getNumericValue(
+		config.Agent.UpdateRetryDelayMillis,
+		DefaultUpdateRetryDelayMillisMin,
+		DefaultUpdateRetryDelayMillisMax,
+		DefaultUpdateRetryDelayMillis)
+	config.Agent.UpdateProxyURL = getStringValue(config.Agent.UpdateProxyURL, "")
+	config.Agent.UpdateCABundlePath = getStringValue(config.Agent.UpdateCABundlePath, "")
 	config.Agent.AuditExpirationDay = getNumericValue(
 		config.Agent.AuditExpirationDay,
 		DefaultAuditExpirationDayMin,
@@ -74,6 +107,24 @@ func parser(config *SsmagentConfig) {
 		DefaultSsmAssociationFrequencyMinutesMin,
 		DefaultSsmAssociationFrequencyMinutesMax,
 		DefaultSsmAssociationFrequencyMinutes)
+	config.Ssm.LongRunningPluginsPollFrequencyMinutes = getNumericValue(
+		config.Ssm.LongRunningPluginsPollFrequencyMinutes,
+		DefaultLongRunningPluginsPollFrequencyMinutesMin,
+		DefaultLongRunningPluginsPollFrequencyMinutesMax,
+		DefaultLongRunningPluginsPollFrequencyMinutes)
+	config.Ssm.LongRunningPluginStartWorkers = getNumericValueAboveMin(
+		config.Ssm.LongRunningPluginStartWorkers,
+		DefaultLongRunningPluginWorkersMin,
+		DefaultLongRunningPluginStartWorkers)
+	config.Ssm.LongRunningPluginStopWorkers = getNumericValueAboveMin(
+		config.Ssm.LongRunningPluginStopWorkers,
+		DefaultLongRunningPluginWorkersMin,
+		DefaultLongRunningPluginStopWorkers)
+	config.Ssm.LongRunningPluginsHealthPort = getNumericValue(
+		config.Ssm.LongRunningPluginsHealthPort,
+		DefaultLongRunningPluginsHealthPortMin,
+		DefaultLongRunningPluginsHealthPortMax,
+		DefaultLongRunningPluginsHealthPort)
 	config.Ssm.AssociationLogsRetentionDurationHours = getNumericValueAboveMin(
 		config.Ssm.AssociationLogsRetentionDurationHours,
 		DefaultStateOrchestrationLogsRetentionDurationHoursMin,
@@ -122,3 +122,80 @@ func TestGetNumeric64Value(t *testing.T) {
 		assert.Equal(t, test.Output, output)
 	}
 }
+
+func TestParserValidatesLongRunningPluginSettings(t *testing.T) {
+	config := DefaultConfig()
+	config.Ssm.LongRunningPluginsPollFrequencyMinutes = DefaultLongRunningPluginsPollFrequencyMinutesMax + 1
+	config.Ssm.LongRunningPluginStartWorkers = 0
+	config.Ssm.LongRunningPluginStopWorkers = -1
+	config.Ssm.LongRunningPluginsHealthPort = 70000
+	parser(&config)
+
+	assert.Equal(t, DefaultLongRunningPluginsPollFrequencyMinutes, config.Ssm.LongRunningPluginsPollFrequencyMinutes)
+	assert.Equal(t, DefaultLongRunningPluginStartWorkers, config.Ssm.LongRunningPluginStartWorkers)
+	assert.Equal(t, DefaultLongRunningPluginStopWorkers, config.Ssm.LongRunningPluginStopWorkers)
+	assert.Equal(t, DefaultLongRunningPluginsHealthPort, config.Ssm.LongRunningPluginsHealthPort)
+
+	config.Ssm.LongRunningPluginsPollFrequencyMinutes = 1
+	config.Ssm.LongRunningPluginStartWorkers = 20
+	config.Ssm.LongRunningPluginStopWorkers = 1
+	config.Ssm.LongRunningPluginsHealthPort = 8080
+	parser(&config)
+
+	assert.Equal(t, 1, config.Ssm.LongRunningPluginsPollFrequencyMinutes)
+	assert.Equal(t, 20, config.Ssm.LongRunningPluginStartWorkers)
+	assert.Equal(t, 1, config.Ssm.LongRunningPluginStopWorkers)
+	assert.Equal(t, 8080, config.Ssm.LongRunningPluginsHealthPort)
+}
+
+func TestParserValidatesSelfUpdateDownloadSettings(t *testing.T) {
+	config := DefaultConfig()
+	config.Agent.SelfUpdateDownloadMaxAttempts = 0
+	config.Agent.SelfUpdateDownloadRetryDelaySeconds = DefaultSelfUpdateDownloadRetryDelaySecondsMax + 1
+	config.Agent.SelfUpdateDownloadTimeoutSeconds = 1
+	parser(&config)
+
+	assert.Equal(t, DefaultSelfUpdateDownloadMaxAttempts, config.Agent.SelfUpdateDownloadMaxAttempts)
+	assert.Equal(t, DefaultSelfUpdateDownloadRetryDelaySeconds, config.Agent.SelfUpdateDownloadRetryDelaySeconds)
+	assert.Equal(t, DefaultSelfUpdateDownloadTimeoutSeconds, config.Agent.SelfUpdateDownloadTimeoutSeconds)
+
+	config.Agent.SelfUpdateDownloadMaxAttempts = 3
+	config.Agent.SelfUpdateDownloadRetryDelaySeconds = 10
+	config.Agent.SelfUpdateDownloadTimeoutSeconds = 600
+	parser(&config)
+
+	assert.Equal(t, 3, config.Agent.SelfUpdateDownloadMaxAttempts)
+	assert.Equal(t, 10, config.Agent.SelfUpdateDownloadRetryDelaySeconds)
+	assert.Equal(t, 600, config.Agent.SelfUpdateDownloadTimeoutSeconds)
+}
+
+func TestParserValidatesUpdateDiskSpaceHeadroom(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, DefaultUpdateDiskSpaceHeadroomMb, config.Agent.UpdateDiskSpaceHeadroomMb)
+
+	config.Agent.UpdateDiskSpaceHeadroomMb = DefaultUpdateDiskSpaceHeadroomMbMin - 1
+	parser(&config)
+	assert.Equal(t, DefaultUpdateDiskSpaceHeadroomMb, config.Agent.UpdateDiskSpaceHeadroomMb)
+
+	config.Agent.UpdateDiskSpaceHeadroomMb = 1024
+	parser(&config)
+	assert.Equal(t, 1024, config.Agent.UpdateDiskSpaceHeadroomMb)
+}
+
+func TestParserValidatesUpdatePluginSettings(t *testing.T) {
+	config := DefaultConfig()
+	assert.Empty(t, config.Agent.UpdateManifestLocation)
+	config.Agent.UpdateMaxAttempts = DefaultUpdateMaxAttemptsMax + 1
+	config.Agent.UpdateRetryDelayMillis = 0
+	parser(&config)
+
+	assert.Equal(t, DefaultUpdateMaxAttempts, config.Agent.UpdateMaxAttempts)
+	assert.Equal(t, DefaultUpdateRetryDelayMillis, config.Agent.UpdateRetryDelayMillis)
+
+	config.Agent.UpdateMaxAttempts = 4
+	config.Agent.UpdateRetryDelayMillis = 5000
+	parser(&config)
+
+	assert.Equal(t, 4, config.Agent.UpdateMaxAttempts)
+	assert.Equal(t, 5000, config.Agent.UpdateRetryDelayMillis)
+}
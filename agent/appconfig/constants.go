@@ -39,6 +39,19 @@ const (
 	DefaultSsmHealthFrequencyMinutesMin = 5
 	DefaultSsmHealthFrequencyMinutesMax = 60
 
+	DefaultLongRunningPluginsPollFrequencyMinutes    = 15
+	DefaultLongRunningPluginsPollFrequencyMinutesMin = 1
+	DefaultLongRunningPluginsPollFrequencyMinutesMax = 60
+
+	DefaultLongRunningPluginStartWorkers = 5
+	DefaultLongRunningPluginStopWorkers  = 5
+	DefaultLongRunningPluginWorkersMin   = 1
+
+	// DefaultLongRunningPluginsHealthPort disables the health report of long running plugins
+	DefaultLongRunningPluginsHealthPort    = 0
+	DefaultLongRunningPluginsHealthPortMin = 0
+	DefaultLongRunningPluginsHealthPortMax = 65535
+
 	DefaultSsmAssociationFrequencyMinutes    = 10
 	DefaultSsmAssociationFrequencyMinutesMin = 5
 	DefaultSsmAssociationFrequencyMinutesMax = 60
@@ -47,6 +60,30 @@ const (
 	DefaultSsmSelfUpdateFrequencyDaysMin = 1 //Minimum frequency is 1 day
 	DefaultSsmSelfUpdateFrequencyDaysMax = 7 //Maximum frequency is 7 day
 
+	DefaultSelfUpdateDownloadMaxAttempts    = 5
+	DefaultSelfUpdateDownloadMaxAttemptsMin = 1
+	DefaultSelfUpdateDownloadMaxAttemptsMax = 10
+
+	DefaultSelfUpdateDownloadRetryDelaySeconds    = 2
+	DefaultSelfUpdateDownloadRetryDelaySecondsMin = 1
+	DefaultSelfUpdateDownloadRetryDelaySecondsMax = 60
+
+	DefaultSelfUpdateDownloadTimeoutSeconds    = 300 // 5 minutes
+	DefaultSelfUpdateDownloadTimeoutSecondsMin = 30
+	DefaultSelfUpdateDownloadTimeoutSecondsMax = 3600
+
+	DefaultUpdateDiskSpaceHeadroomMb    = 200 // room for the downloaded and the extracted packages
+	DefaultUpdateDiskSpaceHeadroomMbMin = 50
+	DefaultUpdateDiskSpaceHeadroomMbMax = 10240
+
+	DefaultUpdateMaxAttempts    = 2
+	DefaultUpdateMaxAttemptsMin = 1
+	DefaultUpdateMaxAttemptsMax = 10
+
+	DefaultUpdateRetryDelayMillis    = 1000
+	DefaultUpdateRetryDelayMillisMin = 100
+	DefaultUpdateRetryDelayMillisMax = 60000
+
 	//aws-ssm-agent bookkeeping constants
 	DefaultLocationOfPending     = "pending"
 	DefaultLocationOfCurrent     = "current"
@@ -79,10 +79,11 @@ const (
 	// Default Custom Inventory Inventory Folder
 	DefaultCustomInventoryFolder = DefaultDataStorePath + "inventory/custom"
 
-	DefaultSSMAgentWorker = DefaultProgramFolder + "bin/ssm-agent-worker"
-	DefaultDocumentWorker = DefaultProgramFolder + "bin/ssm-document-worker"
-	DefaultSessionWorker  = DefaultProgramFolder + "bin/ssm-session-worker"
-	DefaultSessionLogger  = DefaultProgramFolder + "bin/ssm-session-logger"
+	DefaultSSMAgentBinaryPath = DefaultProgramFolder + "bin/amazon-ssm-agent"
+	DefaultSSMAgentWorker     = DefaultProgramFolder + "bin/ssm-agent-worker"
+	DefaultDocumentWorker     = DefaultProgramFolder + "bin/ssm-document-worker"
+	DefaultSessionWorker      = DefaultProgramFolder + "bin/ssm-session-worker"
+	DefaultSessionLogger      = DefaultProgramFolder + "bin/ssm-session-logger"
 
 	// PowerShellPluginCommandName is the path of the powershell.exe to be used by the runPowerShellScript plugin
 	PowerShellPluginCommandName = "/usr/bin/powershell"
@@ -99,6 +99,7 @@ var PowerShellPluginCommandName string
 
 // DefaultProgramFolder is the default folder for SSM
 var DefaultProgramFolder = "/etc/amazon/ssm/"
+var DefaultSSMAgentBinaryPath = "/usr/bin/amazon-ssm-agent"
 var DefaultSSMAgentWorker = "/usr/bin/ssm-agent-worker"
 var DefaultDocumentWorker = "/usr/bin/ssm-document-worker"
 var DefaultSessionWorker = "/usr/bin/ssm-session-worker"
@@ -129,6 +130,7 @@ func init() {
 				DefaultSessionWorker = filepath.Join(curdir, "ssm-session-worker")
 				DefaultSessionLogger = filepath.Join(curdir, "ssm-session-logger")
 				DefaultSSMAgentWorker = filepath.Join(curdir, "ssm-agent-worker")
+				DefaultSSMAgentBinaryPath = filepath.Join(curdir, "amazon-ssm-agent")
 				DefaultProgramFolder = curdir
 			}
 		}
@@ -41,6 +41,16 @@ type SsmCfg struct {
 	AssociationLogsRetentionDurationHours int
 	RunCommandLogsRetentionDurationHours  int
 	SessionLogsRetentionDurationHours     int
+	// CloudWatchExeSHA256 is the expected sha256 of the CloudWatch executable, checked before each start when set
+	CloudWatchExeSHA256 string
+	// LongRunningPluginsPollFrequencyMinutes is the interval of the health check of long running plugins
+	LongRunningPluginsPollFrequencyMinutes int
+	// LongRunningPluginStartWorkers is the number of workers starting long running plugins
+	LongRunningPluginStartWorkers int
+	// LongRunningPluginStopWorkers is the number of workers stopping long running plugins
+	LongRunningPluginStopWorkers int
+	// LongRunningPluginsHealthPort is the localhost port serving the health report of long running plugins, 0 disables it
+	LongRunningPluginsHealthPort int
 }
 
 // AgentInfo represents metadata for amazon-ssm-agent
@@ -58,6 +68,39 @@ type AgentInfo struct {
 	TelemetryMetricsNamespace               string
 	LongRunningWorkerMonitorIntervalSeconds int
 	AuditExpirationDay                      int
+	// SelfUpdateDownloadMaxAttempts is the number of times self update attempts to download the updater
+	SelfUpdateDownloadMaxAttempts int
+	// SelfUpdateDownloadRetryDelaySeconds is the delay before the first retry of a download, it doubles with every retry
+	SelfUpdateDownloadRetryDelaySeconds int
+	// SelfUpdateDownloadTimeoutSeconds is the time after which a failed download is no longer retried
+	SelfUpdateDownloadTimeoutSeconds int
+	// UpdateDiskSpaceHeadroomMb is the free disk space aws:updateSsmAgent requires before it downloads an update
+	UpdateDiskSpaceHeadroomMb int
+	// UpdateManifestLocation overrides the location of the manifest aws:updateSsmAgent downloads, e.g. a mirror in
+	// air-gapped environments. It may contain the {Region} placeholder
+	UpdateManifestLocation string
+	// UpdateMaxAttempts is how often aws:updateSsmAgent attempts to download the manifest and to start the updater
+	UpdateMaxAttempts int
+	// UpdateRetryDelayMillis is the delay of aws:updateSsmAgent between attempts, a random jitter gets added to it
+	UpdateRetryDelayMillis int
+	// UpdateProxyURL is the proxy aws:updateSsmAgent sends its downloads through
+	UpdateProxyURL string
+	// UpdateCABundlePath is a PEM encoded bundle of CA certificates aws:updateSsmAgent trusts in addition to the
+	// system ones, e.g. the private CA of a proxy
+	UpdateCABundlePath string
+	// DisabledPlugins are the names of the worker and long running plugins that don't get registered, e.g. aws:updateSsmAgent
+	DisabledPlugins []string
+	// EagerPluginInitialization constructs all worker plugins when they get loaded instead of on their first execution
+	EagerPluginInitialization bool
+	// PluginConfigOverrides tune the default plugin config per plugin name, e.g. aws:runShellScript
+	PluginConfigOverrides map[string]PluginConfigOverride
+}
+
+// PluginConfigOverride overrides the default plugin config of a plugin, values that aren't set keep the defaults
+type PluginConfigOverride struct {
+	MaxStdoutLength       int
+	MaxStderrLength       int
+	OutputTruncatedSuffix string
 }
 
 // MgsConfig represents configuration for Message Gateway service
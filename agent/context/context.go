@@ -16,6 +16,10 @@
 package context
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
@@ -27,8 +31,16 @@ type T interface {
 	Log() log.T
 	AppConfig() appconfig.SsmagentConfig
 	With(context string) T
+	// WithFields returns a child context whose logger tags every line with the given fields, e.g. the document ID,
+	// next to the fields inherited from the parent. Fields override inherited fields of the same name.
+	WithFields(fields map[string]interface{}) T
 	CurrentContext() []string
 	AppConstants() *appconfig.AppConstants
+	// WithDeadline returns a copy of the context whose deadline is the earlier of the given one and the inherited one.
+	WithDeadline(deadline time.Time) T
+	// Deadline returns the time work done on behalf of the context should end by, ok is false if there is none.
+	// Child contexts created by With inherit the deadline.
+	Deadline() (deadline time.Time, ok bool)
 }
 
 // Default returns an empty context that use the default logger and appconfig.
@@ -44,22 +56,69 @@ func Default(logger log.T, ssmAppconfig appconfig.SsmagentConfig) T {
 
 type defaultContext struct {
 	context   []string
+	fields    map[string]interface{}
 	log       log.T
 	appconfig appconfig.SsmagentConfig
 	appconst  appconfig.AppConstants
+	deadline  time.Time
 }
 
 func (c *defaultContext) With(logContext string) T {
 	contextSlice := append(c.context, logContext)
+	return c.child(contextSlice, c.fields)
+}
+
+func (c *defaultContext) WithFields(fields map[string]interface{}) T {
+	merged := make(map[string]interface{}, len(c.fields)+len(fields))
+	for name, value := range c.fields {
+		merged[name] = value
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+	return c.child(c.context, merged)
+}
+
+// child returns a context with the given log context and fields, which inherits everything else
+func (c *defaultContext) child(contextSlice []string, fields map[string]interface{}) T {
+	logContext := append(append([]string{}, contextSlice...), formatFields(fields)...)
 	newContext := &defaultContext{
 		context:   contextSlice,
-		log:       c.log.WithContext(contextSlice...),
+		fields:    fields,
+		log:       c.log.WithContext(logContext...),
 		appconfig: c.appconfig,
 		appconst:  c.appconst,
+		deadline:  c.deadline,
 	}
 	return newContext
 }
 
+// formatFields returns the log context of the given fields in the [name=value] form, sorted by name
+func formatFields(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		formatted = append(formatted, fmt.Sprintf("[%v=%v]", name, fields[name]))
+	}
+	return formatted
+}
+
+func (c *defaultContext) WithDeadline(deadline time.Time) T {
+	newContext := *c
+	if c.deadline.IsZero() || deadline.Before(c.deadline) {
+		newContext.deadline = deadline
+	}
+	return &newContext
+}
+
+func (c *defaultContext) Deadline() (deadline time.Time, ok bool) {
+	return c.deadline, !c.deadline.IsZero()
+}
+
 func (c *defaultContext) Log() log.T {
 	return c.log
 }
@@ -0,0 +1,86 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package context defines a type that carries context specific data such as the logger.
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestContext returns a default context whose logger supports child contexts
+func newTestContext() T {
+	logger := log.NewMockLog()
+	logger.On("WithContext", mock.Anything).Return(logger)
+	return Default(logger, appconfig.SsmagentConfig{})
+}
+
+func TestDefaultHasNoDeadline(t *testing.T) {
+	ctx := newTestContext()
+
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	_, ok = ctx.With("[child]").Deadline()
+	assert.False(t, ok)
+}
+
+func TestChildContextsInheritDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	ctx := newTestContext().WithDeadline(deadline)
+
+	child := ctx.With("[child]").With("[grandchild]")
+
+	inherited, ok := child.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, inherited)
+	assert.Equal(t, []string{"[child]", "[grandchild]"}, child.CurrentContext())
+}
+
+func TestWithDeadlineOverridesLaterDeadline(t *testing.T) {
+	parentDeadline := time.Now().Add(time.Minute)
+	parent := newTestContext().WithDeadline(parentDeadline)
+
+	earlier := parentDeadline.Add(-30 * time.Second)
+	child := parent.With("[child]").WithDeadline(earlier)
+	deadline, ok := child.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, earlier, deadline)
+
+	// the parent keeps its own deadline
+	deadline, _ = parent.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+
+	// a child can't extend the deadline it inherited
+	deadline, _ = parent.WithDeadline(parentDeadline.Add(time.Hour)).Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
+
+func TestChildContextsCarryParentFields(t *testing.T) {
+	logger := log.NewMockLog()
+	logger.On("WithContext", mock.Anything).Return(logger)
+	ctx := Default(logger, appconfig.SsmagentConfig{})
+
+	parent := ctx.With("[processor]").WithFields(map[string]interface{}{"documentId": "doc", "pluginName": "aws:runShellScript"})
+	child := parent.WithFields(map[string]interface{}{"pluginName": "aws:runPowerShellScript", "step": 2}).With("[plugin]")
+
+	logger.AssertCalled(t, "WithContext", []string{"[processor]", "[documentId=doc]", "[pluginName=aws:runShellScript]"})
+	logger.AssertCalled(t, "WithContext", []string{"[processor]", "[plugin]", "[documentId=doc]", "[pluginName=aws:runPowerShellScript]", "[step=2]"})
+	assert.Equal(t, []string{"[processor]", "[plugin]"}, child.CurrentContext())
+	assert.Equal(t, map[string]interface{}{"documentId": "doc", "pluginName": "aws:runShellScript"}, parent.(*defaultContext).fields)
+}
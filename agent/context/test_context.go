@@ -15,6 +15,7 @@ package context
 
 import (
 	"strings"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/log"
@@ -41,8 +42,11 @@ func NewMockDefault() *Mock {
 	ctx.On("Log").Return(log)
 	ctx.On("AppConfig").Return(config)
 	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+	ctx.On("WithFields", mock.Anything).Return(ctx)
 	ctx.On("CurrentContext").Return([]string{})
 	ctx.On("AppConstants").Return(&appconst)
+	ctx.On("WithDeadline", mock.AnythingOfType("time.Time")).Return(ctx)
+	ctx.On("Deadline").Return(time.Time{}, false)
 	return ctx
 }
 
@@ -58,8 +62,11 @@ func NewMockDefaultWithContext(context []string) *Mock {
 	ctx.On("Log").Return(log)
 	ctx.On("AppConfig").Return(config)
 	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+	ctx.On("WithFields", mock.Anything).Return(ctx)
 	ctx.On("CurrentContext").Return(context)
 	ctx.On("AppConstants").Return(&appconst)
+	ctx.On("WithDeadline", mock.AnythingOfType("time.Time")).Return(ctx)
+	ctx.On("Deadline").Return(time.Time{}, false)
 	return ctx
 }
 
@@ -81,6 +88,12 @@ func (m *Mock) With(ctx string) T {
 	return args.Get(0).(T)
 }
 
+// WithFields mocks the WithFields function.
+func (m *Mock) WithFields(fields map[string]interface{}) T {
+	args := m.Called(fields)
+	return args.Get(0).(T)
+}
+
 // CurrentContext mocks the CurrentContext function.
 func (m *Mock) CurrentContext() []string {
 	args := m.Called()
@@ -92,3 +105,15 @@ func (m *Mock) AppConstants() *appconfig.AppConstants {
 	args := m.Called()
 	return args.Get(0).(*appconfig.AppConstants)
 }
+
+// WithDeadline mocks the WithDeadline function.
+func (m *Mock) WithDeadline(deadline time.Time) T {
+	args := m.Called(deadline)
+	return args.Get(0).(T)
+}
+
+// Deadline mocks the Deadline function.
+func (m *Mock) Deadline() (time.Time, bool) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Bool(1)
+}
@@ -16,6 +16,7 @@ package contracts
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/times"
@@ -120,3 +121,75 @@ func DocumentResultAggregator(log log.T,
 	return documentStatus, runtimeStatusCounts, runtimeStatusesFiltered
 
 }
+
+// AggregatedOutputSeparator separates the outputs of the plugins in an aggregated result
+const AggregatedOutputSeparator = "\n----------\n"
+
+// aggregateStatusPrecedence orders the statuses of plugins from the one taking precedence in an aggregated result,
+// it follows the precedence of DocumentResultAggregator: a pending reboot comes first since the document resumes
+// after it, then Failed > TimedOut > Cancelled, then plugins that didn't complete and finally the successful ones.
+var aggregateStatusPrecedence = []ResultStatus{
+	ResultStatusSuccessAndReboot,
+	ResultStatusFailed,
+	ResultStatusTimedOut,
+	ResultStatusCancelled,
+	ResultStatusInProgress,
+	ResultStatusNotStarted,
+	ResultStatusPassedAndReboot,
+	ResultStatusSuccess,
+	ResultStatusSkipped,
+}
+
+// statusPrecedence returns the rank of the status in aggregateStatusPrecedence, statuses it doesn't know rank last
+func statusPrecedence(status ResultStatus) int {
+	for i, s := range aggregateStatusPrecedence {
+		if s == status {
+			return i
+		}
+	}
+	return len(aggregateStatusPrecedence)
+}
+
+// AggregateResults merges the results of the plugins of a document into an overall result. The status is the one
+// taking precedence in aggregateStatusPrecedence and the code is the one of the first plugin with that status, a
+// failed result always has a non zero code. Outputs and errors are concatenated in order with
+// AggregatedOutputSeparator, and the result spans from the earliest start to the latest end of the plugins.
+func AggregateResults(results []PluginResult) (aggregated PluginResult) {
+	var outputs, stdouts, stderrs, errs []string
+	for i, result := range results {
+		if i == 0 || statusPrecedence(result.Status) < statusPrecedence(aggregated.Status) {
+			aggregated.Status = result.Status
+			aggregated.Code = result.Code
+		}
+		if !result.StartDateTime.IsZero() && (aggregated.StartDateTime.IsZero() || result.StartDateTime.Before(aggregated.StartDateTime)) {
+			aggregated.StartDateTime = result.StartDateTime
+		}
+		if result.EndDateTime.After(aggregated.EndDateTime) {
+			aggregated.EndDateTime = result.EndDateTime
+		}
+		if result.Output != nil {
+			if output := fmt.Sprintf("%v", result.Output); output != "" {
+				outputs = append(outputs, output)
+			}
+		}
+		if result.StandardOutput != "" {
+			stdouts = append(stdouts, result.StandardOutput)
+		}
+		if result.StandardError != "" {
+			stderrs = append(stderrs, result.StandardError)
+		}
+		if result.Error != "" {
+			errs = append(errs, result.Error)
+		}
+	}
+	if aggregated.Status == ResultStatusFailed && aggregated.Code == 0 {
+		aggregated.Code = 1
+	}
+	if len(outputs) > 0 {
+		aggregated.Output = strings.Join(outputs, AggregatedOutputSeparator)
+	}
+	aggregated.StandardOutput = strings.Join(stdouts, AggregatedOutputSeparator)
+	aggregated.StandardError = strings.Join(stderrs, AggregatedOutputSeparator)
+	aggregated.Error = strings.Join(errs, AggregatedOutputSeparator)
+	return aggregated
+}
@@ -160,3 +160,75 @@ func TestDocumentStatusCount(t *testing.T) {
 	_, statusCount, _ := DocumentResultAggregator(logger, "", input)
 	assert.Equal(t, statusCount, output)
 }
+
+func TestAggregateResultsStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		statuses []ResultStatus
+		codes    []int
+		status   ResultStatus
+		code     int
+	}{
+		{"no results", nil, nil, "", 0},
+		{"single success", []ResultStatus{ResultStatusSuccess}, []int{0}, ResultStatusSuccess, 0},
+		{"skipped is a form of success", []ResultStatus{ResultStatusSkipped, ResultStatusSuccess}, []int{0, 0}, ResultStatusSuccess, 0},
+		{"all skipped", []ResultStatus{ResultStatusSkipped, ResultStatusSkipped}, []int{0, 0}, ResultStatusSkipped, 0},
+		{"failed over success", []ResultStatus{ResultStatusSuccess, ResultStatusFailed}, []int{0, 2}, ResultStatusFailed, 2},
+		{"failed over timed out", []ResultStatus{ResultStatusTimedOut, ResultStatusFailed}, []int{124, 1}, ResultStatusFailed, 1},
+		{"failed over cancelled", []ResultStatus{ResultStatusCancelled, ResultStatusFailed, ResultStatusSuccess}, []int{143, 3, 0}, ResultStatusFailed, 3},
+		{"timed out over cancelled", []ResultStatus{ResultStatusCancelled, ResultStatusTimedOut}, []int{143, 124}, ResultStatusTimedOut, 124},
+		{"cancelled over success", []ResultStatus{ResultStatusSuccess, ResultStatusCancelled}, []int{0, 143}, ResultStatusCancelled, 143},
+		{"in progress over success", []ResultStatus{ResultStatusSuccess, ResultStatusInProgress}, []int{0, 0}, ResultStatusInProgress, 0},
+		{"in progress over not started", []ResultStatus{ResultStatusNotStarted, ResultStatusInProgress}, []int{0, 0}, ResultStatusInProgress, 0},
+		{"passed reboot over success", []ResultStatus{ResultStatusSuccess, ResultStatusPassedAndReboot}, []int{0, 0}, ResultStatusPassedAndReboot, 0},
+		{"pending reboot over failed", []ResultStatus{ResultStatusFailed, ResultStatusSuccessAndReboot}, []int{1, 3010}, ResultStatusSuccessAndReboot, 3010},
+		{"first code of the winning status", []ResultStatus{ResultStatusFailed, ResultStatusFailed}, []int{4, 5}, ResultStatusFailed, 4},
+		{"failed without code", []ResultStatus{ResultStatusSuccess, ResultStatusFailed}, []int{0, 0}, ResultStatusFailed, 1},
+		{"unknown status ranks last", []ResultStatus{"Unknown", ResultStatusSkipped}, []int{7, 0}, ResultStatusSkipped, 0},
+	}
+	for _, testCase := range testCases {
+		var results []PluginResult
+		for i, status := range testCase.statuses {
+			results = append(results, PluginResult{Status: status, Code: testCase.codes[i]})
+		}
+
+		aggregated := AggregateResults(results)
+
+		assert.Equal(t, testCase.status, aggregated.Status, testCase.name)
+		assert.Equal(t, testCase.code, aggregated.Code, testCase.name)
+	}
+}
+
+func TestAggregateResultsOutputsAndTimes(t *testing.T) {
+	results := []PluginResult{
+		{
+			Status:         ResultStatusSuccess,
+			Output:         "first output",
+			StandardOutput: "first stdout",
+			StartDateTime:  times.ParseIso8601UTC("2015-07-09T23:23:39.019Z"),
+			EndDateTime:    times.ParseIso8601UTC("2015-07-09T23:23:40.000Z"),
+		},
+		{
+			Status:        ResultStatusFailed,
+			Code:          1,
+			StandardError: "second stderr",
+			Error:         "second error",
+		},
+		{
+			Status:         ResultStatusSuccess,
+			Output:         "third output",
+			StandardOutput: "third stdout",
+			StartDateTime:  times.ParseIso8601UTC("2015-07-09T23:23:38.000Z"),
+			EndDateTime:    times.ParseIso8601UTC("2015-07-09T23:23:39.500Z"),
+		},
+	}
+
+	aggregated := AggregateResults(results)
+
+	assert.Equal(t, "first output"+AggregatedOutputSeparator+"third output", aggregated.Output)
+	assert.Equal(t, "first stdout"+AggregatedOutputSeparator+"third stdout", aggregated.StandardOutput)
+	assert.Equal(t, "second stderr", aggregated.StandardError)
+	assert.Equal(t, "second error", aggregated.Error)
+	assert.Equal(t, times.ParseIso8601UTC("2015-07-09T23:23:38.000Z"), aggregated.StartDateTime)
+	assert.Equal(t, times.ParseIso8601UTC("2015-07-09T23:23:40.000Z"), aggregated.EndDateTime)
+}
@@ -96,6 +96,7 @@ type Configuration struct {
 	RunAsEnabled                bool
 	RunAsUser                   string
 	ShellProfile                ShellProfileConfig
+	ExecutionTimeoutSeconds     int
 }
 
 // Plugin wraps the plugin configuration and plugin result.
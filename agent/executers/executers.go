@@ -43,6 +43,7 @@ type T interface {
 	//TODO: Remove Execute and rename NewExecute to Execute.
 	Execute(log.T, string, string, string, task.CancelFlag, int, string, []string, map[string]string) (io.Reader, io.Reader, int, []error)
 	NewExecute(log.T, string, io.Writer, io.Writer, task.CancelFlag, int, string, []string, map[string]string) (int, error)
+	NewExecuteAsUser(log.T, string, string, io.Writer, io.Writer, task.CancelFlag, int, string, []string, map[string]string) (int, error)
 	StartExe(log.T, string, io.Writer, io.Writer, task.CancelFlag, string, []string) (*os.Process, int, error)
 }
 
@@ -175,6 +176,24 @@ func (ShellCommandExecuter) NewExecute(
 	return
 }
 
+// NewExecuteAsUser executes a list of shell commands as the given user in the given working directory and provides
+// the stdout and stderr writers.
+func (ShellCommandExecuter) NewExecuteAsUser(
+	log log.T,
+	runAsUser string,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	cancelFlag task.CancelFlag,
+	executionTimeout int,
+	commandName string,
+	commandArguments []string,
+	envVars map[string]string,
+) (exitCode int, err error) {
+	exitCode, err = executeCommandAsUser(log, runAsUser, cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, envVars)
+	return
+}
+
 // StartExe starts a list of shell commands in the given working directory.
 // Returns process started, an exit code (0 if successfully launch, 1 if error launching process), and a set of errors.
 // The errors need not be fatal - the output streams may still have data
@@ -257,6 +276,22 @@ func ExecuteCommand(log log.T,
 	commandArguments []string,
 	envVars map[string]string,
 ) (exitCode int, err error) {
+	return executeCommandAsUser(log, "", cancelFlag, workingDir, stdoutWriter, stderrWriter, executionTimeout, commandName, commandArguments, envVars)
+}
+
+// executeCommandAsUser executes the given commands as the given user, the commands run as the user of the agent if
+// no user is given.
+func executeCommandAsUser(log log.T,
+	runAsUser string,
+	cancelFlag task.CancelFlag,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	executionTimeout int,
+	commandName string,
+	commandArguments []string,
+	envVars map[string]string,
+) (exitCode int, err error) {
 
 	stdoutInterruptable, stopStdout := newWriter(stdoutWriter)
 	stderrInterruptable, stopStderr := newWriter(stderrWriter)
@@ -284,6 +319,12 @@ func ExecuteCommand(log log.T,
 
 	// configure OS-specific process settings
 	prepareProcess(command)
+	if runAsUser != "" {
+		if err = prepareRunAsUser(command, runAsUser); err != nil {
+			exitCode = 1
+			return
+		}
+	}
 
 	// configure environment variables
 	prepareEnvironment(command, envVars)
@@ -448,9 +489,15 @@ func killProcessOnCancel(log log.T, command *exec.Cmd, cancelStdout chan bool, c
 	}
 }
 
-// prepareEnvironment adds ssm agent standard environment variables or environment variables defined by customer/other plugins to the command
+// prepareEnvironment adds ssm agent standard environment variables or environment variables defined by customer/other plugins to the command,
+// the latter replace the variables of the agent with the same name
 func prepareEnvironment(command *exec.Cmd, envVars map[string]string) {
-	env := os.Environ()
+	env := make([]string, 0)
+	for _, variable := range os.Environ() {
+		if _, isReplaced := envVars[strings.SplitN(variable, "=", 2)[0]]; !isReplaced {
+			env = append(env, variable)
+		}
+	}
 	for key, val := range envVars {
 		env = append(env, fmtEnvVariable(key, val))
 	}
@@ -15,12 +15,16 @@
 package executers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"testing"
 
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -93,6 +97,50 @@ func TestEnvironmentVariables_None(t *testing.T) {
 	assert.Empty(t, getEnvVariableValue(command.Env, envVarRegionName))
 }
 
+func TestEnvironmentVariables_ReplaceAgentEnvironment(t *testing.T) {
+	os.Setenv("AWS_SSM_TEST_VARIABLE", "agent")
+	defer os.Unsetenv("AWS_SSM_TEST_VARIABLE")
+
+	command := getTestCommand(t)
+	prepareEnvironment(command, map[string]string{"AWS_SSM_TEST_VARIABLE": "supplied"})
+
+	occurrences := 0
+	for _, variable := range command.Env {
+		if strings.HasPrefix(variable, "AWS_SSM_TEST_VARIABLE=") {
+			occurrences++
+		}
+	}
+	assert.Equal(t, 1, occurrences)
+	assert.Equal(t, "supplied", getEnvVariableValue(command.Env, "AWS_SSM_TEST_VARIABLE"))
+}
+
+// TestHelperProcess isn't a real test, it's the child process of TestExecuteCommandInjectsEnvironment printing
+// the environment variable it got
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("AWS_SSM_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Print(os.Getenv("AWS_SSM_TEST_BUILD_ID"))
+	os.Exit(0)
+}
+
+func TestExecuteCommandInjectsEnvironment(t *testing.T) {
+	os.Setenv("AWS_SSM_TEST_BUILD_ID", "agent")
+	defer os.Unsetenv("AWS_SSM_TEST_BUILD_ID")
+	var stdout, stderr bytes.Buffer
+	envVars := map[string]string{
+		"AWS_SSM_TEST_HELPER_PROCESS": "1",
+		"AWS_SSM_TEST_BUILD_ID":       "build-42",
+	}
+
+	exitCode, err := ExecuteCommand(log.NewMockLog(), task.NewChanneledCancelFlag(), "", &stdout, &stderr, 10,
+		os.Args[0], []string{"-test.run=TestHelperProcess"}, envVars)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "build-42", stdout.String())
+}
+
 func TestQuoteShString(t *testing.T) {
 	var result string
 
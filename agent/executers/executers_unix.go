@@ -16,14 +16,23 @@
 package executers
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 )
 
+// Assign method to global variables to allow unittest to override
+var lookupUser = user.Lookup
+var getEuid = os.Geteuid
+
 func prepareProcess(command *exec.Cmd) {
 	// make the process the leader of its process group
 	// (otherwise we cannot kill it properly)
@@ -64,3 +73,78 @@ func validateEnvironmentVariables(command *exec.Cmd) {
 		command.Env = env
 	}
 }
+
+// prepareRunAsUser makes the process run with the credentials of the given user
+func prepareRunAsUser(command *exec.Cmd, runAsUser string) error {
+	credential, err := userCredential(runAsUser)
+	if err != nil {
+		return err
+	}
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Credential = credential
+	return nil
+}
+
+// StageScriptForUser copies the script into a directory of its own that is owned by the given user, since the user
+// can't traverse the orchestration directory of the agent. The returned function removes the directory again.
+func StageScriptForUser(scriptPath string, runAsUser string) (stagedPath string, cleanup func(), err error) {
+	credential, err := userCredential(runAsUser)
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := ioutil.ReadFile(scriptPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read script %v, %v", scriptPath, err)
+	}
+
+	dir, err := ioutil.TempDir("", "ssm-run-as-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create a directory for the script of user %v, %v", runAsUser, err)
+	}
+	cleanup = func() {
+		os.RemoveAll(dir)
+	}
+	stagedPath = filepath.Join(dir, filepath.Base(scriptPath))
+	if err = ioutil.WriteFile(stagedPath, content, appconfig.ReadWriteExecuteAccess); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write the script of user %v, %v", runAsUser, err)
+	}
+	for _, path := range []string{dir, stagedPath} {
+		if err = os.Chown(path, int(credential.Uid), int(credential.Gid)); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to hand over %v to user %v, %v", path, runAsUser, err)
+		}
+	}
+	return stagedPath, cleanup, nil
+}
+
+// userCredential resolves the uid, the gid and the supplementary groups of the given user
+func userCredential(runAsUser string) (*syscall.Credential, error) {
+	u, err := lookupUser(runAsUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve user %v to run as, %v", runAsUser, err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid %v of user %v, %v", u.Uid, runAsUser, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid %v of user %v, %v", u.Gid, runAsUser, err)
+	}
+	if euid := getEuid(); euid != 0 && uint64(euid) != uid {
+		return nil, fmt.Errorf("agent running as uid %v lacks the privilege to run as user %v", euid, runAsUser)
+	}
+
+	credential := &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	if groupIds, err := u.GroupIds(); err == nil {
+		for _, groupId := range groupIds {
+			if group, err := strconv.ParseUint(groupId, 10, 32); err == nil {
+				credential.Groups = append(credential.Groups, uint32(group))
+			}
+		}
+	}
+	return credential, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package executers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubUser makes the lookup of users return the given user and the agent run as the given euid
+func stubUser(u *user.User, lookupErr error, euid int) func() {
+	originalLookup, originalEuid := lookupUser, getEuid
+	lookupUser = func(name string) (*user.User, error) {
+		return u, lookupErr
+	}
+	getEuid = func() int { return euid }
+	return func() {
+		lookupUser, getEuid = originalLookup, originalEuid
+	}
+}
+
+func TestUserCredentialResolvesUidAndGid(t *testing.T) {
+	defer stubUser(&user.User{Username: "service", Uid: "1001", Gid: "1002"}, nil, 0)()
+
+	credential, err := userCredential("service")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1001), credential.Uid)
+	assert.Equal(t, uint32(1002), credential.Gid)
+}
+
+func TestUserCredentialFailsForMissingUser(t *testing.T) {
+	defer stubUser(nil, user.UnknownUserError("missing"), 0)()
+
+	_, err := userCredential("missing")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve user missing to run as")
+}
+
+func TestUserCredentialFailsForInvalidUid(t *testing.T) {
+	defer stubUser(&user.User{Username: "service", Uid: "S-1-5-18", Gid: "1002"}, nil, 0)()
+
+	_, err := userCredential("service")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid uid S-1-5-18 of user service")
+}
+
+func TestUserCredentialFailsWithoutPrivilege(t *testing.T) {
+	defer stubUser(&user.User{Username: "service", Uid: "1001", Gid: "1002"}, nil, 1000)()
+
+	_, err := userCredential("service")
+
+	assert.EqualError(t, err, "agent running as uid 1000 lacks the privilege to run as user service")
+}
+
+func TestUserCredentialOfAgentUserDoesNotNeedPrivilege(t *testing.T) {
+	defer stubUser(&user.User{Username: "agent", Uid: "1000", Gid: "1000"}, nil, 1000)()
+
+	credential, err := userCredential("agent")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1000), credential.Uid)
+}
+
+func TestPrepareRunAsUserSetsCredential(t *testing.T) {
+	defer stubUser(&user.User{Username: "service", Uid: "1001", Gid: "1002"}, nil, 0)()
+	command := exec.Command("test")
+	prepareProcess(command)
+
+	assert.NoError(t, prepareRunAsUser(command, "service"))
+	assert.True(t, command.SysProcAttr.Setpgid)
+	assert.Equal(t, uint32(1001), command.SysProcAttr.Credential.Uid)
+}
+
+func TestExecuteCommandAsCurrentUser(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("current user can't be resolved", err)
+	}
+	var stdout, stderr bytes.Buffer
+
+	exitCode, err := executeCommandAsUser(log.NewMockLog(), current.Username, task.NewChanneledCancelFlag(), "", &stdout, &stderr, 10,
+		"sh", []string{"-c", "id -u"}, map[string]string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, fmt.Sprintln(strconv.Itoa(os.Geteuid())), stdout.String())
+}
+
+func TestExecuteCommandAsMissingUserFails(t *testing.T) {
+	defer stubUser(nil, user.UnknownUserError("missing"), 0)()
+	var stdout, stderr bytes.Buffer
+
+	exitCode, err := executeCommandAsUser(log.NewMockLog(), "missing", task.NewChanneledCancelFlag(), "", &stdout, &stderr, 10,
+		"sh", []string{"-c", "echo ran"}, map[string]string{})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Empty(t, stdout.String())
+}
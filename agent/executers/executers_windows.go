@@ -16,6 +16,7 @@
 package executers
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 )
@@ -38,3 +39,13 @@ func killProcess(process *os.Process, signal *timeoutSignal) error {
 // Running powershell on linux required the HOME env variable to be set and to remove the TERM env variable
 func validateEnvironmentVariables(command *exec.Cmd) {
 }
+
+// prepareRunAsUser rejects running as a different user since it would require the password or a token of the user
+func prepareRunAsUser(command *exec.Cmd, runAsUser string) error {
+	return fmt.Errorf("running commands as user %v is not supported on windows", runAsUser)
+}
+
+// StageScriptForUser rejects staging scripts for a different user since commands can't run as one on windows
+func StageScriptForUser(scriptPath string, runAsUser string) (stagedPath string, cleanup func(), err error) {
+	return "", nil, fmt.Errorf("running commands as user %v is not supported on windows", runAsUser)
+}
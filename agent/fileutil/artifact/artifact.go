@@ -18,13 +18,17 @@ import (
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
@@ -51,10 +55,46 @@ type DownloadInput struct {
 	SourceURL            string
 	DestinationDirectory string
 	SourceChecksums      map[string]string
+	// Transport is used for http/https and s3 downloads, the default transport is used if it's nil
+	Transport http.RoundTripper
+}
+
+// NewTransport returns a transport that sends requests through the given proxy and trusts the certificates of the
+// given PEM encoded CA bundle in addition to the system ones. Returns nil, i.e. the default transport, if neither
+// a proxy nor a CA bundle is set.
+func NewTransport(proxyURL string, caBundlePath string) (http.RoundTripper, error) {
+	if proxyURL == "" && caBundlePath == "" {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %v, %v", proxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if caBundlePath != "" {
+		bundle, err := ioutil.ReadFile(caBundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %v, %v", caBundlePath, err)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("CA bundle %v doesn't contain any PEM encoded certificate", caBundlePath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	}
+	return transport, nil
 }
 
 // httpDownload attempts to download a file via http/s call
-func httpDownload(log log.T, fileURL string, destFile string) (output DownloadOutput, err error) {
+func httpDownload(log log.T, fileURL string, destFile string, transport http.RoundTripper) (output DownloadOutput, err error) {
 	log.Debugf("attempting to download as http/https download from %v to %v", fileURL, destFile)
 	eTagFile := destFile + ".etag"
 	var check http.Client
@@ -71,6 +111,7 @@ func httpDownload(log log.T, fileURL string, destFile string) (output DownloadOu
 	}
 
 	check = http.Client{
+		Transport: transport,
 		CheckRedirect: func(r *http.Request, via []*http.Request) error {
 			r.URL.Opaque = r.URL.Path
 			return nil
@@ -245,11 +286,14 @@ func ListS3Directory(log log.T, amazonS3URL s3util.AmazonS3URL) (folderNames []s
 }
 
 // s3Download attempts to download a file via the aws sdk.
-func s3Download(log log.T, amazonS3URL s3util.AmazonS3URL, destFile string) (output DownloadOutput, err error) {
+func s3Download(log log.T, amazonS3URL s3util.AmazonS3URL, destFile string, transport http.RoundTripper) (output DownloadOutput, err error) {
 	log.Debugf("attempting to download as s3 download %v", destFile)
 	eTagFile := destFile + ".etag"
 
 	config, _ := awsConfig(log, amazonS3URL)
+	if transport != nil {
+		config.HTTPClient = &http.Client{Transport: transport}
+	}
 	params := &s3.GetObjectInput{
 		Bucket: aws.String(amazonS3URL.Bucket),
 		Key:    aws.String(amazonS3URL.Key),
@@ -345,6 +389,11 @@ func Download(log log.T, input DownloadInput) (output DownloadOutput, err error)
 		return
 	}
 
+	// sources staged on the local file system, e.g. in air-gapped environments, are verified in place
+	if localPath, isLocal := localSourcePath(fileURL, input.SourceURL); isLocal {
+		return localSource(log, input, localPath)
+	}
+
 	// process if the url is local file or it has already been downloaded.
 	var isLocalFile = false
 	isLocalFile, err = fileutil.LocalFileExist(input.SourceURL)
@@ -369,14 +418,14 @@ func Download(log log.T, input DownloadInput) (output DownloadOutput, err error)
 		amazonS3URL := s3util.ParseAmazonS3URL(log, fileURL)
 		if amazonS3URL.IsBucketAndKeyPresent() {
 			var tempOutput DownloadOutput
-			tempOutput, err = s3Download(log, amazonS3URL, output.LocalFilePath)
+			tempOutput, err = s3Download(log, amazonS3URL, output.LocalFilePath, input.Transport)
 			if err != nil {
 				log.Info("An error occurred when attempting s3 download. Attempting http/https download as fallback.")
-				tempOutput, err = httpDownload(log, input.SourceURL, output.LocalFilePath)
+				tempOutput, err = httpDownload(log, input.SourceURL, output.LocalFilePath, input.Transport)
 			}
 			output = tempOutput
 		} else {
-			output, err = httpDownload(log, input.SourceURL, output.LocalFilePath)
+			output, err = httpDownload(log, input.SourceURL, output.LocalFilePath, input.Transport)
 		}
 
 		if err != nil {
@@ -392,6 +441,33 @@ func Download(log log.T, input DownloadInput) (output DownloadOutput, err error)
 	return
 }
 
+// localSourcePath returns the path of the source if it refers to the local file system,
+// i.e. it's a file:// url or an absolute path
+func localSourcePath(fileURL *url.URL, source string) (string, bool) {
+	if strings.EqualFold(fileURL.Scheme, "file") {
+		path := fileURL.Path
+		// file:///C:/updates/agent.zip has the path /C:/updates/agent.zip
+		if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+		return filepath.FromSlash(path), true
+	}
+	return source, filepath.IsAbs(source)
+}
+
+// localSource verifies the local file the source refers to instead of downloading it
+func localSource(log log.T, input DownloadInput, localPath string) (output DownloadOutput, err error) {
+	var exists bool
+	if exists, err = fileutil.LocalFileExist(localPath); err != nil || !exists {
+		return output, fmt.Errorf("local source %v doesn't exist, %v", localPath, err)
+	}
+	log.Debugf("source is a local file, skipping download. %v", localPath)
+	output.LocalFilePath = localPath
+	output.IsUpdated = false
+	output.IsHashMatched, err = VerifyHash(log, input, output)
+	return
+}
+
 // VerifyHash verifies the hash of the url file as per specified hash algorithm type and its value
 func VerifyHash(log log.T, input DownloadInput, output DownloadOutput) (bool, error) {
 	hasMatchingHash := false
@@ -430,7 +506,11 @@ func VerifyHash(log log.T, input DownloadInput, output DownloadOutput) (bool, er
 		}
 
 		if !strings.EqualFold(hashValue, computedHashValue) {
-			return false, fmt.Errorf("failed to verify hash of downloadinput %v", input)
+			if hashAlgorithm == "" {
+				hashAlgorithm = "sha256"
+			}
+			return false, fmt.Errorf("failed to verify %v hash of %v, expected %v but computed %v",
+				hashAlgorithm, input.SourceURL, hashValue, computedHashValue)
 		}
 
 		hasMatchingHash = true
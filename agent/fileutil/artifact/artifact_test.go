@@ -0,0 +1,214 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeArtifact writes the content to a file in a temporary directory and returns the path of the file
+func writeArtifact(t *testing.T, content []byte) (string, func()) {
+	dir, err := ioutil.TempDir("", "artifact")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "artifact.zip")
+	assert.NoError(t, ioutil.WriteFile(path, content, 0600))
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestVerifyHashWithSha256(t *testing.T) {
+	content := []byte("amazon-ssm-agent installer")
+	digest := sha256.Sum256(content)
+	path, cleanup := writeArtifact(t, content)
+	defer cleanup()
+
+	for _, hashType := range []string{"sha256", "SHA256", ""} {
+		input := DownloadInput{
+			SourceURL:       "https://example.com/amazon-ssm-agent.zip",
+			SourceChecksums: map[string]string{hashType: hex.EncodeToString(digest[:])},
+		}
+		matched, err := VerifyHash(log.NewMockLog(), input, DownloadOutput{LocalFilePath: path})
+		assert.NoError(t, err)
+		assert.True(t, matched)
+	}
+}
+
+func TestVerifyHashFailsForCorruptedArtifact(t *testing.T) {
+	content := []byte("amazon-ssm-agent installer")
+	digest := sha256.Sum256(content)
+	content[0] ^= 0xff
+	path, cleanup := writeArtifact(t, content)
+	defer cleanup()
+	input := DownloadInput{
+		SourceURL:       "https://example.com/amazon-ssm-agent.zip",
+		SourceChecksums: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+	}
+
+	matched, err := VerifyHash(log.NewMockLog(), input, DownloadOutput{LocalFilePath: path})
+
+	assert.False(t, matched)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to verify sha256 hash of https://example.com/amazon-ssm-agent.zip")
+	assert.Contains(t, err.Error(), hex.EncodeToString(digest[:]))
+}
+
+func TestVerifyHashFailsForUnsupportedHashType(t *testing.T) {
+	path, cleanup := writeArtifact(t, []byte("amazon-ssm-agent installer"))
+	defer cleanup()
+	input := DownloadInput{
+		SourceURL:       "https://example.com/amazon-ssm-agent.zip",
+		SourceChecksums: map[string]string{"crc32": "d87f7e0c"},
+	}
+
+	matched, err := VerifyHash(log.NewMockLog(), input, DownloadOutput{LocalFilePath: path})
+
+	assert.False(t, matched)
+	assert.Error(t, err)
+}
+
+func TestDownloadReadsFileURLFromDisk(t *testing.T) {
+	content := []byte("amazon-ssm-agent installer")
+	digest := sha256.Sum256(content)
+	path, cleanup := writeArtifact(t, content)
+	defer cleanup()
+
+	for _, source := range []string{"file://" + filepath.ToSlash(path), path} {
+		input := DownloadInput{
+			SourceURL:       source,
+			SourceChecksums: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+		}
+		output, err := Download(log.NewMockLog(), input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, path, output.LocalFilePath)
+		assert.True(t, output.IsHashMatched)
+		assert.False(t, output.IsUpdated)
+	}
+}
+
+func TestDownloadVerifiesHashOfLocalSource(t *testing.T) {
+	content := []byte("amazon-ssm-agent installer")
+	digest := sha256.Sum256(content)
+	content[0] ^= 0xff
+	path, cleanup := writeArtifact(t, content)
+	defer cleanup()
+	input := DownloadInput{
+		SourceURL:       "file://" + filepath.ToSlash(path),
+		SourceChecksums: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+	}
+
+	output, err := Download(log.NewMockLog(), input)
+
+	assert.Error(t, err)
+	assert.False(t, output.IsHashMatched)
+}
+
+func TestDownloadFailsForMissingLocalSource(t *testing.T) {
+	path, cleanup := writeArtifact(t, []byte("amazon-ssm-agent installer"))
+	cleanup()
+
+	_, err := Download(log.NewMockLog(), DownloadInput{SourceURL: "file://" + filepath.ToSlash(path)})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't exist")
+}
+
+// newTLSServer starts a server with a self-signed certificate serving the content and writes its certificate as
+// CA bundle to a temporary file
+func newTLSServer(t *testing.T, content []byte) (*httptest.Server, string, func()) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	dir, err := ioutil.TempDir("", "artifact")
+	assert.NoError(t, err)
+	caBundle := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, ioutil.WriteFile(caBundle, cert, 0600))
+	return server, caBundle, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDownloadTrustsConfiguredCABundle(t *testing.T) {
+	content := []byte("amazon-ssm-agent installer")
+	digest := sha256.Sum256(content)
+	server, caBundle, cleanup := newTLSServer(t, content)
+	defer cleanup()
+	transport, err := NewTransport("", caBundle)
+	assert.NoError(t, err)
+	destination, err := ioutil.TempDir("", "artifact")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destination)
+
+	output, err := Download(log.NewMockLog(), DownloadInput{
+		SourceURL:            server.URL + "/installer.zip",
+		DestinationDirectory: destination,
+		SourceChecksums:      map[string]string{"sha256": hex.EncodeToString(digest[:])},
+		Transport:            transport,
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, output.IsHashMatched)
+	downloaded, err := ioutil.ReadFile(output.LocalFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+}
+
+func TestDownloadFailsWithoutCABundle(t *testing.T) {
+	server, _, cleanup := newTLSServer(t, []byte("amazon-ssm-agent installer"))
+	defer cleanup()
+	destination, err := ioutil.TempDir("", "artifact")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destination)
+
+	_, err = Download(log.NewMockLog(), DownloadInput{
+		SourceURL:            server.URL + "/installer.zip",
+		DestinationDirectory: destination,
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+}
+
+func TestNewTransport(t *testing.T) {
+	transport, err := NewTransport("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, transport)
+
+	transport, err = NewTransport("http://proxy.example.com:3128", "")
+	assert.NoError(t, err)
+	request, _ := http.NewRequest(http.MethodGet, "https://s3.amazonaws.com/manifest.json", nil)
+	proxy, err := transport.(*http.Transport).Proxy(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+
+	path, cleanup := writeArtifact(t, []byte("not a certificate"))
+	defer cleanup()
+	_, err = NewTransport("", path)
+	assert.Error(t, err)
+
+	_, err = NewTransport("", path+".missing")
+	assert.Error(t, err)
+}
@@ -56,9 +56,13 @@ func loadCoreModules(context context.T) {
 		}
 
 		// registering the long running plugin manager as a core module
-		manager.EnsureInitialization(context)
-		if lrpm, err := manager.GetInstance(); err == nil {
+		if err := manager.EnsureInitialization(context, nil); err != nil {
+			context.Log().Errorf("Failed to initialize long running plugin manager - %v", err)
+		} else if lrpm, err := manager.GetInstance(); err == nil {
 			registeredCoreModules = append(registeredCoreModules, lrpm)
+			if port := context.AppConfig().Ssm.LongRunningPluginsHealthPort; port > 0 {
+				lrpm.ServeHealth(port)
+			}
 		} else {
 			context.Log().Errorf("Something went wrong during initialization of long running plugin manager")
 		}
@@ -27,11 +27,15 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/docparser/parameterstore"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/updateutil"
 )
 
 const (
 	preconditionSchemaVersion string = "2.2"
+
+	// executionTimeoutInputName is the plugin input that sets how long a plugin may execute for
+	executionTimeoutInputName = "timeoutSeconds"
 )
 
 // DocumentParserInfo represents the parsed information from the request
@@ -237,7 +241,7 @@ func parseDocumentContent(docContent DocContent, parserInfo DocumentParserInfo,
 
 	switch docContent.SchemaVersion {
 	case "1.0", "1.2":
-		return parsePluginStateForV10Schema(docContent, parserInfo.OrchestrationDir, parserInfo.S3Bucket, parserInfo.S3Prefix, parserInfo.MessageId, parserInfo.DocumentId, parserInfo.DefaultWorkingDir)
+		return parsePluginStateForV10Schema(docContent, parserInfo.OrchestrationDir, parserInfo.S3Bucket, parserInfo.S3Prefix, parserInfo.MessageId, parserInfo.DocumentId, parserInfo.DefaultWorkingDir, log)
 
 	case "2.0", "2.0.1", "2.0.2", "2.0.3", "2.2":
 
@@ -251,7 +255,8 @@ func parseDocumentContent(docContent DocContent, parserInfo DocumentParserInfo,
 // parsePluginStateForV10Schema initializes pluginsInfo for the docState. Used for document v1.0 and 1.2
 func parsePluginStateForV10Schema(
 	docContent DocContent,
-	orchestrationDir, s3Bucket, s3Prefix, messageID, documentID, defaultWorkingDir string) (pluginsInfo []contracts.PluginState, err error) {
+	orchestrationDir, s3Bucket, s3Prefix, messageID, documentID, defaultWorkingDir string,
+	log log.T) (pluginsInfo []contracts.PluginState, err error) {
 
 	if len(docContent.RuntimeConfig) == 0 {
 		return pluginsInfo, fmt.Errorf("Unsupported schema format")
@@ -272,6 +277,7 @@ func parsePluginStateForV10Schema(
 			PluginName:              pluginName,
 			PluginID:                pluginName,
 			DefaultWorkingDirectory: defaultWorkingDir,
+			ExecutionTimeoutSeconds: parseExecutionTimeout(log, pluginConfig.Properties),
 		}
 		pluginConfigurations = append(pluginConfigurations, &config)
 	}
@@ -316,6 +322,7 @@ func parsePluginStateForV20Schema(
 			Preconditions:           parsePluginParametersInPreconditions(&docContent, instancePluginConfig.Preconditions, params, log),
 			IsPreconditionEnabled:   isPreconditionEnabled,
 			DefaultWorkingDirectory: defaultWorkingDir,
+			ExecutionTimeoutSeconds: parseExecutionTimeout(log, instancePluginConfig.Inputs),
 		}
 
 		var plugin contracts.PluginState
@@ -327,6 +334,29 @@ func parsePluginStateForV20Schema(
 	return
 }
 
+// parseExecutionTimeout returns the timeoutSeconds of the plugin inputs, validated the way the plugins validate it,
+// or 0 if the inputs don't set one. Properties of v1.2 documents may list several commands that execute one after
+// the other, those may execute for the sum of their timeouts.
+func parseExecutionTimeout(log log.T, properties interface{}) int {
+	switch value := properties.(type) {
+	case map[string]interface{}:
+		if timeout, ok := value[executionTimeoutInputName]; ok {
+			return pluginutil.ValidateExecutionTimeout(log, timeout)
+		}
+	case []interface{}:
+		total := 0
+		for _, property := range value {
+			timeout := parseExecutionTimeout(log, property)
+			if timeout == 0 {
+				return 0
+			}
+			total += timeout
+		}
+		return total
+	}
+	return 0
+}
+
 // parsePluginParametersInPreconditions modifies plugin preconditions as defined in PluginConfig to match the structure
 // expected by the plugin executor (plugin.Configuration -> PreconditionArgument)
 func parsePluginParametersInPreconditions(docContent *DocContent, precondition map[string][]string, params map[string]interface{}, log log.T) map[string][]contracts.PreconditionArgument {
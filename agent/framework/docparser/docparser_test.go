@@ -131,6 +131,33 @@ func TestParseDocument_ValidMainSteps(t *testing.T) {
 	assert.Equal(t, testWorkingDir, pluginInfoTest.Configuration.DefaultWorkingDirectory)
 }
 
+func TestParseDocument_ExecutionTimeoutFromPluginInputs(t *testing.T) {
+	testParserInfo := DocumentParserInfo{OrchestrationDir: testOrchDir}
+	tests := []struct {
+		document string
+		expected int
+	}{
+		{`{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","inputs":{"runCommand":["ls"],"timeoutSeconds":"600"}}]}`, 600},
+		{`{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","inputs":{"runCommand":["ls"],"timeoutSeconds":"{{ timeout }}"}}],"parameters":{"timeout":{"type":"String","default":"120"}}}`, 120},
+		{`{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","inputs":{"runCommand":["ls"],"timeoutSeconds":1}}]}`, 3600},
+		{`{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"test","inputs":{"runCommand":["ls"]}}]}`, 0},
+		{`{"schemaVersion":"1.2","runtimeConfig":{"aws:runShellScript":{"properties":[{"id":"0","runCommand":["ls"],"timeoutSeconds":"60"},{"id":"1","runCommand":["ls"],"timeoutSeconds":30}]}}}`, 90},
+		{`{"schemaVersion":"1.2","runtimeConfig":{"aws:runShellScript":{"properties":[{"id":"0","runCommand":["ls"],"timeoutSeconds":"60"},{"id":"1","runCommand":["ls"]}]}}}`, 0},
+	}
+
+	for _, test := range tests {
+		var testDocContent DocContent
+		assert.NoError(t, json.Unmarshal([]byte(test.document), &testDocContent))
+
+		pluginsInfo, err := testDocContent.ParseDocument(log.NewMockLog(), contracts.DocumentInfo{}, testParserInfo, nil)
+
+		assert.NoError(t, err)
+		if assert.Len(t, pluginsInfo, 1) {
+			assert.Equal(t, test.expected, pluginsInfo[0].Configuration.ExecutionTimeoutSeconds, test.document)
+		}
+	}
+}
+
 func TestInitializeDocState_Valid(t *testing.T) {
 	mockLog := log.NewMockLog()
 
@@ -0,0 +1,62 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains general interfaces and types relevant to plugins.
+// It also provides the methods for registering plugins.
+package plugin
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// ExecuteWithContext executes the plugin with a cancel flag that gets canceled once ctx is done, so that callers can
+// impose cancellation and deadlines with a standard context. The plugin writes to output, which the caller is expected
+// to have initialized, and the result is built from the output once the plugin returns.
+func ExecuteWithContext(ctx gocontext.Context, context context.T, p runpluginutil.T, config contracts.Configuration, output iohandler.IOHandler) (res contracts.PluginResult) {
+	cancelFlag := task.NewChanneledCancelFlag()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	defer func() {
+		// make sure ctx can no longer cancel the plugin once it returned
+		close(done)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cancelFlag.Set(task.Canceled)
+		case <-done:
+		}
+	}()
+
+	res.PluginID = config.PluginID
+	res.PluginName = config.PluginName
+	res.StartDateTime = time.Now()
+	p.Execute(context, config, cancelFlag, output)
+	res.EndDateTime = time.Now()
+
+	res.Code = output.GetExitCode()
+	res.Status = output.GetStatus()
+	res.Output = output.String()
+	res.StandardOutput = output.GetStdout()
+	res.StandardError = output.GetStderr()
+	return res
+}
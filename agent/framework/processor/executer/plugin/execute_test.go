@@ -0,0 +1,77 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	gocontext "context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockOutput returns a mocked output reporting the given status
+func mockOutput(status contracts.ResultStatus) *iohandlermocks.MockIOHandler {
+	output := new(iohandlermocks.MockIOHandler)
+	output.On("GetExitCode").Return(0)
+	output.On("GetStatus").Return(status)
+	output.On("String").Return("output")
+	output.On("GetStdout").Return("stdout")
+	output.On("GetStderr").Return("")
+	return output
+}
+
+func TestExecuteWithContextCancelsFlagWhenContextIsDone(t *testing.T) {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	config := contracts.Configuration{PluginID: "id", PluginName: "aws:custom"}
+	output := mockOutput(contracts.ResultStatusCancelled)
+	var observed task.State
+	p := new(runpluginutil.PluginMock)
+	p.On("Execute", mock.Anything, config, mock.Anything, output).Return().Run(func(args mock.Arguments) {
+		cancelFlag := args.Get(2).(task.CancelFlag)
+		cancel()
+		observed = cancelFlag.Wait()
+	})
+
+	res := ExecuteWithContext(ctx, context.NewMockDefault(), p, config, output)
+
+	assert.Equal(t, task.Canceled, observed)
+	assert.Equal(t, contracts.ResultStatusCancelled, res.Status)
+	assert.Equal(t, "id", res.PluginID)
+	assert.Equal(t, "output", res.Output)
+}
+
+func TestExecuteWithContextDoesNotCancelCompletedPlugin(t *testing.T) {
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), time.Minute)
+	defer cancel()
+	config := contracts.Configuration{PluginID: "id"}
+	output := mockOutput(contracts.ResultStatusSuccess)
+	var cancelFlag task.CancelFlag
+	p := new(runpluginutil.PluginMock)
+	p.On("Execute", mock.Anything, config, mock.Anything, output).Return().Run(func(args mock.Arguments) {
+		cancelFlag = args.Get(2).(task.CancelFlag)
+	})
+
+	res := ExecuteWithContext(ctx, context.NewMockDefault(), p, config, output)
+	cancel()
+
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	assert.False(t, cancelFlag.Canceled())
+}
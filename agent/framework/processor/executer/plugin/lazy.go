@@ -0,0 +1,93 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains general interfaces and types relevant to plugins.
+// It also provides the methods for registering plugins.
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+)
+
+// lazyFactory constructs its plugin on first use and hands out the same instance afterwards.
+// A failed construction isn't memoized, so that the next use tries again.
+type lazyFactory struct {
+	factory runpluginutil.PluginFactory
+
+	//guards plugin
+	lock sync.Mutex
+
+	//plugin constructed by factory, nil until constructed successfully
+	plugin runpluginutil.T
+}
+
+// Create returns the memoized plugin, the plugin gets constructed if it isn't yet
+func (f *lazyFactory) Create(context context.T) (runpluginutil.T, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.plugin != nil {
+		return f.plugin, nil
+	}
+	plugin, err := createSafely(context, f.factory)
+	if err != nil {
+		return nil, err
+	}
+	f.plugin = plugin
+	return plugin, nil
+}
+
+// createSafely constructs the plugin of the given factory, a panicking construction is reported as an error so
+// that it only fails the execution of that plugin
+func createSafely(context context.T, factory runpluginutil.PluginFactory) (plugin runpluginutil.T, err error) {
+	defer func() {
+		if msg := recover(); msg != nil {
+			context.Log().Errorf("%s: %s", msg, debug.Stack())
+			plugin, err = nil, fmt.Errorf("plugin construction panicked - %v", msg)
+		}
+	}()
+	return factory.Create(context)
+}
+
+// memoizePlugins wraps the given worker plugins so that each plugin gets constructed once on its first execution.
+// If the agent configuration asks for eager initialization all plugins get constructed right away.
+func memoizePlugins(context context.T, plugins runpluginutil.PluginRegistry) runpluginutil.PluginRegistry {
+	memoized := make(runpluginutil.PluginRegistry, len(plugins))
+	for name, factory := range plugins {
+		memoized[name] = &lazyFactory{factory: factory}
+	}
+	if context.AppConfig().Agent.EagerPluginInitialization {
+		initializePlugins(context, memoized)
+	}
+	return memoized
+}
+
+// initializePlugins constructs all given plugins, plugins that fail get constructed again on their first execution
+func initializePlugins(context context.T, plugins runpluginutil.PluginRegistry) {
+	log := context.Log()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := plugins[runpluginutil.PluginName(name)].Create(context); err != nil {
+			log.Warnf("Unable to initialize plugin %v - %v", name, err)
+		}
+	}
+}
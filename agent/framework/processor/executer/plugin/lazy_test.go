@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// contextWithEagerInitialization returns a mocked context whose agent configuration enables eager initialization
+func contextWithEagerInitialization() context.T {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.EagerPluginInitialization = true
+	ctx := new(context.Mock)
+	ctx.On("Log").Return(log.NewMockLog())
+	ctx.On("AppConfig").Return(config)
+	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+	return ctx
+}
+
+func TestLazyFactoryConstructsPluginOnce(t *testing.T) {
+	ctx := context.NewMockDefault()
+	plugin := new(runpluginutil.PluginMock)
+	factory := new(runpluginutil.PluginFactoryMock)
+	factory.On("Create", mock.Anything).Return((*runpluginutil.PluginMock)(nil), errors.New("unable to create plugin")).Once()
+	factory.On("Create", mock.Anything).Return(plugin, nil).Once()
+	lazy := &lazyFactory{factory: factory}
+
+	_, err := lazy.Create(ctx)
+	assert.Error(t, err)
+	for i := 0; i < 3; i++ {
+		created, err := lazy.Create(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, plugin, created)
+	}
+	factory.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestMemoizePluginsDefersConstruction(t *testing.T) {
+	factory := new(runpluginutil.PluginFactoryMock)
+
+	plugins := memoizePlugins(context.NewMockDefault(), runpluginutil.PluginRegistry{"aws:custom": factory})
+
+	assert.Len(t, plugins, 1)
+	factory.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestMemoizePluginsConstructsEagerly(t *testing.T) {
+	ctx := contextWithEagerInitialization()
+	plugin := new(runpluginutil.PluginMock)
+	factory := new(runpluginutil.PluginFactoryMock)
+	factory.On("Create", mock.Anything).Return(plugin, nil)
+
+	plugins := memoizePlugins(ctx, runpluginutil.PluginRegistry{"aws:custom": factory})
+	created, err := plugins["aws:custom"].Create(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, plugin, created)
+	factory.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestPanickingPluginDoesNotPreventSiblingsFromBeingCreated(t *testing.T) {
+	for _, ctx := range []context.T{context.NewMockDefault(), contextWithEagerInitialization()} {
+		plugin := new(runpluginutil.PluginMock)
+		sibling := new(runpluginutil.PluginFactoryMock)
+		sibling.On("Create", mock.Anything).Return(plugin, nil)
+		panicking := FactoryFunc(func(context context.T) (runpluginutil.T, error) {
+			panic("unable to create plugin")
+		})
+
+		plugins := memoizePlugins(ctx, runpluginutil.PluginRegistry{"aws:panicking": panicking, "aws:sibling": sibling})
+
+		created, err := plugins["aws:panicking"].Create(ctx)
+		assert.Error(t, err)
+		assert.Nil(t, created)
+		created, err = plugins["aws:sibling"].Create(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, plugin, created)
+		sibling.AssertNumberOfCalls(t, "Create", 1)
+	}
+}
+
+// benchmarkLoadWorkers measures loading the worker plugins until they're ready to be looked up by documents
+func benchmarkLoadWorkers(b *testing.B, ctx context.T) {
+	for i := 0; i < b.N; i++ {
+		memoizePlugins(ctx, loadWorkers(ctx))
+	}
+}
+
+func BenchmarkLoadWorkersLazy(b *testing.B) {
+	benchmarkLoadWorkers(b, context.NewMockDefault())
+}
+
+func BenchmarkLoadWorkersEager(b *testing.B) {
+	benchmarkLoadWorkers(b, contextWithEagerInitialization())
+}
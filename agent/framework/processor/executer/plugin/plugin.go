@@ -16,6 +16,7 @@
 package plugin
 
 import (
+	"fmt"
 	"runtime/debug"
 	"sync"
 
@@ -38,31 +39,15 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/session/plugins/standardstream"
 )
 
-// allPlugins is the list of all known plugins.
-// This allows us to differentiate between the case where a document asks for a plugin that exists but isn't supported on this platform
-// and the case where a plugin name isn't known at all to this version of the agent (and the user should probably upgrade their agent)
-var allPlugins = map[string]struct{}{
-	appconfig.PluginNameAwsAgentUpdate:         {},
-	appconfig.PluginNameAwsApplications:        {},
-	appconfig.PluginNameAwsConfigureDaemon:     {},
-	appconfig.PluginNameAwsConfigurePackage:    {},
-	appconfig.PluginNameAwsPowerShellModule:    {},
-	appconfig.PluginNameAwsRunPowerShellScript: {},
-	appconfig.PluginNameAwsRunShellScript:      {},
-	appconfig.PluginNameAwsSoftwareInventory:   {},
-	appconfig.PluginNameCloudWatch:             {},
-	appconfig.PluginNameConfigureDocker:        {},
-	appconfig.PluginNameDockerContainer:        {},
-	appconfig.PluginNameDomainJoin:             {},
-	appconfig.PluginEC2ConfigUpdate:            {},
-	appconfig.PluginNameRefreshAssociation:     {},
-	appconfig.PluginDownloadContent:            {},
-	appconfig.PluginRunDocument:                {},
-}
-
-var once sync.Once
-
-// registeredPlugins stores the registered plugins.
+// registryLock guards registeredPlugins
+var registryLock sync.RWMutex
+
+func init() {
+	//Long running plugins are handled by lrpm. lrpminvoker is a worker plugin that can communicate with lrpm.
+	RegisterLongRunningWorkerPlugin(runpluginutil.PluginNameCloudWatch, CloudWatchFactory{}.Create)
+}
+
+// registeredPlugins stores the registered plugins, nil until they get loaded.
 var registeredPlugins *runpluginutil.PluginRegistry
 
 type CloudWatchFactory struct {
@@ -143,7 +128,9 @@ func (f SessionPluginFactory) Create(context context.T) (runpluginutil.T, error)
 	return sessionplugin.NewPlugin(f.newPluginFunc)
 }
 
-// RegisteredWorkerPlugins returns all registered core modules.
+// RegisteredWorkerPlugins returns a copy of all registered core modules, changes to the returned registry
+// don't affect the registered plugins. Each plugin gets constructed on its first execution unless the agent
+// configuration enables EagerPluginInitialization.
 func RegisteredWorkerPlugins(context context.T) runpluginutil.PluginRegistry {
 
 	defer func() {
@@ -153,96 +140,177 @@ func RegisteredWorkerPlugins(context context.T) runpluginutil.PluginRegistry {
 		}
 	}()
 
-	once.Do(func() {
-		loadWorkers(context)
+	return loadRegistry(func() runpluginutil.PluginRegistry {
+		plugins := memoizePlugins(context, loadWorkers(context))
+		logPluginVersions(context, plugins)
+		return plugins
 	})
-	return *registeredPlugins
 }
 
-// RegisteredSessionWorkerPlugins returns all registered session plugins.
+// RegisteredSessionWorkerPlugins returns a copy of all registered session plugins, changes to the returned registry
+// don't affect the registered plugins.
 func RegisteredSessionWorkerPlugins() runpluginutil.PluginRegistry {
-	once.Do(func() {
-		loadSessionPlugins()
-	})
-	return *registeredPlugins
+	return loadRegistry(loadSessionPlugins)
+}
+
+// InvalidateCache drops the loaded plugins so that the next call to RegisteredWorkerPlugins or
+// RegisteredSessionWorkerPlugins loads them again, e.g. to pick up plugins added by an update
+func InvalidateCache() {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registeredPlugins = nil
+}
+
+// loadRegistry returns a copy of the loaded plugins, the plugins get loaded with load if they aren't loaded yet
+func loadRegistry(load func() runpluginutil.PluginRegistry) runpluginutil.PluginRegistry {
+	registryLock.RLock()
+	loaded := registeredPlugins
+	registryLock.RUnlock()
+	if loaded != nil {
+		return copyRegistry(*loaded)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if registeredPlugins == nil {
+		plugins := load()
+		registeredPlugins = &plugins
+	}
+	return copyRegistry(*registeredPlugins)
+}
+
+// copyRegistry returns a shallow copy of the given registry
+func copyRegistry(registry runpluginutil.PluginRegistry) runpluginutil.PluginRegistry {
+	plugins := make(runpluginutil.PluginRegistry, len(registry))
+	for name, factory := range registry {
+		plugins[name] = factory
+	}
+	return plugins
 }
 
 // loadWorkers loads all worker plugins that are invokers for interacting with long running plugins and
 // then all standard worker plugins (if there are any conflicting names, the standard worker plugin wins)
-func loadWorkers(context context.T) {
+func loadWorkers(context context.T) runpluginutil.PluginRegistry {
 	plugins := runpluginutil.PluginRegistry{}
 
 	//Long running plugins are handled by lrpm. lrpminvoker is a worker plugin that can communicate with lrpm.
 	//that's why all long running plugins are first handled by lrpminvoker - which then hands off the work to lrpm.
-	plugins[appconfig.PluginNameCloudWatch] = CloudWatchFactory{}
+	for key, value := range registeredFactories(longRunningFactories) {
+		plugins[key] = value
+		context.Log().Infof("Successfully loaded long running plugin %v", key)
+	}
 
+	builtInPlugins := runpluginutil.PluginRegistry{}
 	for key, value := range loadPlatformIndependentPlugins(context) {
+		builtInPlugins[key] = value
 		plugins[key] = value
 		context.Log().Infof("Successfully loaded platform independent plugin %v", key)
 	}
 
 	for key, value := range loadPlatformDependentPlugins(context) {
+		builtInPlugins[key] = value
 		plugins[key] = value
 		context.Log().Infof("Successfully loaded platform dependent plugin %v", key)
 	}
 
-	registeredPlugins = &plugins
+	//plugins registered with RegisterWorkerPlugin can't replace built-in plugins
+	for key, value := range registeredFactories(workerFactories) {
+		if _, exists := builtInPlugins[key]; exists {
+			context.Log().Errorf("Duplicate worker plugin - %v already registered", key)
+			continue
+		}
+		plugins[key] = value
+		context.Log().Infof("Successfully loaded registered plugin %v", key)
+	}
+
+	for _, name := range context.AppConfig().Agent.DisabledPlugins {
+		if _, exists := plugins[runpluginutil.PluginName(name)]; exists {
+			delete(plugins, runpluginutil.PluginName(name))
+			context.Log().Infof("Plugin %v is disabled by the agent configuration", name)
+		}
+	}
+
+	return plugins
+}
+
+// pluginEntry is a built-in worker plugin, its name gets resolved when the plugin gets loaded
+type pluginEntry struct {
+	name    func() string
+	factory runpluginutil.PluginFactory
+}
+
+// named returns the name of a plugin entry whose name is known up front
+func named(name runpluginutil.PluginName) func() string {
+	return func() string { return string(name) }
+}
+
+// loadEntries returns the plugins of the given entries. Each entry gets loaded on its own, a panic while loading an
+// entry gets logged and only skips that plugin so that it doesn't prevent the remaining plugins from loading.
+func loadEntries(context context.T, entries []pluginEntry) runpluginutil.PluginRegistry {
+	plugins := runpluginutil.PluginRegistry{}
+	for _, entry := range entries {
+		if name, err := loadEntry(context, entry); err == nil {
+			plugins[name] = entry.factory
+		}
+	}
+	return plugins
+}
+
+// loadEntry resolves the name of the given plugin entry, a panic is reported as an error
+func loadEntry(context context.T, entry pluginEntry) (name runpluginutil.PluginName, err error) {
+	defer func() {
+		if msg := recover(); msg != nil {
+			context.Log().Errorf("Agent failed while loading worker plugin %v!", msg)
+			context.Log().Errorf("%s: %s", msg, debug.Stack())
+			err = fmt.Errorf("loading plugin panicked - %v", msg)
+		}
+	}()
+	return runpluginutil.PluginName(entry.name()), nil
 }
 
 // loadSessionPlugins loads all session plugins
-func loadSessionPlugins() {
+func loadSessionPlugins() runpluginutil.PluginRegistry {
 	var sessionPlugins = runpluginutil.PluginRegistry{}
 
-	standardStreamPluginName := appconfig.PluginNameStandardStream
+	standardStreamPluginName := runpluginutil.PluginNameStandardStream
 	sessionPlugins[standardStreamPluginName] = SessionPluginFactory{standardstream.NewPlugin}
 
-	interactiveCommandsPluginName := appconfig.PluginNameInteractiveCommands
+	interactiveCommandsPluginName := runpluginutil.PluginNameInteractiveCommands
 	sessionPlugins[interactiveCommandsPluginName] = SessionPluginFactory{interactivecommands.NewPlugin}
 
-	portPluginName := appconfig.PluginNamePort
+	portPluginName := runpluginutil.PluginNamePort
 	sessionPlugins[portPluginName] = SessionPluginFactory{port.NewPlugin}
 
-	registeredPlugins = &sessionPlugins
+	return sessionPlugins
 }
 
 // loadPlatformIndependentPlugins registers plugins common to all platforms
 func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegistry {
-	var workerPlugins = runpluginutil.PluginRegistry{}
-
-	inventoryPluginName := inventory.Name()
-	workerPlugins[inventoryPluginName] = InventoryGathererFactory{}
-
-	// registering aws:runPowerShellScript plugin
-	workerPlugins[appconfig.PluginNameAwsRunPowerShellScript] = RunPowerShellFactory{}
+	return loadEntries(context, []pluginEntry{
+		{inventory.Name, InventoryGathererFactory{}},
 
-	// registering aws:updateSsmAgent plugin
-	updateAgentPluginName := updatessmagent.Name()
-	workerPlugins[updateAgentPluginName] = UpdateAgentFactory{}
+		// registering aws:runPowerShellScript plugin
+		{named(runpluginutil.PluginNameAwsRunPowerShellScript), RunPowerShellFactory{}},
 
-	// registering aws:configureContainers plugin
-	configureContainersPluginName := configurecontainers.Name()
+		// registering aws:updateSsmAgent plugin
+		{updatessmagent.Name, UpdateAgentFactory{}},
 
-	workerPlugins[configureContainersPluginName] = ConfigureContainerFactory{}
+		// registering aws:configureContainers plugin
+		{configurecontainers.Name, ConfigureContainerFactory{}},
 
-	// registering aws:runDockerAction plugin
-	runDockerPluginName := dockercontainer.Name()
-	workerPlugins[runDockerPluginName] = RunDockerFactory{}
+		// registering aws:runDockerAction plugin
+		{dockercontainer.Name, RunDockerFactory{}},
 
-	// registering aws:refreshAssociation plugin
-	refreshAssociationPluginName := refreshassociation.Name()
-	workerPlugins[refreshAssociationPluginName] = RefreshAssociationFactory{}
+		// registering aws:refreshAssociation plugin
+		{refreshassociation.Name, RefreshAssociationFactory{}},
 
-	// registering aws:configurePackage
-	configurePackagePluginName := configurepackage.Name()
-	workerPlugins[configurePackagePluginName] = ConfigurePackageFactory{}
+		// registering aws:configurePackage
+		{configurepackage.Name, ConfigurePackageFactory{}},
 
-	//registering aws:downloadContent
-	downloadContentPluginName := downloadcontent.Name()
-	workerPlugins[downloadContentPluginName] = DownloadContentFactory{}
+		//registering aws:downloadContent
+		{downloadcontent.Name, DownloadContentFactory{}},
 
-	//registering aws:runDocument
-	runDocumentPluginName := rundocument.Name()
-	workerPlugins[runDocumentPluginName] = RunDocumentFactory{}
-
-	return workerPlugins
+		//registering aws:runDocument
+		{rundocument.Name, RunDocumentFactory{}},
+	})
 }
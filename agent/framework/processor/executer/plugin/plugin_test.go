@@ -0,0 +1,65 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisteredWorkerPluginsReturnsCopy(t *testing.T) {
+	ctx := context.NewMockDefault()
+
+	plugins := RegisteredWorkerPlugins(ctx)
+	expected := len(plugins)
+	delete(plugins, runpluginutil.PluginNameCloudWatch)
+	plugins["aws:notAPlugin"] = RunDocumentFactory{}
+
+	plugins = RegisteredWorkerPlugins(ctx)
+	assert.Len(t, plugins, expected)
+	assert.Contains(t, plugins, runpluginutil.PluginNameCloudWatch)
+	assert.NotContains(t, plugins, runpluginutil.PluginName("aws:notAPlugin"))
+}
+
+func TestInvalidateCacheReloadsPlugins(t *testing.T) {
+	defer swapFactories()()
+	ctx := context.NewMockDefault()
+	custom := func(context context.T) (runpluginutil.T, error) { return nil, nil }
+
+	assert.NotContains(t, RegisteredWorkerPlugins(ctx), runpluginutil.PluginName("aws:custom"))
+	RegisterWorkerPlugin("aws:custom", custom)
+	assert.NotContains(t, RegisteredWorkerPlugins(ctx), runpluginutil.PluginName("aws:custom"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NotEmpty(t, RegisteredWorkerPlugins(ctx))
+		}()
+		go func() {
+			defer wg.Done()
+			InvalidateCache()
+		}()
+	}
+	wg.Wait()
+
+	InvalidateCache()
+	assert.Contains(t, RegisteredWorkerPlugins(ctx), runpluginutil.PluginName("aws:custom"))
+	InvalidateCache()
+}
@@ -16,7 +16,6 @@
 package plugin
 
 import (
-	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/domainjoin"
@@ -39,10 +38,8 @@ func (f DomainJoinFactory) Create(context context.T) (runpluginutil.T, error) {
 
 // loadPlatformDependentPlugins registers platform dependent plugins
 func loadPlatformDependentPlugins(context context.T) runpluginutil.PluginRegistry {
-	var workerPlugins = runpluginutil.PluginRegistry{}
-
-	workerPlugins[appconfig.PluginNameAwsRunShellScript] = RunShellScriptFactory{}
-	workerPlugins[appconfig.PluginNameDomainJoin] = DomainJoinFactory{}
-
-	return workerPlugins
+	return loadEntries(context, []pluginEntry{
+		{named(runpluginutil.PluginNameAwsRunShellScript), RunShellScriptFactory{}},
+		{named(runpluginutil.PluginNameDomainJoin), DomainJoinFactory{}},
+	})
 }
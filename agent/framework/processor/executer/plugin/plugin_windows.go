@@ -57,23 +57,19 @@ func (f UpdateEc2ConfigFactory) Create(context context.T) (runpluginutil.T, erro
 
 // loadPlatformDependentPlugins registers platform dependent plugins
 func loadPlatformDependentPlugins(context context.T) runpluginutil.PluginRegistry {
-	var workerPlugins = runpluginutil.PluginRegistry{}
+	workerPlugins := loadEntries(context, []pluginEntry{
+		// registering aws:psModule plugin
+		{psmodule.Name, PsModuleFactory{}},
 
-	// registering aws:psModule plugin
-	psModulePluginName := psmodule.Name()
-	workerPlugins[psModulePluginName] = PsModuleFactory{}
+		// registering aws:applications plugin
+		{application.Name, ApplicationFactory{}},
 
-	// registering aws:applications plugin
-	applicationPluginName := application.Name()
-	workerPlugins[applicationPluginName] = ApplicationFactory{}
+		// registering aws:domainJoin plugin
+		{domainjoin.Name, DomainJoinFactory{}},
 
-	// registering aws:domainJoin plugin
-	domainJoinPluginName := domainjoin.Name()
-	workerPlugins[domainJoinPluginName] = DomainJoinFactory{}
-
-	// registering aws:updateAgent plugin.
-	updateEC2AgentPluginName := updateec2config.Name()
-	workerPlugins[updateEC2AgentPluginName] = UpdateEc2ConfigFactory{}
+		// registering aws:updateAgent plugin.
+		{updateec2config.Name, UpdateEc2ConfigFactory{}},
+	})
 
 	//// registering aws:configureDaemon
 	//configureDaemonPluginName := configuredaemon.Name()
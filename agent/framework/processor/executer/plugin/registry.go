@@ -0,0 +1,72 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains general interfaces and types relevant to plugins.
+// It also provides the methods for registering plugins.
+package plugin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+)
+
+// FactoryFunc creates a worker plugin
+type FactoryFunc func(context context.T) (runpluginutil.T, error)
+
+// Create creates the worker plugin, it makes FactoryFunc a runpluginutil.PluginFactory
+func (f FactoryFunc) Create(context context.T) (runpluginutil.T, error) {
+	return f(context)
+}
+
+var factoriesLock sync.Mutex
+
+// workerFactories are the worker plugin factories registered with RegisterWorkerPlugin by plugin name
+var workerFactories = runpluginutil.PluginRegistry{}
+
+// longRunningFactories are the worker plugin factories registered with RegisterLongRunningWorkerPlugin by plugin name
+var longRunningFactories = runpluginutil.PluginRegistry{}
+
+// RegisterWorkerPlugin registers the factory of a worker plugin, plugins are expected to register themselves from init()
+// so that they get loaded with the built-in worker plugins. Registering the same name twice panics.
+func RegisterWorkerPlugin(name runpluginutil.PluginName, factory func(context.T) (runpluginutil.T, error)) {
+	register(workerFactories, name, factory)
+}
+
+// RegisterLongRunningWorkerPlugin registers the factory of the worker plugin that hands off the work of a long running
+// plugin to lrpm. Standard worker plugins win over long running ones with the same name. Registering the same name twice panics.
+func RegisterLongRunningWorkerPlugin(name runpluginutil.PluginName, factory func(context.T) (runpluginutil.T, error)) {
+	register(longRunningFactories, name, factory)
+}
+
+// register adds the factory to the given registry
+func register(registry runpluginutil.PluginRegistry, name runpluginutil.PluginName, factory func(context.T) (runpluginutil.T, error)) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	if factory == nil {
+		panic(fmt.Sprintf("nil factory registered for plugin %v", name))
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("plugin %v registered twice", name))
+	}
+	registry[name] = FactoryFunc(factory)
+}
+
+// registeredFactories returns a copy of the given registry
+func registeredFactories(registry runpluginutil.PluginRegistry) runpluginutil.PluginRegistry {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	return copyRegistry(registry)
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// swapFactories replaces the registered factories for the duration of a test, the returned function restores them
+func swapFactories() func() {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	originalWorkers, originalLongRunning := workerFactories, longRunningFactories
+	workerFactories, longRunningFactories = runpluginutil.PluginRegistry{}, runpluginutil.PluginRegistry{}
+	return func() {
+		factoriesLock.Lock()
+		defer factoriesLock.Unlock()
+		workerFactories, longRunningFactories = originalWorkers, originalLongRunning
+	}
+}
+
+func TestRegisteredPluginsAreLoaded(t *testing.T) {
+	defer swapFactories()()
+
+	custom := func(context context.T) (runpluginutil.T, error) { return nil, nil }
+	RegisterWorkerPlugin("aws:custom", custom)
+	RegisterWorkerPlugin(runpluginutil.PluginNameRunDocument, custom)
+	RegisterLongRunningWorkerPlugin("aws:customDaemon", custom)
+	RegisterLongRunningWorkerPlugin(runpluginutil.PluginNameDownloadContent, custom)
+
+	plugins := loadWorkers(context.NewMockDefault())
+
+	assert.IsType(t, FactoryFunc(nil), plugins["aws:custom"])
+	assert.IsType(t, FactoryFunc(nil), plugins["aws:customDaemon"])
+	assert.IsType(t, RunDocumentFactory{}, plugins[runpluginutil.PluginNameRunDocument])
+	assert.IsType(t, DownloadContentFactory{}, plugins[runpluginutil.PluginNameDownloadContent])
+	assert.NotContains(t, plugins, runpluginutil.PluginNameCloudWatch)
+}
+
+func TestCloudWatchIsRegisteredAsLongRunningPlugin(t *testing.T) {
+	assert.Contains(t, registeredFactories(longRunningFactories), runpluginutil.PluginNameCloudWatch)
+}
+
+func TestRegisteringWorkerPluginTwicePanics(t *testing.T) {
+	defer swapFactories()()
+	custom := func(context context.T) (runpluginutil.T, error) { return nil, nil }
+	RegisterWorkerPlugin("aws:custom", custom)
+
+	assert.Panics(t, func() { RegisterWorkerPlugin("aws:custom", custom) })
+	assert.Panics(t, func() { RegisterLongRunningWorkerPlugin("aws:other", nil) })
+}
+
+func TestPanickingPluginDoesNotPreventSiblingsFromLoading(t *testing.T) {
+	ctx := context.NewMockDefault()
+	factory := new(runpluginutil.PluginFactoryMock)
+
+	plugins := loadEntries(ctx, []pluginEntry{
+		{named("aws:first"), factory},
+		{func() string { panic("unable to load plugin") }, factory},
+		{named("aws:second"), factory},
+	})
+
+	assert.Len(t, plugins, 2)
+	assert.Contains(t, plugins, runpluginutil.PluginName("aws:first"))
+	assert.Contains(t, plugins, runpluginutil.PluginName("aws:second"))
+	assert.Contains(t, loadPlatformIndependentPlugins(ctx), runpluginutil.PluginNameRunDocument)
+}
+
+func TestWorkerPluginsAreEnabledByDefault(t *testing.T) {
+	plugins := loadWorkers(context.NewMockDefault())
+
+	assert.Contains(t, plugins, runpluginutil.PluginNameAwsAgentUpdate)
+	assert.Contains(t, plugins, runpluginutil.PluginNameCloudWatch)
+}
+
+func TestDisabledWorkerPluginsAreNotLoaded(t *testing.T) {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.DisabledPlugins = []string{appconfig.PluginNameAwsAgentUpdate, appconfig.PluginNameCloudWatch}
+	ctx := new(context.Mock)
+	ctx.On("Log").Return(log.NewMockLog())
+	ctx.On("AppConfig").Return(config)
+
+	plugins := loadWorkers(ctx)
+
+	assert.NotContains(t, plugins, runpluginutil.PluginNameAwsAgentUpdate)
+	assert.NotContains(t, plugins, runpluginutil.PluginNameCloudWatch)
+	assert.Contains(t, plugins, runpluginutil.PluginNameRunDocument)
+}
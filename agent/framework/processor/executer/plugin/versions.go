@@ -0,0 +1,69 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains general interfaces and types relevant to plugins.
+// It also provides the methods for registering plugins.
+package plugin
+
+import (
+	"sort"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+)
+
+// UnknownPluginVersion is the version of plugins whose factory doesn't implement runpluginutil.VersionedPlugin
+const UnknownPluginVersion = "unknown"
+
+// PluginVersion is the version of a registered plugin
+type PluginVersion struct {
+	Name    runpluginutil.PluginName
+	Version string
+}
+
+// RegisteredWorkerPluginVersions returns the versions of all registered worker plugins ordered by name,
+// e.g. to include them in health reports
+func RegisteredWorkerPluginVersions(context context.T) []PluginVersion {
+	return pluginVersions(RegisteredWorkerPlugins(context))
+}
+
+// pluginVersions returns the versions of the given plugins ordered by name
+func pluginVersions(plugins runpluginutil.PluginRegistry) []PluginVersion {
+	versions := make([]PluginVersion, 0, len(plugins))
+	for name, factory := range plugins {
+		versions = append(versions, PluginVersion{Name: name, Version: pluginVersion(factory)})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Name < versions[j].Name
+	})
+	return versions
+}
+
+// pluginVersion returns the version reported by the given plugin factory
+func pluginVersion(factory runpluginutil.PluginFactory) string {
+	if lazy, isLazy := factory.(*lazyFactory); isLazy {
+		factory = lazy.factory
+	}
+	if versioned, isVersioned := factory.(runpluginutil.VersionedPlugin); isVersioned {
+		return versioned.Version()
+	}
+	return UnknownPluginVersion
+}
+
+// logPluginVersions logs the name and version of each of the given plugins
+func logPluginVersions(context context.T, plugins runpluginutil.PluginRegistry) {
+	log := context.Log()
+	for _, version := range pluginVersions(plugins) {
+		log.Infof("Plugin %-30v version %v", version.Name, version.Version)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// versionedFactory is a plugin factory reporting a version
+type versionedFactory struct {
+	RunDocumentFactory
+	version string
+}
+
+func (f versionedFactory) Name() string {
+	return "aws:versioned"
+}
+
+func (f versionedFactory) Version() string {
+	return f.version
+}
+
+func TestPluginVersions(t *testing.T) {
+	plugins := memoizePlugins(context.NewMockDefault(), runpluginutil.PluginRegistry{
+		"aws:versioned": versionedFactory{version: "1.2.3"},
+		"aws:plain":     RunDocumentFactory{},
+	})
+
+	assert.Equal(t, []PluginVersion{
+		{Name: "aws:plain", Version: UnknownPluginVersion},
+		{Name: "aws:versioned", Version: "1.2.3"},
+	}, pluginVersions(plugins))
+}
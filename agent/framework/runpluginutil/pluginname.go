@@ -0,0 +1,69 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runpluginutil run plugin utility functions without referencing the actually plugin impl packages
+package runpluginutil
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+)
+
+// PluginName is the name of a plugin as referenced by documents, e.g. aws:runShellScript
+type PluginName string
+
+// Names of the known worker plugins
+const (
+	PluginNameAwsAgentUpdate         PluginName = appconfig.PluginNameAwsAgentUpdate
+	PluginNameAwsApplications        PluginName = appconfig.PluginNameAwsApplications
+	PluginNameAwsConfigureDaemon     PluginName = appconfig.PluginNameAwsConfigureDaemon
+	PluginNameAwsConfigurePackage    PluginName = appconfig.PluginNameAwsConfigurePackage
+	PluginNameAwsPowerShellModule    PluginName = appconfig.PluginNameAwsPowerShellModule
+	PluginNameAwsRunPowerShellScript PluginName = appconfig.PluginNameAwsRunPowerShellScript
+	PluginNameAwsRunShellScript      PluginName = appconfig.PluginNameAwsRunShellScript
+	PluginNameAwsSoftwareInventory   PluginName = appconfig.PluginNameAwsSoftwareInventory
+	PluginNameCloudWatch             PluginName = appconfig.PluginNameCloudWatch
+	PluginNameConfigureDocker        PluginName = appconfig.PluginNameConfigureDocker
+	PluginNameDockerContainer        PluginName = appconfig.PluginNameDockerContainer
+	PluginNameDomainJoin             PluginName = appconfig.PluginNameDomainJoin
+	PluginNameEC2ConfigUpdate        PluginName = appconfig.PluginEC2ConfigUpdate
+	PluginNameRefreshAssociation     PluginName = appconfig.PluginNameRefreshAssociation
+	PluginNameDownloadContent        PluginName = appconfig.PluginDownloadContent
+	PluginNameRunDocument            PluginName = appconfig.PluginRunDocument
+)
+
+// Names of the known session plugins
+const (
+	PluginNameStandardStream      PluginName = appconfig.PluginNameStandardStream
+	PluginNameInteractiveCommands PluginName = appconfig.PluginNameInteractiveCommands
+	PluginNamePort                PluginName = appconfig.PluginNamePort
+)
+
+// ParsePluginName returns the PluginName of the given name, unknown names are rejected with an error
+func ParsePluginName(name string) (PluginName, error) {
+	pluginName := PluginName(name)
+	if !pluginName.IsKnown() {
+		return "", fmt.Errorf("unknown plugin %v", name)
+	}
+	return pluginName, nil
+}
+
+// IsKnown returns true if the plugin is known to this version of the agent, whether or not it's supported on this platform
+func (name PluginName) IsKnown() bool {
+	if _, known := allPlugins[name]; known {
+		return true
+	}
+	_, known := allSessionPlugins[name]
+	return known
+}
@@ -47,38 +47,45 @@ type PluginFactory interface {
 	Create(context context.T) (T, error)
 }
 
+// VersionedPlugin is implemented by plugin factories that report the name and version of the plugins they create,
+// plugin factories that don't implement it report an unknown version
+type VersionedPlugin interface {
+	Name() string
+	Version() string
+}
+
 // PluginRegistry stores a set of plugins (both worker and long running plugins), indexed by ID.
-type PluginRegistry map[string]PluginFactory
+type PluginRegistry map[PluginName]PluginFactory
 
 var SSMPluginRegistry PluginRegistry
 
 // allPlugins is the list of all known plugins.
 // This allows us to differentiate between the case where a document asks for a plugin that exists but isn't supported on this platform
 // and the case where a plugin name isn't known at all to this version of the agent (and the user should probably upgrade their agent)
-var allPlugins = map[string]struct{}{
-	appconfig.PluginNameAwsAgentUpdate:         {},
-	appconfig.PluginNameAwsApplications:        {},
-	appconfig.PluginNameAwsConfigureDaemon:     {},
-	appconfig.PluginNameAwsConfigurePackage:    {},
-	appconfig.PluginNameAwsPowerShellModule:    {},
-	appconfig.PluginNameAwsRunPowerShellScript: {},
-	appconfig.PluginNameAwsRunShellScript:      {},
-	appconfig.PluginNameAwsSoftwareInventory:   {},
-	appconfig.PluginNameCloudWatch:             {},
-	appconfig.PluginNameConfigureDocker:        {},
-	appconfig.PluginNameDockerContainer:        {},
-	appconfig.PluginNameDomainJoin:             {},
-	appconfig.PluginEC2ConfigUpdate:            {},
-	appconfig.PluginNameRefreshAssociation:     {},
-	appconfig.PluginDownloadContent:            {},
-	appconfig.PluginRunDocument:                {},
+var allPlugins = map[PluginName]struct{}{
+	PluginNameAwsAgentUpdate:         {},
+	PluginNameAwsApplications:        {},
+	PluginNameAwsConfigureDaemon:     {},
+	PluginNameAwsConfigurePackage:    {},
+	PluginNameAwsPowerShellModule:    {},
+	PluginNameAwsRunPowerShellScript: {},
+	PluginNameAwsRunShellScript:      {},
+	PluginNameAwsSoftwareInventory:   {},
+	PluginNameCloudWatch:             {},
+	PluginNameConfigureDocker:        {},
+	PluginNameDockerContainer:        {},
+	PluginNameDomainJoin:             {},
+	PluginNameEC2ConfigUpdate:        {},
+	PluginNameRefreshAssociation:     {},
+	PluginNameDownloadContent:        {},
+	PluginNameRunDocument:            {},
 }
 
 // allSessionPlugins is the list of all known session plugins.
-var allSessionPlugins = map[string]struct{}{
-	appconfig.PluginNameStandardStream:      {},
-	appconfig.PluginNameInteractiveCommands: {},
-	appconfig.PluginNamePort:                {},
+var allSessionPlugins = map[PluginName]struct{}{
+	PluginNameStandardStream:      {},
+	PluginNameInteractiveCommands: {},
+	PluginNamePort:                {},
 }
 
 // Assign method to global variables to allow unittest to override
@@ -161,7 +168,7 @@ func RunPlugins(
 			isSupported        bool
 		)
 
-		pluginFactory, pluginHandlerFound = registry[pluginName]
+		pluginFactory, pluginHandlerFound = registry[PluginName(pluginName)]
 		isKnown, isSupported, _ = isSupportedPlugin(context.Log(), pluginName)
 		operation, logMessage := getStepExecutionOperation(
 			context.Log(),
@@ -208,7 +215,7 @@ func RunPlugins(
 
 		// truncate the result and send it back to buffer channel.
 		result := *pluginOutputs[pluginID]
-		pluginConfig := iohandler.DefaultOutputConfig()
+		pluginConfig := pluginutil.PluginConfigFor(pluginName)
 		result.StandardOutput = pluginutil.StringPrefix(result.StandardOutput, pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix)
 		result.StandardError = pluginutil.StringPrefix(result.StandardError, pluginConfig.MaxStdoutLength, pluginConfig.OutputTruncatedSuffix)
 		// send to buffer channel, guaranteed to not block since buffer size is plugin number
@@ -326,7 +333,7 @@ func executePlugin(context context.T,
 	// Create the output object and execute the plugin
 	defer output.Close(log)
 	output.Init(log, pluginName, stepName)
-	plugin.Execute(context, config, cancelFlag, output)
+	executeWithTimeout(context, plugin, config, cancelFlag, output, contextTimeout(context, executionTimeout(config)))
 }
 
 // GetPropertyName returns the ID field of property in a v1.2 SSM Document
@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/log"
@@ -95,11 +96,11 @@ func TestRunPluginsWithNewDocument(t *testing.T) {
 			Output:        "",
 		}
 
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -266,18 +267,18 @@ func TestRunPluginsWithCancelFlagShutdown(t *testing.T) {
 			EndDateTime:   defaultTime,
 		}
 		if name == testPlugin1 {
-			plugins[name].On("Execute", ctx, pluginState.Configuration, cancelFlag, mock.Anything).Run(func(args mock.Arguments) {
+			plugins[name].On("Execute", ctx, pluginState.Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Run(func(args mock.Arguments) {
 				flag := args.Get(2).(task.CancelFlag)
 				flag.Set(task.ShutDown)
 			}).Return()
 
 		} else {
-			plugins[name].On("Execute", ctx, pluginState.Configuration, cancelFlag, mock.Anything).Return()
+			plugins[name].On("Execute", ctx, pluginState.Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 		}
 		pluginStates[index] = pluginState
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(plugins[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 	}
 
 	ch := make(chan contracts.PluginResult, 2)
@@ -339,12 +340,12 @@ func TestRunPluginsWithInProgressDocuments(t *testing.T) {
 			pluginState.Result = *pluginResults[name]
 		} else {
 			pluginState.Result.Status = contracts.ResultStatusNotStarted
-			plugins[name].On("Execute", ctx, pluginState.Configuration, cancelFlag, mock.Anything).Return()
+			plugins[name].On("Execute", ctx, pluginState.Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 		}
 		pluginStates[index] = pluginState
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(plugins[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 	}
 
 	ch := make(chan contracts.PluginResult, 2)
@@ -438,8 +439,8 @@ func TestRunPluginsWithDuplicatePluginType(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(plugin, nil)
-		plugin.On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return(*pluginResults[name])
-		pluginRegistry[pluginType] = pluginFactory
+		plugin.On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return(*pluginResults[name])
+		pluginRegistry[PluginName(pluginType)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -542,8 +543,8 @@ func TestRunPluginsWithCompatiblePlatformPrecondition(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -644,8 +645,8 @@ func TestRunPluginsWithCompatiblePlatformPreconditionWithValueFirst(t *testing.T
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -748,7 +749,7 @@ func TestRunPluginsWithIncompatiblePlatformPrecondition(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
 	called := 0
@@ -857,11 +858,11 @@ func TestRunPluginsWithCompatiblePreconditionButMissingPluginHandler(t *testing.
 				StartDateTime: defaultTime,
 				EndDateTime:   defaultTime,
 			}
-			pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return(*pluginResults[name])
+			pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return(*pluginResults[name])
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -975,7 +976,7 @@ func TestRunPluginsWithMoreThanOnePrecondition(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1082,7 +1083,7 @@ func TestRunPluginsWithUnrecognizedPreconditionOperator(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1192,7 +1193,7 @@ func TestRunPluginsWithUnrecognizedPreconditionDuplicateVariable(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1302,7 +1303,7 @@ func TestRunPluginsWithUnrecognizedPreconditionDuplicateParameter(t *testing.T)
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1412,7 +1413,7 @@ func TestRunPluginsWithUnrecognizedPreconditionDuplicateConstant(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1522,7 +1523,7 @@ func TestRunPluginsWithUnrecognizedPreconditionSSMParameter(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1632,7 +1633,7 @@ func TestRunPluginsWithUnrecognizedPreconditionSecureSSMParameter(t *testing.T)
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1742,7 +1743,7 @@ func TestRunPluginsWithUnrecognizedPreconditionNoDocumentParameters(t *testing.T
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1852,7 +1853,7 @@ func TestRunPluginsWithUnrecognizedPreconditionUnrecognizedParameter(t *testing.
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -1962,7 +1963,7 @@ func TestRunPluginsPlatformPreconditionWithIncompatibleArgument(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2063,8 +2064,8 @@ func TestRunPluginsWithCompatibleParamParamPrecondition(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2168,7 +2169,7 @@ func TestRunPluginsWithIncompatibleParamParamPrecondition(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
 	called := 0
@@ -2267,8 +2268,8 @@ func TestRunPluginsWithCompatibleParamValuePrecondition(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2369,8 +2370,8 @@ func TestRunPluginsWithCompatibleValueParamPrecondition(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2474,7 +2475,7 @@ func TestRunPluginsWithIncompatibleParamValuePrecondition(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
 	called := 0
@@ -2573,8 +2574,8 @@ func TestRunPluginsWithCompatibleMixedPrecondition(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginRegistry[PluginName(name)] = pluginFactory
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2678,7 +2679,7 @@ func TestRunPluginsWithIncompatibleMixedPrecondition(t *testing.T) {
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
 	called := 0
@@ -2789,7 +2790,7 @@ func TestRunPluginsWithMoreThanTwoPreconditionOperands(t *testing.T) {
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2907,11 +2908,11 @@ func TestRunPluginsWithUnknownPlugin(t *testing.T) {
 				StartDateTime: defaultTime,
 				EndDateTime:   defaultTime,
 			}
-			pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return(*pluginResults[name])
+			pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return(*pluginResults[name])
 		}
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -2988,11 +2989,11 @@ func TestRunPluginSuccessWithNonTruncatedResult(t *testing.T) {
 			StandardOutput: "",
 		}
 
-		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, cancelFlag, mock.Anything).Return()
+		pluginInstances[name].On("Execute", ctx, pluginConfigs[name].Configuration, mock.AnythingOfType("*task.ChanneledCancelFlag"), mock.Anything).Return()
 
 		pluginFactory := new(PluginFactoryMock)
 		pluginFactory.On("Create", mock.Anything).Return(pluginInstances[name], nil)
-		pluginRegistry[name] = pluginFactory
+		pluginRegistry[PluginName(name)] = pluginFactory
 
 		pluginConfigs2[index] = pluginConfigs[name]
 	}
@@ -3070,3 +3071,18 @@ func TestGetStepNameError(t *testing.T) {
 	_, err := getStepName(inputPluginName, config)
 	assert.Nil(t, err)
 }
+
+func TestParsePluginName(t *testing.T) {
+	name, err := ParsePluginName(appconfig.PluginNameCloudWatch)
+	assert.NoError(t, err)
+	assert.Equal(t, PluginNameCloudWatch, name)
+
+	name, err = ParsePluginName(appconfig.PluginNamePort)
+	assert.NoError(t, err)
+	assert.Equal(t, PluginNamePort, name)
+
+	_, err = ParsePluginName("aws:cloudwatch")
+	assert.Error(t, err)
+	_, err = ParsePluginName("")
+	assert.Error(t, err)
+}
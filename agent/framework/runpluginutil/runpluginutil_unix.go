@@ -29,9 +29,9 @@ func IsPluginSupportedForCurrentPlatform(log log.T, pluginName string) (isKnown
 	platformName, _ := platform.PlatformName(log)
 	platformVersion, _ := platform.PlatformVersion(log)
 
-	if _, known := allSessionPlugins[pluginName]; known == true {
+	if _, known := allSessionPlugins[PluginName(pluginName)]; known == true {
 		return known, true, fmt.Sprintf("%s v%s", platformName, platformVersion)
 	}
-	_, known := allPlugins[pluginName]
+	_, known := allPlugins[PluginName(pluginName)]
 	return known, true, fmt.Sprintf("%s v%s", platformName, platformVersion)
 }
@@ -34,11 +34,11 @@ func IsPluginSupportedForCurrentPlatform(log log.T, pluginName string) (isKnown
 	platformName, _ := platform.PlatformName(log)
 	platformVersion, _ := platform.PlatformVersion(log)
 
-	if _, known := allSessionPlugins[pluginName]; known == true {
+	if _, known := allSessionPlugins[PluginName(pluginName)]; known == true {
 		return known, isSupportedSessionPlugin(log, pluginName), fmt.Sprintf("%s v%s", platformName, platformVersion)
 	}
 
-	_, known := allPlugins[pluginName]
+	_, known := allPlugins[PluginName(pluginName)]
 	if isPlatformNanoServer, err := platform.IsPlatformNanoServer(log); err == nil && isPlatformNanoServer {
 		//if the current OS is Nano server, SSM Agent doesn't support the following plugins.
 		if pluginName == appconfig.PluginNameDomainJoin ||
@@ -0,0 +1,143 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package runpluginutil run plugin utility functions without referencing the actually plugin impl packages
+package runpluginutil
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// DefaultExecutionTimeoutSeconds is the time a plugin may execute for when the configuration doesn't set one.
+// It exceeds the longest timeout the plugins accept themselves, so it only stops plugins that ignore their own.
+const DefaultExecutionTimeoutSeconds = 176400
+
+var (
+	// timeoutCancelWaitDuration is how long a timed out plugin gets to return after its cancel flag got set
+	timeoutCancelWaitDuration = 10 * time.Second
+
+	// cancelFlagPollInterval is how often the cancel flag of the document gets checked while a plugin executes,
+	// the flag doesn't get set when the plugin completes so that it can't be waited for without a timeout
+	cancelFlagPollInterval = time.Second
+)
+
+// executionTimeout returns the time the plugin configured by config may execute for
+func executionTimeout(config contracts.Configuration) time.Duration {
+	if config.ExecutionTimeoutSeconds <= 0 {
+		return DefaultExecutionTimeoutSeconds * time.Second
+	}
+	return time.Duration(config.ExecutionTimeoutSeconds) * time.Second
+}
+
+// contextTimeout returns the given timeout, shortened to the deadline of the context if that expires earlier
+func contextTimeout(context context.T, timeout time.Duration) time.Duration {
+	deadline, ok := context.Deadline()
+	if !ok {
+		return timeout
+	}
+	if remaining := time.Until(deadline); remaining < timeout {
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	}
+	return timeout
+}
+
+// executeWithTimeout executes the plugin with a cancel flag of its own, which follows cancelFlag and gets canceled
+// once the timeout expires. The output is marked as timed out if the plugin was still executing at that time.
+// A timed out plugin that doesn't return within timeoutCancelWaitDuration is abandoned.
+func executeWithTimeout(
+	context context.T,
+	plugin T,
+	config contracts.Configuration,
+	cancelFlag task.CancelFlag,
+	output iohandler.IOHandler,
+	timeout time.Duration) {
+	log := context.Log()
+
+	pluginCancelFlag := task.NewChanneledCancelFlag()
+	done := make(chan struct{})
+	if cancelFlag != nil {
+		go followCancelFlag(cancelFlag, pluginCancelFlag, done, cancelFlagPollInterval)
+	}
+
+	// the plugin executes on a routine of its own so that it can be abandoned,
+	// a panic gets passed on to the caller once the plugin returned
+	var crash interface{}
+	go func() {
+		defer close(done)
+		defer func() {
+			crash = recover()
+		}()
+		plugin.Execute(context, config, pluginCancelFlag, output)
+	}()
+	returned := func() {
+		if crash != nil {
+			panic(crash)
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		returned()
+		return
+	case <-timer.C:
+	}
+
+	select {
+	case <-done:
+		// the plugin returned just as the timer fired
+		returned()
+		return
+	default:
+		pluginCancelFlag.SetWithReason(task.Canceled, task.CancelReasonTimeout)
+	}
+
+	cancelWait := time.NewTimer(timeoutCancelWaitDuration)
+	defer cancelWait.Stop()
+	select {
+	case <-done:
+		returned()
+	case <-cancelWait.C:
+		log.Errorf("Plugin %v didn't return within %v after it got canceled, abandoning it", config.PluginName, timeoutCancelWaitDuration)
+	}
+
+	log.Errorf("Plugin %v timed out after %v", config.PluginName, timeout)
+	output.AppendErrorf("Plugin timed out after %v", timeout)
+	output.SetExitCode(appconfig.CommandStoppedPreemptivelyExitCode)
+	output.SetStatus(contracts.ResultStatusTimedOut)
+}
+
+// followCancelFlag sets pluginCancelFlag to the state of cancelFlag once that gets set, or returns once done is closed
+func followCancelFlag(cancelFlag task.CancelFlag, pluginCancelFlag task.CancelFlag, done chan struct{}, pollInterval time.Duration) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if state, timedOut := cancelFlag.WaitWithTimeout(pollInterval); !timedOut {
+			pluginCancelFlag.Set(state)
+			return
+		}
+	}
+}
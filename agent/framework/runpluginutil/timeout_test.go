@@ -0,0 +1,179 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runpluginutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecutionTimeout(t *testing.T) {
+	assert.Equal(t, DefaultExecutionTimeoutSeconds*time.Second, executionTimeout(contracts.Configuration{}))
+	assert.Equal(t, DefaultExecutionTimeoutSeconds*time.Second, executionTimeout(contracts.Configuration{ExecutionTimeoutSeconds: -1}))
+	assert.Equal(t, 30*time.Second, executionTimeout(contracts.Configuration{ExecutionTimeoutSeconds: 30}))
+}
+
+func TestContextTimeout(t *testing.T) {
+	ctx := context.NewMockDefault()
+	assert.Equal(t, 30*time.Second, contextTimeout(ctx, 30*time.Second))
+
+	ctx = new(context.Mock)
+	ctx.On("Deadline").Return(time.Now().Add(time.Hour), true)
+	assert.Equal(t, 30*time.Second, contextTimeout(ctx, 30*time.Second))
+
+	ctx = new(context.Mock)
+	ctx.On("Deadline").Return(time.Now().Add(10*time.Second), true)
+	timeout := contextTimeout(ctx, 30*time.Second)
+	assert.True(t, timeout > 0 && timeout <= 10*time.Second)
+
+	ctx = new(context.Mock)
+	ctx.On("Deadline").Return(time.Now().Add(-time.Second), true)
+	assert.Equal(t, time.Duration(0), contextTimeout(ctx, 30*time.Second))
+}
+
+// waitingPlugin is a plugin that executes until its cancel flag is set
+type waitingPlugin struct {
+	observed task.State
+	reason   task.CancelReason
+}
+
+func (p *waitingPlugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	p.observed = cancelFlag.Wait()
+	p.reason = cancelFlag.Reason()
+}
+
+func TestExecuteWithTimeoutCancelsPluginAfterTimeout(t *testing.T) {
+	config := contracts.Configuration{PluginName: "aws:custom"}
+	output := new(iohandlermocks.MockIOHandler)
+	output.On("AppendErrorf", mock.Anything, mock.Anything).Return()
+	output.On("SetExitCode", appconfig.CommandStoppedPreemptivelyExitCode).Return()
+	output.On("SetStatus", contracts.ResultStatusTimedOut).Return()
+	plugin := &waitingPlugin{}
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	executeWithTimeout(context.NewMockDefault(), plugin, config, cancelFlag, output, 10*time.Millisecond)
+
+	assert.Equal(t, task.Canceled, plugin.observed)
+	assert.Equal(t, task.CancelReasonTimeout, plugin.reason)
+	assert.False(t, cancelFlag.Canceled())
+	output.AssertExpectations(t)
+}
+
+func TestExecuteWithTimeoutCompletesJustBeforeTimeout(t *testing.T) {
+	config := contracts.Configuration{PluginName: "aws:custom"}
+	output := new(iohandlermocks.MockIOHandler)
+	var pluginCancelFlag task.CancelFlag
+	plugin := new(PluginMock)
+	plugin.On("Execute", mock.Anything, config, mock.Anything, output).Return().Run(func(args mock.Arguments) {
+		pluginCancelFlag = args.Get(2).(task.CancelFlag)
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	executeWithTimeout(context.NewMockDefault(), plugin, config, task.NewChanneledCancelFlag(), output, 100*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.False(t, pluginCancelFlag.Canceled())
+	output.AssertNotCalled(t, "SetStatus", mock.Anything)
+	output.AssertNotCalled(t, "SetExitCode", mock.Anything)
+}
+
+func TestExecuteWithTimeoutFollowsCancelFlag(t *testing.T) {
+	output := new(iohandlermocks.MockIOHandler)
+	plugin := &waitingPlugin{}
+	cancelFlag := task.NewChanneledCancelFlag()
+	cancelFlag.Set(task.ShutDown)
+
+	executeWithTimeout(context.NewMockDefault(), plugin, contracts.Configuration{}, cancelFlag, output, time.Minute)
+
+	assert.Equal(t, task.ShutDown, plugin.observed)
+	output.AssertNotCalled(t, "SetStatus", mock.Anything)
+}
+
+// stuckPlugin is a plugin that ignores its cancel flag and executes until release is closed
+type stuckPlugin struct {
+	release chan struct{}
+}
+
+func (p *stuckPlugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
+	<-p.release
+}
+
+func TestExecuteWithTimeoutAbandonsPluginIgnoringCancellation(t *testing.T) {
+	original := timeoutCancelWaitDuration
+	timeoutCancelWaitDuration = 10 * time.Millisecond
+	defer func() { timeoutCancelWaitDuration = original }()
+	config := contracts.Configuration{PluginName: "aws:custom"}
+	output := new(iohandlermocks.MockIOHandler)
+	output.On("AppendErrorf", mock.Anything, mock.Anything).Return()
+	output.On("SetExitCode", appconfig.CommandStoppedPreemptivelyExitCode).Return()
+	output.On("SetStatus", contracts.ResultStatusTimedOut).Return()
+	plugin := &stuckPlugin{release: make(chan struct{})}
+	defer close(plugin.release)
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		executeWithTimeout(context.NewMockDefault(), plugin, config, task.NewChanneledCancelFlag(), output, 10*time.Millisecond)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		assert.Fail(t, "executeWithTimeout didn't abandon the plugin")
+	}
+	output.AssertExpectations(t)
+}
+
+func TestExecuteWithTimeoutStopsFollowingCancelFlagOnceCompleted(t *testing.T) {
+	original := cancelFlagPollInterval
+	cancelFlagPollInterval = time.Millisecond
+	defer func() { cancelFlagPollInterval = original }()
+	output := new(iohandlermocks.MockIOHandler)
+	var pluginCancelFlag task.CancelFlag
+	plugin := new(PluginMock)
+	plugin.On("Execute", mock.Anything, mock.Anything, mock.Anything, output).Return().Run(func(args mock.Arguments) {
+		pluginCancelFlag = args.Get(2).(task.CancelFlag)
+	})
+	cancelFlag := task.NewChanneledCancelFlag()
+
+	executeWithTimeout(context.NewMockDefault(), plugin, contracts.Configuration{}, cancelFlag, output, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	cancelFlag.Set(task.Canceled)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.False(t, pluginCancelFlag.Canceled())
+}
+
+func TestExecuteWithTimeoutPassesOnPanic(t *testing.T) {
+	output := new(iohandlermocks.MockIOHandler)
+	plugin := new(PluginMock)
+	plugin.On("Execute", mock.Anything, mock.Anything, mock.Anything, output).Return().Run(func(args mock.Arguments) {
+		panic("plugin crashed")
+	})
+
+	defer func() {
+		assert.Equal(t, "plugin crashed", recover())
+	}()
+	executeWithTimeout(context.NewMockDefault(), plugin, contracts.Configuration{}, task.NewChanneledCancelFlag(), output, time.Minute)
+	assert.Fail(t, "executeWithTimeout didn't pass on the panic")
+}
@@ -19,6 +19,7 @@ import (
 	"log"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
@@ -38,7 +39,26 @@ var (
 	dataStore    map[string]plugin.PluginInfo
 )
 
-type FsStore struct{}
+// SizeLimitError is returned when the data store file exceeds the maximum size it's allowed to have.
+// The oversized file is moved to BackupFileName, so that the next read starts from an empty data store.
+type SizeLimitError struct {
+	FileName       string
+	BackupFileName string
+	Size           int64
+	MaxSize        int64
+}
+
+// Error returns the description of the size violation
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("datastore file %s has %v bytes which exceeds the maximum of %v bytes - it was moved to %s",
+		e.FileName, e.Size, e.MaxSize, e.BackupFileName)
+}
+
+// FsStore is the file system based data store
+type FsStore struct {
+	// MaxSize is the maximum size of the data store file in bytes that gets loaded, 0 means no limit
+	MaxSize int64
+}
 
 // Write overwrites long running plugins specific data back to data store (file system)
 func (fs *FsStore) Write(data map[string]plugin.PluginInfo, location, fileName string) error {
@@ -94,6 +114,11 @@ func (fs *FsStore) load(fileName string) (map[string]plugin.PluginInfo, error) {
 		return data, nil
 	}
 
+	//check the size before loading so that a pathological file can't exhaust memory
+	if err = fs.checkSize(fileName); err != nil {
+		return data, err
+	}
+
 	err = jsonutil.UnmarshalFile(fileName, &data)
 
 	return data, err
@@ -103,3 +128,29 @@ func (fs *FsStore) load(fileName string) (map[string]plugin.PluginInfo, error) {
 func (fs *FsStore) dataStoreFileExist(fileName string) bool {
 	return fileutil.Exists(fileName)
 }
+
+// checkSize moves the data store file aside if it's bigger than allowed
+func (fs *FsStore) checkSize(fileName string) error {
+	if fs.MaxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	if info.Size() <= fs.MaxSize {
+		return nil
+	}
+
+	backupFileName := fmt.Sprintf("%s.oversized-%v", fileName, time.Now().Unix())
+	if err = os.Rename(fileName, backupFileName); err != nil {
+		return fmt.Errorf("datastore file %s has %v bytes which exceeds the maximum of %v bytes and it can't be moved aside: %v",
+			fileName, info.Size(), fs.MaxSize, err)
+	}
+	return &SizeLimitError{
+		FileName:       fileName,
+		BackupFileName: backupFileName,
+		Size:           info.Size(),
+		MaxSize:        fs.MaxSize,
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package datastore has utilites to read and write from long running plugins data-store
+package datastore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRejectsOversizedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lrpm-datastore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "plugins.json")
+
+	store := FsStore{}
+	data := map[string]plugin.PluginInfo{"plugin": {Name: "plugin", Configuration: "configuration"}}
+	assert.NoError(t, store.Write(data, dir, fileName))
+
+	read, err := store.Read(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "configuration", read["plugin"].Configuration)
+
+	store.MaxSize = 10
+	read, err = store.Read(fileName)
+	assert.Empty(t, read)
+	sizeErr, isTooLarge := err.(*SizeLimitError)
+	assert.True(t, isTooLarge)
+	assert.Equal(t, int64(10), sizeErr.MaxSize)
+	assert.False(t, fileutil.Exists(fileName))
+	assert.True(t, fileutil.Exists(sizeErr.BackupFileName))
+
+	// the oversized file was moved aside, so the data store is empty from now on
+	read, err = store.Read(fileName)
+	assert.NoError(t, err)
+	assert.Empty(t, read)
+}
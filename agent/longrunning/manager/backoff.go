@@ -0,0 +1,76 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// restartBackoff returns the time to wait before the next restart of a plugin that was restarted the given number of
+// times since it last ran stable. The first restart is immediate, then the wait starts at initial and doubles up to max.
+func restartBackoff(attempts int, initial, max time.Duration) time.Duration {
+	if attempts <= 0 || initial <= 0 {
+		return 0
+	}
+	backoff := initial
+	for i := 1; i < attempts && (max <= 0 || backoff < max); i++ {
+		backoff *= 2
+	}
+	if max > 0 && backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// restartDeferral returns how much longer the restart of the given plugin is deferred by its backoff, 0 if it can restart now
+func (m *Manager) restartDeferral(name string) time.Duration {
+	config := m.GetConfig()
+
+	m.statusLock.RLock()
+	attempts := m.restartAttempts[name]
+	lastAttempt := m.lastRestartAttempt[name]
+	m.statusLock.RUnlock()
+
+	remaining := restartBackoff(attempts, config.RestartBackoff, config.MaxRestartBackoff) - m.clock.Now().Sub(lastAttempt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// restartAttemptsOf returns the number of restarts of the given plugin since it last ran stable
+func (m *Manager) restartAttemptsOf(name string) int {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	return m.restartAttempts[name]
+}
+
+// recordRestartAttempt counts a restart of the given plugin, the count is reset once the plugin runs stable
+func (m *Manager) recordRestartAttempt(name string) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.restartAttempts == nil {
+		m.restartAttempts = map[string]int{}
+	}
+	if m.lastRestartAttempt == nil {
+		m.lastRestartAttempt = map[string]time.Time{}
+	}
+	m.restartAttempts[name]++
+	m.lastRestartAttempt[name] = m.clock.Now()
+	if m.restartAttempts[name] == crashLoopThreshold {
+		m.emit(EventCrashLooping, name, fmt.Sprintf("restarted %v times without running stable in between", crashLoopThreshold))
+	}
+}
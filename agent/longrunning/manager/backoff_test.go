@@ -0,0 +1,111 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// steppedClock is a clock whose time only changes when a test sets it
+type steppedClock struct {
+	now time.Time
+}
+
+func (c *steppedClock) Now() time.Time {
+	return c.now
+}
+
+func (c *steppedClock) After(d time.Duration) chan struct{} {
+	return times.DefaultClock.After(d)
+}
+
+func TestRestartBackoffDoublesUpToMax(t *testing.T) {
+	assert.Equal(t, time.Duration(0), restartBackoff(0, time.Minute, time.Hour))
+	assert.Equal(t, time.Minute, restartBackoff(1, time.Minute, time.Hour))
+	assert.Equal(t, 2*time.Minute, restartBackoff(2, time.Minute, time.Hour))
+	assert.Equal(t, 32*time.Minute, restartBackoff(6, time.Minute, time.Hour))
+	assert.Equal(t, time.Hour, restartBackoff(7, time.Minute, time.Hour))
+	assert.Equal(t, time.Hour, restartBackoff(1000, time.Minute, time.Hour))
+	assert.Equal(t, time.Duration(0), restartBackoff(5, 0, time.Hour))
+}
+
+func TestHealthCheckBacksOffRestartsOfCrashingPlugin(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(false)
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.startPlugin = pool
+
+	now := time.Now()
+	clock := &steppedClock{}
+	m.clock = clock
+	healthCheckAt := func(at time.Time) {
+		clock.now = at
+		m.ensurePluginsAreRunning()
+	}
+
+	// the first restart is immediate
+	healthCheckAt(now)
+	pool.AssertNumberOfCalls(t, "Submit", 1)
+
+	// the second restart waits for the initial backoff
+	healthCheckAt(now.Add(30 * time.Second))
+	pool.AssertNumberOfCalls(t, "Submit", 1)
+	healthCheckAt(now.Add(DefaultRestartBackoff))
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+
+	// the third restart waits twice as long
+	healthCheckAt(now.Add(DefaultRestartBackoff + DefaultRestartBackoff))
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+	healthCheckAt(now.Add(3 * DefaultRestartBackoff))
+	pool.AssertNumberOfCalls(t, "Submit", 3)
+
+	info := m.GetRunningPlugins()["plugin"]
+	assert.Equal(t, 3, info.RestartAttempts)
+	assert.Equal(t, now.Add(3*DefaultRestartBackoff), info.LastRestartAttempt)
+}
+
+func TestStableRunResetsRestartAttempts(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin"}
+	m.config.StableUptime = time.Minute
+
+	now := time.Now()
+	clock := &times.MockedClock{}
+	m.clock = clock
+	clock.On("Now").Return(now).Times(3)
+	m.recordRestartAttempt("plugin")
+	m.recordRestartAttempt("plugin")
+	m.recordStartResult("plugin", nil)
+	assert.Equal(t, 2, m.GetRunningPlugins()["plugin"].RestartAttempts)
+
+	clock.On("Now").Return(now.Add(time.Minute))
+	m.recordIsRunning("plugin", true)
+
+	assert.Equal(t, 0, m.GetRunningPlugins()["plugin"].RestartAttempts)
+	assert.Equal(t, time.Duration(0), m.restartDeferral("plugin"))
+}
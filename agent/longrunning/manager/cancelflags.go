@@ -0,0 +1,54 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// recordCancelFlag keeps the cancel flag a long running plugin got started with, so that the plugin can be
+// signaled when the agent shuts down
+func (m *Manager) recordCancelFlag(name string, cancelFlag task.CancelFlag) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.cancelFlags == nil {
+		m.cancelFlags = map[string]task.CancelFlag{}
+	}
+	m.cancelFlags[name] = cancelFlag
+}
+
+// forgetCancelFlag drops the cancel flag of a long running plugin that got stopped
+func (m *Manager) forgetCancelFlag(name string) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	delete(m.cancelFlags, name)
+}
+
+// signalCancelFlags sets the cancel flags of all started long running plugins according to the stop type - a soft
+// stop asks plugins to shut down, a hard stop cancels them, both with the shutdown reason
+func (m *Manager) signalCancelFlags(stopType contracts.StopType) {
+	state := task.Canceled
+	if stopType == contracts.StopTypeSoftStop {
+		state = task.ShutDown
+	}
+
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	for name, cancelFlag := range m.cancelFlags {
+		m.context.Log().Debugf("Signaling long running plugin - %s to stop", name)
+		cancelFlag.SetWithReason(state, task.CancelReasonShutdown)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// startWithCancelFlag starts the given registered plugin of the manager and returns the cancel flag it got started with
+func startWithCancelFlag(t *testing.T, m *Manager, handler *mockedPlugin, name string) *task.ChanneledCancelFlag {
+	cancelFlag := task.NewChanneledCancelFlag()
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, cancelFlag, mock.Anything).Return(nil)
+	assert.NoError(t, m.startPluginWithDefaultIO(m.registeredPlugins[name], cancelFlag))
+	return cancelFlag
+}
+
+func TestSoftStopShutsDownStartedPlugins(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	cancelFlag := startWithCancelFlag(t, m, handler, "plugin")
+
+	var stateWhileDraining task.State
+	pool := &task.MockedPool{}
+	pool.On("ShutdownAndWaitForJobs", mock.Anything).Return(true, nil).Run(func(mock.Arguments) { stateWhileDraining = cancelFlag.State() })
+	m.startPlugin = pool
+	m.stopPlugin = newDrainingPool(0)
+
+	assert.NoError(t, m.ModuleRequestStop(contracts.StopTypeSoftStop))
+	assert.Equal(t, task.ShutDown, stateWhileDraining)
+	assert.True(t, cancelFlag.ShutDown())
+}
+
+func TestHardStopCancelsStartedPlugins(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	cancelFlag := startWithCancelFlag(t, m, handler, "plugin")
+	m.startPlugin = newDrainingPool(0)
+	m.stopPlugin = newDrainingPool(0)
+
+	assert.NoError(t, m.ModuleRequestStop(contracts.StopTypeHardStop))
+	assert.True(t, cancelFlag.Canceled())
+	assert.Equal(t, task.CancelReasonShutdown, cancelFlag.Reason())
+}
+
+func TestFailedStartDoesNotKeepCancelFlag(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+
+	assert.Error(t, m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag()))
+	assert.Empty(t, m.cancelFlags)
+}
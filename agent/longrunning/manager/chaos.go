@@ -0,0 +1,86 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build chaos
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrInjectedFailure is returned by starts of long running plugins that fail because of an injected failure
+var ErrInjectedFailure = errors.New("injected failure of long running plugin")
+
+// injectedFailures are the failures injected into a long running plugin
+type injectedFailures struct {
+	failStarts int
+	notRunning bool
+}
+
+var (
+	injectionsLock sync.Mutex
+	injections     = map[string]*injectedFailures{}
+)
+
+// InjectStartFailures makes the next n starts of the given long running plugin fail with ErrInjectedFailure.
+// Failure injection only exists in builds with the chaos build tag.
+func InjectStartFailures(name string, n int) {
+	injectionsLock.Lock()
+	defer injectionsLock.Unlock()
+	injectionFor(name).failStarts = n
+}
+
+// InjectNotRunning makes the manager see the given long running plugin as not running, regardless of its IsRunning.
+// Failure injection only exists in builds with the chaos build tag.
+func InjectNotRunning(name string, notRunning bool) {
+	injectionsLock.Lock()
+	defer injectionsLock.Unlock()
+	injectionFor(name).notRunning = notRunning
+}
+
+// ClearInjectedFailures removes all injected failures
+func ClearInjectedFailures() {
+	injectionsLock.Lock()
+	defer injectionsLock.Unlock()
+	injections = map[string]*injectedFailures{}
+}
+
+// injectionFor returns the injected failures of a plugin. Callers are expected to hold injectionsLock.
+func injectionFor(name string) *injectedFailures {
+	if _, exists := injections[name]; !exists {
+		injections[name] = &injectedFailures{}
+	}
+	return injections[name]
+}
+
+// injectedStartFailure returns ErrInjectedFailure if a start failure was injected into the given plugin
+func injectedStartFailure(name string) error {
+	injectionsLock.Lock()
+	defer injectionsLock.Unlock()
+	if injected, exists := injections[name]; exists && injected.failStarts > 0 {
+		injected.failStarts--
+		return ErrInjectedFailure
+	}
+	return nil
+}
+
+// injectedNotRunning returns whether the given plugin has to be seen as not running
+func injectedNotRunning(name string) bool {
+	injectionsLock.Lock()
+	defer injectionsLock.Unlock()
+	injected, exists := injections[name]
+	return exists && injected.notRunning
+}
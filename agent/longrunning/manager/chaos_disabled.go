@@ -0,0 +1,29 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !chaos
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+// Failure injection is compiled out of builds without the chaos build tag, see chaos.go
+
+// injectedStartFailure never fails without the chaos build tag
+func injectedStartFailure(name string) error {
+	return nil
+}
+
+// injectedNotRunning never reports a plugin as not running without the chaos build tag
+func injectedNotRunning(name string) bool {
+	return false
+}
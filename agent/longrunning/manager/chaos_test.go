@@ -0,0 +1,88 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build chaos
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInjectedStartFailuresAreReportedAsStartFailed(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	defer ClearInjectedFailures()
+	m.setRunning(true)
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(false)
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	InjectStartFailures("plugin", 2)
+	for i := 0; i < 2; i++ {
+		err := m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag())
+		assert.Equal(t, ErrInjectedFailure, err)
+
+		reason, detail, err := m.WhyNotRunning("plugin")
+		assert.NoError(t, err)
+		assert.Equal(t, ReasonStartFailed, reason)
+		assert.Equal(t, ErrInjectedFailure.Error(), detail["lastError"])
+	}
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// the plugin recovers once the injected failures are used up
+	assert.NoError(t, m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag()))
+	handler.AssertNumberOfCalls(t, "Start", 1)
+}
+
+func TestInjectedNotRunningDrivesRestartsAndCrashLoop(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	defer ClearInjectedFailures()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.startPlugin = pool
+	// restart on every health check, the backoff is covered separately
+	m.config.RestartBackoff = 0
+
+	events, unsubscribe := m.Subscribe()
+	defer unsubscribe()
+
+	InjectNotRunning("plugin", true)
+	for i := 0; i < crashLoopThreshold; i++ {
+		m.ensurePluginsAreRunning()
+	}
+
+	pool.AssertNumberOfCalls(t, "Submit", crashLoopThreshold)
+	assert.Equal(t, crashLoopThreshold, m.restartAttempts["plugin"])
+	event := <-events
+	assert.Equal(t, EventCrashLooping, event.Type)
+	assert.Equal(t, "plugin", event.Plugin)
+
+	// the real IsRunning is used again once the injection is removed
+	InjectNotRunning("plugin", false)
+	m.ensurePluginsAreRunning()
+	pool.AssertNumberOfCalls(t, "Submit", crashLoopThreshold)
+}
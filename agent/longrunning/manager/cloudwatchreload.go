@@ -0,0 +1,57 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin/cloudwatch"
+)
+
+// assign the cloudwatch reload functions to variables so that tests can override them
+var (
+	watchCloudWatchReload         = cloudwatch.WatchReload
+	reloadCloudWatchConfiguration = cloudwatch.ReloadConfiguration
+)
+
+// startCloudWatchReload reloads the cloudwatch configuration in place whenever the OS level trigger of the platform
+// fires - SIGHUP on unix and a named event on windows. Nothing is watched unless the cloudwatch plugin is registered.
+func (m *Manager) startCloudWatchReload() {
+	lock.RLock()
+	_, isRegistered := m.registeredPlugins[appconfig.PluginNameCloudWatch]
+	lock.RUnlock()
+	if !isRegistered {
+		return
+	}
+	m.stopCloudWatchReload = watchCloudWatchReload(m.context.Log(), m.reloadCloudWatch)
+}
+
+// reloadCloudWatch re-reads the cloudwatch configuration file and reconfigures the running cloudwatch plugin with it
+func (m *Manager) reloadCloudWatch() {
+	log := m.context.Log()
+	err := reloadCloudWatchConfiguration(log, func(configuration string) error {
+		return m.Reconfigure(appconfig.PluginNameCloudWatch, configuration)
+	})
+	if err != nil {
+		log.Errorf("Failed to reload cloudwatch configuration - %v", err)
+	}
+}
+
+// stopCloudWatchReloadWatch stops reloading the cloudwatch configuration if it was started
+func (m *Manager) stopCloudWatchReloadWatch() {
+	if m.stopCloudWatchReload != nil {
+		m.stopCloudWatchReload()
+		m.stopCloudWatchReload = nil
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubCloudWatchReload replaces the cloudwatch reload trigger and configuration for the duration of a test
+func stubCloudWatchReload(configuration string) (trigger func(), stopped *bool, restore func()) {
+	var reload func()
+	isStopped := false
+	originalWatch, originalReload := watchCloudWatchReload, reloadCloudWatchConfiguration
+	watchCloudWatchReload = func(log log.T, r func()) func() {
+		reload = r
+		return func() { isStopped = true }
+	}
+	reloadCloudWatchConfiguration = func(log log.T, reconfigure func(string) error) error {
+		return reconfigure(configuration)
+	}
+	return func() { reload() }, &isStopped, func() {
+		watchCloudWatchReload, reloadCloudWatchConfiguration = originalWatch, originalReload
+	}
+}
+
+func TestCloudWatchReloadReconfiguresRunningPlugin(t *testing.T) {
+	trigger, stopped, restoreReload := stubCloudWatchReload("reloaded")
+	defer restoreReload()
+	handler := &mockedReconfigurePlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	name := appconfig.PluginNameCloudWatch
+	m.registeredPlugins[name] = managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: name}, Handler: handler}
+	m.runningPlugins[name] = managerContracts.PluginInfo{Name: name, Configuration: "old"}
+	handler.On("Reconfigure", mock.Anything, "reloaded", mock.Anything).Return(nil)
+	ds.On("Write", mock.Anything).Return(nil)
+
+	m.startCloudWatchReload()
+	trigger()
+	m.stopCloudWatchReloadWatch()
+
+	handler.AssertCalled(t, "Reconfigure", mock.Anything, "reloaded", mock.Anything)
+	assert.Equal(t, "reloaded", m.runningPlugins[name].Configuration)
+	assert.True(t, *stopped)
+}
+
+func TestCloudWatchReloadRequiresRegisteredPlugin(t *testing.T) {
+	_, _, restoreReload := stubCloudWatchReload("reloaded")
+	defer restoreReload()
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	m.startCloudWatchReload()
+
+	assert.Nil(t, m.stopCloudWatchReload)
+}
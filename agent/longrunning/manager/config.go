@@ -0,0 +1,110 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"time"
+)
+
+// ConflictPolicy defines how the manager handles an operation on a long running plugin requested while another
+// operation on the same plugin is still in progress
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyQueue applies the conflicting operation once the operation in progress is done
+	ConflictPolicyQueue ConflictPolicy = "Queue"
+
+	// ConflictPolicyReject rejects the conflicting operation with ErrPluginOperationConflict
+	ConflictPolicyReject ConflictPolicy = "Reject"
+)
+
+// DefaultMinFreeDiskBytes is the default available disk space below which the manager becomes read-only
+const DefaultMinFreeDiskBytes = 50 * 1024 * 1024
+
+// DefaultStableUptime is the default time a long running plugin has to stay up after a start to count as stable
+const DefaultStableUptime = 5 * time.Minute
+
+// DefaultRestartBackoff is the default time the manager waits before restarting a plugin again after its first restart
+const DefaultRestartBackoff = time.Minute
+
+// DefaultMaxRestartBackoff is the default maximum time the manager waits between restarts of a plugin
+const DefaultMaxRestartBackoff = time.Hour
+
+// DefaultMaxDataStoreBytes is the default maximum size of the data store file that gets loaded
+const DefaultMaxDataStoreBytes = 10 * 1024 * 1024
+
+// ManagerConfig holds the settings of the long running plugin manager
+type ManagerConfig struct {
+	// ConflictPolicy is applied when the same plugin is requested by conflicting documents
+	ConflictPolicy ConflictPolicy
+
+	// MaxRestartsPerMinute limits the restarts of long running plugins across all plugins, 0 means no limit
+	MaxRestartsPerMinute int
+
+	// MinFreeDiskBytes is the available disk space below which the manager becomes read-only, 0 disables the check
+	MinFreeDiskBytes int64
+
+	// MaxDataStoreBytes is the maximum size of the data store file that gets loaded, 0 means no limit.
+	// Changing it requires a restart
+	MaxDataStoreBytes int64
+
+	// MirrorCriticalEventsToConsole writes critical lifecycle events to the system console in addition to the agent log,
+	// so that they are visible on the EC2 console output even if the instance is otherwise unreachable
+	MirrorCriticalEventsToConsole bool
+
+	// StableUptime is the time a long running plugin has to stay up after a start before its restart attempts
+	// are reset, independent of the poll frequency
+	StableUptime time.Duration
+
+	// RestartBackoff is the time the manager waits after the first restart of a plugin before restarting it again,
+	// the wait doubles with each restart until the plugin runs stable. 0 disables the backoff
+	RestartBackoff time.Duration
+
+	// MaxRestartBackoff caps the time the manager waits between restarts of a plugin
+	MaxRestartBackoff time.Duration
+
+	// PollFrequency is the interval of the health check of long running plugins
+	PollFrequency time.Duration
+
+	// SoftStopTimeout is the time the manager waits for long running plugins to stop during a soft stop
+	SoftStopTimeout time.Duration
+
+	// HardStopTimeout is the time the manager waits for long running plugins to stop during a hard stop
+	HardStopTimeout time.Duration
+
+	// StartWorkers is the number of workers starting long running plugins, changing it requires a restart
+	StartWorkers int
+
+	// StopWorkers is the number of workers stopping long running plugins, changing it requires a restart
+	StopWorkers int
+}
+
+// DefaultManagerConfig returns the default settings of the long running plugin manager
+func DefaultManagerConfig() ManagerConfig {
+	return ManagerConfig{
+		ConflictPolicy:       ConflictPolicyQueue,
+		MaxRestartsPerMinute: 0,
+		MinFreeDiskBytes:     DefaultMinFreeDiskBytes,
+		MaxDataStoreBytes:    DefaultMaxDataStoreBytes,
+		StableUptime:         DefaultStableUptime,
+		RestartBackoff:       DefaultRestartBackoff,
+		MaxRestartBackoff:    DefaultMaxRestartBackoff,
+		PollFrequency:        PollFrequencyMinutes * time.Minute,
+		SoftStopTimeout:      SoftStopTimeout,
+		HardStopTimeout:      HardStopTimeout,
+		StartWorkers:         NumberOfLongRunningPluginWorkers,
+		StopWorkers:          NumberOfCancelWorkers,
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// consolePrefix tags the lines the manager writes to the system console
+const consolePrefix = "amazon-ssm-agent"
+
+// isCriticalEvent returns whether a lifecycle event is critical enough to get mirrored to the system console.
+// Only events that need the attention of an operator are critical, routine starts & stops would spam the console.
+func isCriticalEvent(eventType LifecycleEventType) bool {
+	switch eventType {
+	case EventQuarantined, EventCrashLooping, EventDegraded:
+		return true
+	default:
+		return false
+	}
+}
+
+// mirrorToConsole writes a lifecycle event to the system console
+func (m *Manager) mirrorToConsole(event LifecycleEvent) {
+	subject := "long running plugin manager"
+	if event.Plugin != "" {
+		subject = fmt.Sprintf("long running plugin %s", event.Plugin)
+	}
+	line := fmt.Sprintf("%s: %s %s %s: %s", consolePrefix, event.Time.UTC().Format(time.RFC3339), subject, event.Type, event.Detail)
+	if err := writeSystemConsole(line); err != nil {
+		m.context.Log().Debugf("Unable to write to the system console - %v", err)
+	}
+}
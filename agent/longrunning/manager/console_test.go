@@ -0,0 +1,88 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSystemConsole records the lines written to the system console, the returned function restores the real console
+func stubSystemConsole(lines *[]string) func() {
+	original := writeSystemConsole
+	writeSystemConsole = func(line string) error {
+		*lines = append(*lines, line)
+		return nil
+	}
+	return func() { writeSystemConsole = original }
+}
+
+func TestOnlyCriticalEventsAreMirroredToConsole(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	var lines []string
+	defer stubSystemConsole(&lines)()
+	m.config.MirrorCriticalEventsToConsole = true
+
+	m.emit(EventStarted, "plugin", "")
+	m.emit(EventStopped, "plugin", "")
+	m.emit(EventRestarted, "plugin", "")
+	m.emit(EventQuarantined, "plugin", "binary mismatch")
+	m.emit(EventCrashLooping, "plugin", "not running")
+	m.emit(EventDegraded, "", "read-only")
+
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.Contains(lines[0], "long running plugin plugin Quarantined: binary mismatch"), lines[0])
+	assert.True(t, strings.Contains(lines[1], "CrashLooping"), lines[1])
+	assert.True(t, strings.Contains(lines[2], "long running plugin manager Degraded: read-only"), lines[2])
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, consolePrefix), line)
+	}
+}
+
+func TestEventsAreNotMirroredToConsoleByDefault(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	var lines []string
+	defer stubSystemConsole(&lines)()
+
+	m.emit(EventQuarantined, "plugin", "binary mismatch")
+
+	assert.Empty(t, lines)
+}
+
+func TestRepeatedlyRestartedPluginIsReportedAsCrashLoopingOnce(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	var lines []string
+	defer stubSystemConsole(&lines)()
+	m.config.MirrorCriticalEventsToConsole = true
+
+	for i := 0; i < crashLoopThreshold-1; i++ {
+		m.recordRestartAttempt("plugin")
+	}
+	assert.Empty(t, lines)
+
+	m.recordRestartAttempt("plugin")
+	m.recordRestartAttempt("plugin")
+	assert.Len(t, lines, 1)
+
+	// a plugin seen running stable again starts over
+	m.recordIsRunning("plugin", true)
+	m.recordRestartAttempt("plugin")
+	assert.Len(t, lines, 1)
+}
@@ -0,0 +1,42 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build darwin freebsd linux netbsd openbsd
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"os"
+)
+
+// kernelMessageLog is where messages for the kernel log go, from there the kernel writes them to the serial console
+const kernelMessageLog = "/dev/kmsg"
+
+// systemConsole is used if the kernel log isn't available
+const systemConsole = "/dev/console"
+
+// writeToSystemConsole writes a line to the kernel log with critical priority, or to the console device if the
+// kernel log isn't available
+func writeToSystemConsole(line string) (err error) {
+	var f *os.File
+	if f, err = os.OpenFile(kernelMessageLog, os.O_WRONLY, 0); err == nil {
+		line = "<2>" + line
+	} else if f, err = os.OpenFile(systemConsole, os.O_WRONLY, 0); err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(line + "\n")
+	return
+}
@@ -0,0 +1,36 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// +build windows
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"os"
+)
+
+// serialPort is the port whose output EC2 captures as the console output of windows instances
+const serialPort = `\\.\COM1`
+
+// writeToSystemConsole writes a line to the serial port captured as the console output of the instance
+func writeToSystemConsole(line string) (err error) {
+	var f *os.File
+	if f, err = os.OpenFile(serialPort, os.O_WRONLY, 0); err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(line + "\r\n")
+	return
+}
@@ -16,14 +16,15 @@ package manager
 
 import (
 	"bytes"
+	gocontext "context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"path/filepath"
-
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
@@ -31,12 +32,12 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning"
+	"github.com/aws/amazon-ssm-agent/agent/longrunning/datastore"
 	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin/cloudwatch"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/aws/amazon-ssm-agent/agent/times"
-	"github.com/carlescere/scheduler"
 )
 
 const (
@@ -46,14 +47,14 @@ const (
 	// NameOfCloudWatchJsonFile is the name of ec2 config cloudwatch local configuration file
 	NameOfCloudWatchJsonFile = "AWS.EC2.Windows.CloudWatch.json"
 
-	//number of long running workers
-	NumberOfLongRunningPluginWorkers = 5
+	//default number of long running workers, see appconfig LongRunningPluginStartWorkers
+	NumberOfLongRunningPluginWorkers = appconfig.DefaultLongRunningPluginStartWorkers
 
-	//number of cancel workers
-	NumberOfCancelWorkers = 5
+	//default number of cancel workers, see appconfig LongRunningPluginStopWorkers
+	NumberOfCancelWorkers = appconfig.DefaultLongRunningPluginStopWorkers
 
-	//poll frequency for managing lifecycle of long running plugins
-	PollFrequencyMinutes = 15
+	//default poll frequency for managing lifecycle of long running plugins, see appconfig LongRunningPluginsPollFrequencyMinutes
+	PollFrequencyMinutes = appconfig.DefaultLongRunningPluginsPollFrequencyMinutes
 
 	//hardStopTimeout is the time before the manager will be shutdown during a hardstop = 4 seconds
 	HardStopTimeout = 4 * time.Second
@@ -66,9 +67,13 @@ const (
 type T interface {
 	contracts.ICoreModule
 	GetRegisteredPlugins() map[string]managerContracts.Plugin
+	GetRunningPlugins() map[string]managerContracts.PluginInfo
 	StopPlugin(name string, cancelFlag task.CancelFlag) (err error)
 	StartPlugin(name, configuration string, orchestrationDir string, cancelFlag task.CancelFlag, out iohandler.IOHandler) (err error)
+	SubmitStopPlugin(name string) error
+	SubmitStartPlugin(name, configuration, orchestrationDir string) error
 	EnsurePluginRegistered(name string, plugin managerContracts.Plugin) (err error)
+	AcquirePluginOperation(name, configuration string) (release func(), err error)
 }
 
 // Manager is the core module - that manages long running plugins
@@ -88,71 +93,258 @@ type Manager struct {
 	registeredPlugins map[string]managerContracts.Plugin
 
 	//manages lifecycle of all long running plugins
-	managingLifeCycleJob *scheduler.Job
+	managingLifeCycleJob times.Ticker
 
 	//manages file system related functions
 	fileSysUtil longrunning.FileSysUtil
 
 	//ec2config's configuration xml parser
 	ec2ConfigXmlParser cloudwatch.Ec2ConfigXmlParser
+
+	//clock used to track when long running plugins were last health checked
+	clock times.Clock
+
+	//guards lastHealthCheck
+	healthCheckLock sync.Mutex
+
+	//time of the latest health check of long running plugins
+	lastHealthCheck time.Time
+
+	//stops the health check watchdog
+	stopWatchdog chan struct{}
+
+	//stops reloading the cloudwatch configuration on the OS level trigger
+	stopCloudWatchReload func()
+
+	//guards running, persistenceDegraded, lastIsRunning, restart attempts, startedAt, lastStartFailure, quarantined, disk pressure state & resourceUsage
+	statusLock sync.RWMutex
+
+	//true while the manager is executing
+	running bool
+
+	//true if the latest write to the data store failed
+	persistenceDegraded bool
+
+	//latest IsRunning result of each long running plugin
+	lastIsRunning map[string]bool
+
+	//number of restarts of each long running plugin since it last ran stable
+	restartAttempts map[string]int
+
+	//time of the latest restart of each long running plugin
+	lastRestartAttempt map[string]time.Time
+
+	//time of the latest successful restart of each long running plugin
+	lastRestart map[string]time.Time
+
+	//time of the latest successful start of each long running plugin
+	startedAt map[string]time.Time
+
+	//long running plugins with a start in progress
+	starting map[string]bool
+
+	//cancel flag of the latest start of each long running plugin
+	cancelFlags map[string]task.CancelFlag
+
+	//latest failed start of each long running plugin
+	lastStartFailure map[string]startFailure
+
+	//quarantined long running plugins with the reason of their quarantine
+	quarantined map[string]string
+
+	//whether the manager is read-only because of disk pressure
+	readOnly bool
+
+	//latest available disk space in bytes
+	availableDiskBytes int64
+
+	//whether persisting long running plugins was skipped while the manager was read-only
+	persistencePending bool
+
+	//resource usage of all running long running plugins as of the latest health check
+	resourceUsage resourceUsageTotals
+
+	//guards config
+	configLock sync.RWMutex
+
+	//settings of the manager
+	config ManagerConfig
+
+	//records the metrics of the manager
+	metrics MetricsRecorder
+
+	//serves the health report of long running plugins, nil unless ServeHealth was called
+	healthServer *http.Server
+
+	//guards operations
+	operationsLock sync.Mutex
+
+	//operations in progress, keyed by long running plugin name
+	operations map[string]*pluginOperation
+
+	//paces restarts of long running plugins across all plugins
+	restartLimiter *restartLimiter
+
+	//guards closeWatches
+	closeWatchesLock sync.Mutex
+
+	//quit channels of the targeted health checks started by WatchClosely, keyed by long running plugin name
+	closeWatches map[string]chan struct{}
+
+	//guards secretProviders
+	secretProvidersLock sync.Mutex
+
+	//providers resolving secret references in configurations of long running plugins, keyed by provider name
+	secretProviders map[string]SecretProvider
+
+	//guards subscribers
+	subscribersLock sync.Mutex
+
+	//channels of the subscribers of lifecycle events
+	subscribers map[chan LifecycleEvent]struct{}
 }
 
 var singletonInstance *Manager
-var once sync.Once
 
-// EnsureManagerIsInitialized ensures that manager is initialized at least once
-func EnsureInitialization(context context.T) {
+// initErr is the error of the latest failed initialization of the manager
+var initErr error
+
+// singletonLock guards singletonInstance and initErr
+var singletonLock sync.RWMutex
+
+// initLock serializes the initializations of the manager, it's held while the manager gets built
+var initLock sync.Mutex
+
+// ErrNotInitialized is returned by GetInstance when the manager was never initialized
+var ErrNotInitialized error = notInitializedError{}
+
+// notInitializedError is the error of GetInstance before the manager got initialized
+type notInitializedError struct{}
+
+// Error describes the missing initialization
+func (notInitializedError) Error() string {
+	return "lrpm isn't initialized yet"
+}
+
+// Temporary reports that the manager gets initialized by the agent, a later call can succeed
+func (notInitializedError) Temporary() bool {
+	return true
+}
+
+// InitializationError is returned by GetInstance when the latest initialization of the manager failed
+type InitializationError struct {
+	Err error
+}
+
+// Error describes why the initialization failed
+func (e *InitializationError) Error() string {
+	return fmt.Sprintf("lrpm initialization failed - %v", e.Err)
+}
+
+// EnsureManagerIsInitialized ensures that manager is initialized at least once.
+// The metrics of the manager are recorded with the given recorder, nil drops them.
+// If the initialization fails the error is returned and a later call tries again.
+func EnsureInitialization(context context.T, metrics MetricsRecorder) error {
 	//todo: After we start using 1 task pool for entire agent (even for core modules), we can then move all initializations to init()
 
 	//only components with access to context are expected to call this
 
 	//this ensures that only one instance of lrpm exists
-	once.Do(func() {
-		managerContext := context.With("[" + Name + "]")
-		log := managerContext.Log()
-		//initialize pluginsInfo (which will store all information about long running plugins)
-		plugins := map[string]managerContracts.PluginInfo{}
-		//load all registered plugins
-		regPlugins := RegisteredPlugins(context)
-		jsonB, _ := json.Marshal(&regPlugins)
-		log.Infof("registered plugins: %s", string(jsonB))
-
-		// startPlugin and stopPlugin will be processed by separate worker pools
-		// so we can define the number of workers for each pool
-		cancelWaitDuration := 10000 * time.Millisecond
-		clock := times.DefaultClock
-		startPluginPool := task.NewPool(log, NumberOfLongRunningPluginWorkers, cancelWaitDuration, clock)
-		stopPluginPool := task.NewPool(log, NumberOfCancelWorkers, cancelWaitDuration, clock)
-
-		fileSysUtil := &longrunning.FileSysUtilImpl{}
-
-		ec2ConfigXmlParser := &cloudwatch.Ec2ConfigXmlParserImpl{
-			FileSysUtil: fileSysUtil,
-		}
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	singletonLock.RLock()
+	initialized := singletonInstance != nil
+	singletonLock.RUnlock()
+	if initialized {
+		return nil
+	}
 
-		singletonInstance = &Manager{
-			context:            managerContext,
-			startPlugin:        startPluginPool,
-			stopPlugin:         stopPluginPool,
-			runningPlugins:     plugins,
-			registeredPlugins:  regPlugins,
-			fileSysUtil:        fileSysUtil,
-			ec2ConfigXmlParser: ec2ConfigXmlParser,
-		}
-	})
+	instance, err := newManager(context, metrics)
 
+	singletonLock.Lock()
+	defer singletonLock.Unlock()
+	if err != nil {
+		initErr = err
+		return err
+	}
+	initErr = nil
+	singletonInstance = instance
+	return nil
 }
 
-// GetInstance returns an instance of Manager if its initialized otherwise it returns an error
+// newManager builds the manager and its dependencies
+func newManager(context context.T, metrics MetricsRecorder) (*Manager, error) {
+	managerContext := context.With("[" + Name + "]")
+	log := managerContext.Log()
+	//initialize pluginsInfo (which will store all information about long running plugins)
+	plugins := map[string]managerContracts.PluginInfo{}
+	//load all registered plugins
+	regPlugins := RegisteredPlugins(context)
+	jsonB, err := marshalRegisteredPlugins(&regPlugins)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal registered plugins - %v", err)
+	}
+	log.Infof("registered plugins: %s", string(jsonB))
+
+	// startPlugin and stopPlugin will be processed by separate worker pools
+	// so we can define the number of workers for each pool
+	cancelWaitDuration := 10000 * time.Millisecond
+	clock := times.DefaultClock
+	config := loadManagerConfig(log, context.AppConfig())
+	log.Infof("using %v workers to start and %v workers to stop long running plugins", config.StartWorkers, config.StopWorkers)
+	startPluginPool, err := newTaskPool(log, config.StartWorkers, cancelWaitDuration, clock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the %s pool - %v", startPluginPoolName, err)
+	}
+	stopPluginPool, err := newTaskPool(log, config.StopWorkers, cancelWaitDuration, clock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the %s pool - %v", stopPluginPoolName, err)
+	}
+
+	fileSysUtil := &longrunning.FileSysUtilImpl{}
+
+	ec2ConfigXmlParser := &cloudwatch.Ec2ConfigXmlParserImpl{
+		FileSysUtil: fileSysUtil,
+	}
+
+	if metrics == nil {
+		metrics = noMetrics{}
+	}
+
+	dataStore = ds{
+		dsImpl: datastore.FsStore{MaxSize: config.MaxDataStoreBytes},
+	}
+
+	return &Manager{
+		context:            managerContext,
+		startPlugin:        startPluginPool,
+		stopPlugin:         stopPluginPool,
+		runningPlugins:     plugins,
+		registeredPlugins:  regPlugins,
+		fileSysUtil:        fileSysUtil,
+		ec2ConfigXmlParser: ec2ConfigXmlParser,
+		clock:              clock,
+		config:             config,
+		restartLimiter:     newRestartLimiter(clock, config.MaxRestartsPerMinute),
+		secretProviders:    defaultSecretProviders(log),
+		metrics:            metrics,
+	}, nil
+}
+
+// GetInstance returns an instance of Manager if its initialized otherwise it returns ErrNotInitialized,
+// or an InitializationError if the latest initialization failed
 func GetInstance() (*Manager, error) {
-	lock.Lock()
-	defer lock.Unlock()
+	singletonLock.RLock()
+	defer singletonLock.RUnlock()
 
-	if singletonInstance == nil {
-		return nil, errors.New("lrpm isn't initialized yet")
-	} else {
+	if singletonInstance != nil {
 		return singletonInstance, nil
 	}
+	if initErr != nil {
+		return nil, &InitializationError{Err: initErr}
+	}
+	return nil, ErrNotInitialized
 }
 
 // GetRegisteredPlugins returns a map of all registered long running plugins
@@ -160,6 +352,25 @@ func (m *Manager) GetRegisteredPlugins() map[string]managerContracts.Plugin {
 	return m.registeredPlugins
 }
 
+// GetRunningPlugins returns a copy of the long running plugins currently managed by the manager,
+// changes to the returned map don't affect the manager
+func (m *Manager) GetRunningPlugins() map[string]managerContracts.PluginInfo {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+
+	runningPlugins := make(map[string]managerContracts.PluginInfo, len(m.runningPlugins))
+	for name, info := range m.runningPlugins {
+		info.RestartAttempts = m.restartAttempts[name]
+		info.LastRestartAttempt = m.lastRestartAttempt[name]
+		info.Starting = m.starting[name]
+		runningPlugins[name] = info
+	}
+	return runningPlugins
+}
+
 // Name returns the module name
 func (m *Manager) ModuleName() string {
 	return Name
@@ -173,6 +384,11 @@ func (m *Manager) ModuleExecute(context context.T) (err error) {
 	//read from data store to determine if there were any previously long running plugins which need to be started again
 	var dataStoreMap map[string]managerContracts.PluginInfo
 	dataStoreMap, err = dataStore.Read()
+	if sizeErr, isTooLarge := err.(*datastore.SizeLimitError); isTooLarge {
+		//refuse to load a pathological data store - boot with empty state instead
+		log.Criticalf("Starting with no long running plugins - %s", sizeErr)
+		dataStoreMap, err = nil, nil
+	}
 	if len(dataStoreMap) != 0 {
 		m.runningPlugins = dataStoreMap
 	}
@@ -181,48 +397,64 @@ func (m *Manager) ModuleExecute(context context.T) (err error) {
 		log.Errorf("%s is exiting - unable to read from data store", m.ModuleName())
 		return
 	}
+	m.setRunning(true)
+	m.migratePersistedConfigurations()
 
 	//revive older long running plugins if they were running before
 	if len(m.runningPlugins) > 0 {
+		pruned := false
 		for pluginName, pluginInfo := range m.runningPlugins {
 			//get the corresponding registered plugin
-			p, exists := m.registeredPlugins[pluginName]
+			p, exists := m.registeredPluginOrInstance(pluginName)
 			if !exists {
 				//remove previously running plugins with no registered handlers
+				log.Warnf("Removing previously executing long running plugin %s - it's no longer registered", pluginName)
 				delete(m.runningPlugins, pluginName)
+				pruned = true
 				continue
 			}
-			p.Info = pluginInfo
+			//only take over the persisted configuration, and the priority and tags restored from a snapshot -
+			//everything else is defined by the registered plugin
+			p.Info.Configuration = pluginInfo.Configuration
+			p.Info.State = pluginInfo.State
+			p.Info.OrchestrationDir = pluginInfo.OrchestrationDir
+			if pluginInfo.Priority != 0 {
+				p.Info.Priority = pluginInfo.Priority
+			}
+			if len(pluginInfo.Tags) > 0 {
+				p.Info.Tags = pluginInfo.Tags
+			}
+			m.registeredPlugins[pluginName] = p
 			if pluginName == appconfig.PluginNameCloudWatch {
 				//skip CW plugin since it'll be handled later
 				continue
 			}
+			if isLazyInactive(p, pluginInfo) {
+				log.Infof("Not starting lazy long running plugin %s until it gets activated", pluginName)
+				continue
+			}
+			if reason, isQuarantined := m.quarantineReason(pluginName); isQuarantined {
+				log.Warnf("Not starting previously executing long running plugin %s since it's quarantined - %s", pluginName, reason)
+				continue
+			}
 			log.Infof("Detected %s as a previously executing long running plugin. Starting that plugin again", p.Info.Name)
 			//submit the work of long running plugin to the task pool
 			/*
-				Note: All long running plugins are singleton in nature - hence jobId = plugin name.
-				This is in sync with our task-pool - which rejects jobs with duplicate jobIds.
+				Note: All long running plugins are singleton in nature - a plugin is marked as starting until its
+				start returns, and any other start of the plugin in the meantime is skipped.
 			*/
-			//todo: orchestrationDir should be set accordingly - 3rd parameter for Start
-			instanceID, _ := platform.InstanceID()
-			orchestrationRootDir := filepath.Join(
-				appconfig.DefaultDataStorePath,
-				instanceID,
-				appconfig.DefaultDocumentRootDirName,
-				m.context.AppConfig().Agent.OrchestrationRootDir)
-			orchestrationDir := fileutil.BuildPath(orchestrationRootDir)
-
-			ioConfig := contracts.IOConfiguration{
-				OrchestrationDirectory: orchestrationDir,
-				OutputS3BucketName:     "",
-				OutputS3KeyPrefix:      "",
+			if err := m.startPluginOnce(p, task.NewChanneledCancelFlag()); err == nil {
+				m.emit(EventStarted, pluginName, "")
 			}
-			out := iohandler.NewDefaultIOHandler(log, ioConfig)
-			defer out.Close(log)
-			out.Init(log, p.Info.Name)
-			p.Handler.Start(m.context, p.Info.Configuration, "", task.NewChanneledCancelFlag(), out)
-			out.Close(log)
-			m.registeredPlugins[pluginName] = p
+		}
+
+		//persist the removal of plugins without registered handlers so that the data store doesn't drift
+		if pruned {
+			lock.Lock()
+			if err := m.writeDataStore(); err != nil {
+				log.Errorf("Failed to update datastore - because of %s", err)
+			}
+			lock.Unlock()
 		}
 	} else {
 		log.Infof("there aren't any long running plugin to execute")
@@ -234,79 +466,189 @@ func (m *Manager) ModuleExecute(context context.T) (err error) {
 		m.configCloudWatch(log)
 	}
 
+	//reload the cloudwatch configuration in place when operators ask for it, e.g. with SIGHUP
+	m.startCloudWatchReload()
+
 	//schedule periodic health check of all long running plugins
-	if m.managingLifeCycleJob, err = scheduler.Every(PollFrequencyMinutes).Minutes().Run(m.ensurePluginsAreRunning); err != nil {
-		context.Log().Errorf("unable to schedule long running plugins manager. %v", err)
-	}
+	pollFrequency := m.GetConfig().PollFrequency
+	m.managingLifeCycleJob = m.scheduleHealthCheck(pollFrequency, true)
+
+	//force a health check if the ticker stalls, e.g. because of a clock step
+	m.startHealthCheckWatchdog(pollFrequency)
 
 	return
 }
 
-// RequestStop handles the termination of the long running plugin manager
+// StopInterruptedError is returned when the manager is asked to stop and its context is done before the task pools drained
+type StopInterruptedError struct {
+	Err error
+}
+
+// Error describes why the stop got interrupted
+func (e *StopInterruptedError) Error() string {
+	return fmt.Sprintf("long running plugin manager stopped before its task pools drained - %v", e.Err)
+}
+
+// Unwrap returns the error of the context that interrupted the stop
+func (e *StopInterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// PoolShutdownError is returned when task pools of the manager didn't shut down in time, leaving plugins running
+type PoolShutdownError struct {
+	Pools []string
+}
+
+// Error lists the task pools that didn't shut down in time
+func (e *PoolShutdownError) Error() string {
+	return fmt.Sprintf("task pools %s of the long running plugin manager didn't shut down in time", strings.Join(e.Pools, ", "))
+}
+
+const (
+	startPluginPoolName = "start plugin"
+	stopPluginPoolName  = "stop plugin"
+
+	// stopDeadlineSlack is how long before the deadline of the stop context the task pools give up on their jobs
+	stopDeadlineSlack = 100 * time.Millisecond
+)
+
+// poolShutdown collects the task pools that didn't shut down in time, it's safe for concurrent use
+type poolShutdown struct {
+	lock   sync.Mutex
+	failed []string
+}
+
+// wait shuts down the given pool and records it if it doesn't shut down within the timeout, the jobs that are
+// still running once the pool gave up on them are logged as abandoned
+func (s *poolShutdown) wait(log log.T, name string, pool task.Pool, timeout time.Duration) {
+	finished, unfinished := pool.ShutdownAndWaitForJobs(timeout)
+	if len(unfinished) > 0 {
+		log.Warnf("Abandoned jobs %s of the %s pool since they didn't stop within %v", strings.Join(unfinished, ", "), name, timeout)
+	}
+	if finished {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failed = append(s.failed, name)
+}
+
+// err returns a PoolShutdownError if any pool didn't shut down in time
+func (s *poolShutdown) err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.failed) == 0 {
+		return nil
+	}
+	pools := append([]string{}, s.failed...)
+	sort.Strings(pools)
+	return &PoolShutdownError{Pools: pools}
+}
+
+// RequestStop handles the termination of the long running plugin manager, the task pools get the stop timeout of
+// the stop type to drain and the call returns once they gave up on their jobs
 func (m *Manager) ModuleRequestStop(stopType contracts.StopType) (err error) {
-	var waitTimeout time.Duration
+	return m.requestStop(gocontext.Background(), stopType)
+}
 
-	if stopType == contracts.StopTypeSoftStop {
-		waitTimeout = SoftStopTimeout
-	} else {
-		waitTimeout = HardStopTimeout
+// RequestStopWithContext gracefully stops the long running plugin manager within the deadline of the given context
+// instead of the configured stop timeouts. It returns a StopInterruptedError if the context is done before both task
+// pools drained.
+func (m *Manager) RequestStopWithContext(ctx gocontext.Context) error {
+	return m.requestStop(ctx, contracts.StopTypeSoftStop)
+}
+
+// requestStop stops the long running plugin manager, the task pools get until the deadline of ctx to drain or the
+// stop timeout of the stop type if ctx has no deadline
+func (m *Manager) requestStop(ctx gocontext.Context, stopType contracts.StopType) error {
+	waitTimeout := m.stopTimeout(stopType)
+	if deadline, ok := ctx.Deadline(); ok {
+		// the pools give up just before the deadline, so that the ones that didn't shut down can be reported
+		waitTimeout = time.Until(deadline) - stopDeadlineSlack
+		if waitTimeout < 0 {
+			waitTimeout = 0
+		}
 	}
 
 	var wg sync.WaitGroup
 
 	// stop lifecycle management job that monitors execution of all long running plugins
 	m.stopLifeCycleManagementJob()
+	m.setRunning(false)
+	m.stopHealthServer()
+
+	// give the plugins a clean signal to stop their own work before the pools drop their jobs
+	m.signalCancelFlags(stopType)
 
 	//there is no need to stop all individual plugins - because when the task pools are shutdown - all corresponding
 	//jobs are also shutdown accordingly.
 
+	var shutdown poolShutdown
+
 	// shutdown the send command pool in a separate go routine
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		m.startPlugin.ShutdownAndWait(waitTimeout)
+		shutdown.wait(m.context.Log(), startPluginPoolName, m.startPlugin, waitTimeout)
 	}()
 
 	// shutdown the cancel command pool in a separate go routine
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		m.stopPlugin.ShutdownAndWait(waitTimeout)
+		shutdown.wait(m.context.Log(), stopPluginPoolName, m.stopPlugin, waitTimeout)
 	}()
 
 	if len(m.runningPlugins) > 0 {
-		m.stopLongRunningPlugins(stopType)
+		m.stopLongRunningPlugins(stopType, waitTimeout)
 	}
 
-	// wait for everything to shutdown
-	wg.Wait()
-	return nil
+	// wait for everything to shutdown, unless the context is done first
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return shutdown.err()
+	case <-ctx.Done():
+		// the pools may have drained just as the context expired
+		select {
+		case <-drained:
+			return shutdown.err()
+		default:
+			return &StopInterruptedError{Err: ctx.Err()}
+		}
+	}
 }
 
-// stopLongRunningPlugins requests the long running plugins to stop
-func (m *Manager) stopLongRunningPlugins(stopType contracts.StopType) {
+// stopLongRunningPlugins requests the long running plugins to stop, pre-stop hooks count against the given budget
+func (m *Manager) stopLongRunningPlugins(stopType contracts.StopType, budget time.Duration) {
 	log := m.context.Log()
 	log.Infof("long running manager stop requested. Stop type: %v", stopType)
 
 	var wg sync.WaitGroup
-	i := 0
 	for pluginName := range m.runningPlugins {
-		go func(wgc *sync.WaitGroup, i int) {
+		if stopType == contracts.StopTypeSoftStop {
+			wg.Add(1)
+		}
+		go func(wgc *sync.WaitGroup, pluginName string) {
 			if stopType == contracts.StopTypeSoftStop {
-				wgc.Add(1)
 				defer wgc.Done()
 			}
 
 			plugin := m.registeredPlugins[pluginName]
-			if err := plugin.Handler.Stop(m.context, task.NewChanneledCancelFlag()); err != nil {
+			if err := m.stopPluginHandler(plugin, task.NewChanneledCancelFlag(), budget); err != nil {
 				log.Errorf("Plugin (%v) failed to stop with error: %v",
 					pluginName,
 					err)
 			}
 
-		}(&wg, i)
-		i++
+		}(&wg, pluginName)
 	}
+	//during a soft stop plugins get the chance to finish their pre-stop hooks and stop gracefully
+	wg.Wait()
 }
 
 // EnsurePluginRegistered adds a long-running plugin if it is not already in the registry
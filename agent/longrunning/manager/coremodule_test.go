@@ -15,16 +15,26 @@
 package manager
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -418,3 +428,253 @@ func (m *MockedEc2ConfigXmlParser) IsCloudWatchEnabled() (bool, error) {
 	args := m.Called()
 	return args.Bool(0), args.Error(1)
 }
+
+func TestGetRunningPluginsReturnsCopy(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", Configuration: "config", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.ensurePluginsAreRunning()
+	}()
+	runningPlugins := m.GetRunningPlugins()
+	<-done
+
+	assert.Equal(t, m.runningPlugins, runningPlugins)
+
+	// changing the copy doesn't change the manager
+	info := runningPlugins["plugin"]
+	info.Configuration = "changed"
+	runningPlugins["plugin"] = info
+	delete(runningPlugins, "plugin")
+	runningPlugins["other"] = managerContracts.PluginInfo{Name: "other"}
+
+	assert.Len(t, m.runningPlugins, 1)
+	assert.Equal(t, "config", m.runningPlugins["plugin"].Configuration)
+}
+
+// resetSingleton clears the manager singleton and stubs the persisted manager settings,
+// the returned function restores both
+func resetSingleton() func() {
+	originalInstance, originalInitErr, originalDataStore := singletonInstance, initErr, dataStore
+	originalReadManagerConfig := readManagerConfig
+	singletonInstance, initErr = nil, nil
+	readManagerConfig = func() (ManagerConfig, bool, error) {
+		return ManagerConfig{}, false, nil
+	}
+	return func() {
+		singletonInstance, initErr, dataStore = originalInstance, originalInitErr, originalDataStore
+		readManagerConfig = originalReadManagerConfig
+	}
+}
+
+// newMockContextWithDefaultConfig returns a mocked context with the default agent configuration, like the one
+// the manager gets initialized with once the agent configuration got validated
+func newMockContextWithDefaultConfig() context.T {
+	ctx := new(context.Mock)
+	ctx.On("Log").Return(log.NewMockLog())
+	ctx.On("AppConfig").Return(appconfig.DefaultConfig())
+	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+	ctx.On("CurrentContext").Return([]string{})
+	ctx.On("AppConstants").Return(&appconfig.AppConstants{
+		MinHealthFrequencyMinutes: appconfig.DefaultSsmHealthFrequencyMinutesMin,
+		MaxHealthFrequencyMinutes: appconfig.DefaultSsmHealthFrequencyMinutesMax,
+	})
+	return ctx
+}
+
+func TestGetInstanceBeforeInitialization(t *testing.T) {
+	defer resetSingleton()()
+
+	instance, err := GetInstance()
+
+	assert.Nil(t, instance)
+	assert.Equal(t, ErrNotInitialized, err)
+}
+
+func TestEnsureInitializationCanBeRetriedAfterFailure(t *testing.T) {
+	defer resetSingleton()()
+	originalMarshal := marshalRegisteredPlugins
+	defer func() { marshalRegisteredPlugins = originalMarshal }()
+	marshalRegisteredPlugins = func(v interface{}) ([]byte, error) {
+		return nil, errors.New("unsupported value")
+	}
+
+	assert.Error(t, EnsureInitialization(newMockContextWithDefaultConfig(), nil))
+	instance, err := GetInstance()
+	assert.Nil(t, instance)
+	_, isInitializationError := err.(*InitializationError)
+	assert.True(t, isInitializationError)
+
+	marshalRegisteredPlugins = originalMarshal
+	assert.NoError(t, EnsureInitialization(newMockContextWithDefaultConfig(), nil))
+	instance, err = GetInstance()
+	assert.NoError(t, err)
+	assert.NotNil(t, instance)
+}
+
+func TestEnsureInitializationFailsWithoutPoolWorkers(t *testing.T) {
+	defer resetSingleton()()
+	originalNewTaskPool := newTaskPool
+	defer func() { newTaskPool = originalNewTaskPool }()
+	var workers []int
+	newTaskPool = func(log log.T, n int, cancelWaitDuration time.Duration, clock times.Clock) (task.Pool, error) {
+		workers = append(workers, n)
+		return originalNewTaskPool(log, 0, cancelWaitDuration, clock)
+	}
+
+	assert.Error(t, EnsureInitialization(newMockContextWithDefaultConfig(), nil))
+	assert.Len(t, workers, 1)
+	_, err := GetInstance()
+	assert.IsType(t, &InitializationError{}, err)
+}
+
+func TestGetInstanceDoesNotWaitForPluginsLock(t *testing.T) {
+	defer resetSingleton()()
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	singletonInstance = m
+
+	// a long operation on the plugins doesn't block callers of GetInstance
+	lock.Lock()
+	defer lock.Unlock()
+	got := make(chan *Manager)
+	go func() {
+		instance, _ := GetInstance()
+		got <- instance
+	}()
+	select {
+	case instance := <-got:
+		assert.Equal(t, m, instance)
+	case <-time.After(time.Second):
+		assert.Fail(t, "GetInstance waited for the lock of the running plugins")
+	}
+}
+
+// stoppedPlugin is a long running plugin that is never running, it's safe for concurrent use
+type stoppedPlugin struct{}
+
+func (stoppedPlugin) IsRunning(context context.T) bool {
+	return false
+}
+
+func (stoppedPlugin) Start(context context.T, configuration string, orchestrationDir string, cancelFlag task.CancelFlag, out iohandler.IOHandler) error {
+	return nil
+}
+
+func (stoppedPlugin) Stop(context context.T, cancelFlag task.CancelFlag) error {
+	return nil
+}
+
+func TestConcurrentStartStopAndGetInstance(t *testing.T) {
+	defer resetSingleton()()
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: "plugin"}, Handler: stoppedPlugin{}}
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	singletonInstance = m
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(true)
+	m.startPlugin = pool
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			m.startPluginOnce(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag())
+		}()
+		go func() {
+			defer wg.Done()
+			m.ensurePluginsAreRunning()
+			m.StopPlugin("unknown", task.NewChanneledCancelFlag())
+		}()
+		go func() {
+			defer wg.Done()
+			instance, err := GetInstance()
+			assert.NoError(t, err)
+			assert.Equal(t, m, instance)
+		}()
+	}
+	wg.Wait()
+}
+
+type mockedPlugin struct {
+	mock.Mock
+}
+
+func (m *mockedPlugin) IsRunning(context context.T) bool {
+	args := m.Called(context)
+	return args.Bool(0)
+}
+
+func (m *mockedPlugin) Start(context context.T, configuration string, orchestrationDir string, cancelFlag task.CancelFlag, out iohandler.IOHandler) error {
+	args := m.Called(context, configuration, orchestrationDir, cancelFlag, out)
+	return args.Error(0)
+}
+
+func (m *mockedPlugin) Stop(context context.T, cancelFlag task.CancelFlag) error {
+	args := m.Called(context, cancelFlag)
+	return args.Error(0)
+}
+
+type mockedDataStore struct {
+	mock.Mock
+}
+
+func (m *mockedDataStore) Write(data map[string]managerContracts.PluginInfo) error {
+	args := m.Called(data)
+	return args.Error(0)
+}
+
+func (m *mockedDataStore) Read() (map[string]managerContracts.PluginInfo, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]managerContracts.PluginInfo), args.Error(1)
+}
+
+// setupTestManager returns a manager with the given long running plugins registered, and swaps the data store,
+// the plugin IO handler and the creation of plugin orchestration directories for test doubles. The returned function restores the swapped dependencies.
+func setupTestManager(plugins map[string]*mockedPlugin) (*Manager, *mockedDataStore, func()) {
+	registered := map[string]managerContracts.Plugin{}
+	for name, handler := range plugins {
+		registered[name] = managerContracts.Plugin{
+			Info:    managerContracts.PluginInfo{Name: name},
+			Handler: handler,
+		}
+	}
+	m := &Manager{
+		context:           context.NewMockDefault(),
+		runningPlugins:    map[string]managerContracts.PluginInfo{},
+		registeredPlugins: registered,
+		clock:             times.DefaultClock,
+		config:            DefaultManagerConfig(),
+	}
+
+	ds := &mockedDataStore{}
+	originalDataStore := dataStore
+	originalIOHandler := newPluginIOHandler
+	originalDiskSpaceInfo := getDiskSpaceInfo
+	originalMakePluginDirs := makePluginDirs
+	dataStore = ds
+	makePluginDirs = func(dir string) error {
+		return nil
+	}
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: 10 * DefaultMinFreeDiskBytes}, nil
+	}
+	newPluginIOHandler = func(log log.T, ioConfig contracts.IOConfiguration, pluginName string) iohandler.IOHandler {
+		return iohandler.NewDefaultIOHandler(log, ioConfig)
+	}
+	platform.SetInstanceID(instanceId)
+
+	return m, ds, func() {
+		dataStore = originalDataStore
+		newPluginIOHandler = originalIOHandler
+		getDiskSpaceInfo = originalDiskSpaceInfo
+		makePluginDirs = originalMakePluginDirs
+	}
+}
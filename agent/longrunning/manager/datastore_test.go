@@ -0,0 +1,138 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/longrunning/datastore"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestModuleExecuteBootsWithEmptyStateOnOversizedDataStore(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	var noPlugins map[string]managerContracts.PluginInfo
+	ds.On("Read").Return(noPlugins, &datastore.SizeLimitError{
+		FileName:       "plugins.json",
+		BackupFileName: "plugins.json.oversized-1",
+		Size:           1 << 30,
+		MaxSize:        DefaultMaxDataStoreBytes,
+	})
+	logger := m.context.Log().(*log.Mock)
+	logger.On("Criticalf", mock.Anything, mock.Anything).Return(nil)
+
+	err := m.ModuleExecute(m.context)
+	defer m.stopLifeCycleManagementJob()
+
+	assert.NoError(t, err)
+	assert.Empty(t, m.runningPlugins)
+	ok, detail := m.Healthz()
+	assert.True(t, ok, detail)
+	logger.AssertCalled(t, "Criticalf", mock.Anything, mock.Anything)
+}
+
+func TestModuleExecutePersistsRemovalOfUnregisteredPlugins(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Read").Return(map[string]managerContracts.PluginInfo{
+		"plugin":       {Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}},
+		"unregistered": {Name: "unregistered", State: managerContracts.PluginState{IsEnabled: true}},
+	}, nil)
+	ds.On("Write", mock.Anything).Return(nil)
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	assert.NoError(t, m.ModuleExecute(m.context))
+	m.stopLifeCycleManagementJob()
+
+	ds.AssertNumberOfCalls(t, "Write", 1)
+	lock.RLock()
+	assert.NotContains(t, m.runningPlugins, "unregistered")
+	lock.RUnlock()
+
+	// health checks that don't change anything don't persist
+	m.ensurePluginsAreRunning()
+	ds.AssertNumberOfCalls(t, "Write", 1)
+}
+
+func TestStateTransitionsPersistOnce(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	m.setRunning(true)
+	m.stopPlugin = newRunningPool()
+	startPool := newRunningPool()
+	startPool.On("HasJob", mock.Anything).Return(false)
+	m.startPlugin = startPool
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	handler.On("Stop", mock.Anything, mock.Anything).Return(nil)
+	isRunning := handler.On("IsRunning", mock.Anything).Return(false)
+
+	snapshot, err := m.SnapshotState()
+	assert.NoError(t, err)
+	ds.AssertNumberOfCalls(t, "Write", 0)
+
+	assert.NoError(t, m.RestoreState([]byte(`{"Version":1,"Plugins":[{"Name":"plugin","Configuration":"","IsEnabled":false}]}`), false))
+	ds.AssertNumberOfCalls(t, "Write", 1)
+
+	assert.NoError(t, m.RestoreState(snapshot, false))
+	ds.AssertNumberOfCalls(t, "Write", 2)
+
+	// start
+	assert.NoError(t, m.StartPlugin("plugin", "config", "orchestration", task.NewChanneledCancelFlag(), nil))
+	ds.AssertNumberOfCalls(t, "Write", 3)
+
+	// revive by the health check
+	m.ensurePluginsAreRunning()
+	ds.AssertNumberOfCalls(t, "Write", 4)
+
+	// restart
+	isRunning.Return(false)
+	assert.NoError(t, m.Restart("plugin"))
+	ds.AssertNumberOfCalls(t, "Write", 5)
+
+	// health checks that don't change anything don't persist
+	isRunning.Return(true)
+	m.ensurePluginsAreRunning()
+	ds.AssertNumberOfCalls(t, "Write", 5)
+
+	// stop
+	assert.NoError(t, m.StopPlugin("plugin", task.NewChanneledCancelFlag()))
+	ds.AssertNumberOfCalls(t, "Write", 6)
+}
+
+func TestFailingWriteDoesNotStopManager(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	ds.On("Read").Return(map[string]managerContracts.PluginInfo{
+		"unregistered": {Name: "unregistered"},
+	}, nil)
+	ds.On("Write", mock.Anything).Return(errors.New("disk failure"))
+
+	assert.NoError(t, m.ModuleExecute(m.context))
+	m.stopLifeCycleManagementJob()
+
+	ds.AssertNumberOfCalls(t, "Write", 1)
+	ok, _ := m.Healthz()
+	assert.False(t, ok)
+}
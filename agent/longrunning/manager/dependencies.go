@@ -15,13 +15,23 @@
 package manager
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/datastore"
 	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 )
 
 // dataStoreT defines the operations that manager uses to interact with its data-store
@@ -54,7 +64,7 @@ func (d ds) Read() (map[string]plugin.PluginInfo, error) {
 }
 
 var dataStore dataStoreT = ds{
-	dsImpl: datastore.FsStore{},
+	dsImpl: datastore.FsStore{MaxSize: DefaultMaxDataStoreBytes},
 }
 
 // getDataStoreLocation returns the absolute path where long running plugins data-store is saved.
@@ -81,3 +91,83 @@ func isPlatformSupported(log log.T, pluginName string) bool {
 	isSupported, _ := plugin.IsLongRunningPluginSupportedForCurrentPlatform(log, pluginName)
 	return isSupported
 }
+
+// newPluginIOHandler creates the IO handler used when the manager starts a long running plugin on its own.
+// Assign method to global variable to allow unittest to override
+var newPluginIOHandler = func(log log.T, ioConfig contracts.IOConfiguration, pluginName string) iohandler.IOHandler {
+	out := iohandler.NewDefaultIOHandler(log, ioConfig)
+	out.Init(log, pluginName)
+	return out
+}
+
+// getDiskSpaceInfo returns the disk space available to the manager.
+// Assign method to global variable to allow unittest to override
+var getDiskSpaceInfo = fileutil.GetDiskSpaceInfo
+
+// makePluginDirs creates the orchestration directory of a long running plugin.
+// Assign method to global variable to allow unittest to override
+var makePluginDirs = fileutil.MakeDirs
+
+// marshalRegisteredPlugins marshals the registered plugins to log them.
+// Assign method to global variable to allow unittest to override
+var marshalRegisteredPlugins = json.Marshal
+
+// taskPoolQueueSize is the number of jobs waiting for a worker of a task pool, further jobs are rejected
+// so that plugins that keep failing can't queue up restarts without limit
+const taskPoolQueueSize = 100
+
+// newTaskPool creates a task pool running jobs with the given number of workers.
+// Assign method to global variable to allow unittest to override
+var newTaskPool = func(log log.T, workers int, cancelWaitDuration time.Duration, clock times.Clock) (task.Pool, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("invalid number of workers %v", workers)
+	}
+	return task.NewBoundedPool(log, workers, taskPoolQueueSize, cancelWaitDuration, clock), nil
+}
+
+// writeSystemConsole writes a line to the system console of the instance.
+// Assign method to global variable to allow unittest to override
+var writeSystemConsole = writeToSystemConsole
+
+// managerConfigFileName is the name of the file persisting the settings of the manager changed at runtime
+const managerConfigFileName = "managerconfig"
+
+// getManagerConfigLocation returns the absolute path where the settings of the manager changed at runtime are saved
+func getManagerConfigLocation() (location, fileName string, err error) {
+	if location, _, err = getDataStoreLocation(); err != nil {
+		return
+	}
+	fileName = filepath.Join(location, managerConfigFileName)
+	return
+}
+
+// writeManagerConfig persists the settings of the manager.
+// Assign method to global variable to allow unittest to override
+var writeManagerConfig = func(config ManagerConfig) (err error) {
+	var location, fileName, s string
+	if location, fileName, err = getManagerConfigLocation(); err != nil {
+		return
+	}
+	if err = fileutil.MakeDirs(location); err != nil {
+		return
+	}
+	if s, err = jsonutil.Marshal(config); err != nil {
+		return
+	}
+	_, err = fileutil.WriteIntoFileWithPermissions(fileName, s, os.FileMode(int(appconfig.ReadWriteAccess)))
+	return
+}
+
+// readManagerConfig reads the persisted settings of the manager, found is false if none were persisted.
+// Assign method to global variable to allow unittest to override
+var readManagerConfig = func() (config ManagerConfig, found bool, err error) {
+	var fileName string
+	if _, fileName, err = getManagerConfigLocation(); err != nil {
+		return
+	}
+	if !fileutil.Exists(fileName) {
+		return
+	}
+	err = jsonutil.UnmarshalFile(fileName, &config)
+	return config, err == nil, err
+}
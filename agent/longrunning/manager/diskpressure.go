@@ -0,0 +1,67 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+)
+
+// checkDiskPressure compares the available disk space against the configured threshold and switches the manager
+// in and out of read-only mode accordingly. In read-only mode long running plugins keep running, but the manager
+// skips persisting them until enough disk space is available again. Returns whether the manager is read-only.
+func (m *Manager) checkDiskPressure() (readOnly bool) {
+	log := m.context.Log()
+	minFreeDiskBytes := m.GetConfig().MinFreeDiskBytes
+	if minFreeDiskBytes <= 0 {
+		return false
+	}
+
+	info, err := getDiskSpaceInfo()
+
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if err != nil {
+		log.Debugf("Unable to get disk space info, keeping the current mode - %v", err)
+		return m.readOnly
+	}
+
+	m.availableDiskBytes = info.AvailBytes
+	wasReadOnly := m.readOnly
+	m.readOnly = info.AvailBytes < minFreeDiskBytes
+	if m.readOnly && !wasReadOnly {
+		m.emit(EventDegraded, "", fmt.Sprintf("only %v bytes of disk space are available - manager is read-only", info.AvailBytes))
+		log.Warnf("Only %v bytes of disk space are available (threshold %v) - long running plugin manager is read-only, plugins are kept running but not persisted",
+			info.AvailBytes, minFreeDiskBytes)
+	} else if !m.readOnly && wasReadOnly {
+		log.Infof("%v bytes of disk space are available again - long running plugin manager resumes persisting plugins", info.AvailBytes)
+	}
+	return m.readOnly
+}
+
+// flushPendingPersistence persists the long running plugins if persisting them was skipped while the manager was
+// read-only and enough disk space is available again. Callers are expected to hold lock.
+func (m *Manager) flushPendingPersistence() {
+	m.statusLock.RLock()
+	pending := m.persistencePending
+	m.statusLock.RUnlock()
+
+	if !pending || m.checkDiskPressure() {
+		return
+	}
+	m.context.Log().Infof("Persisting long running plugins skipped while the manager was read-only")
+	if err := m.writeDataStore(); err != nil {
+		m.context.Log().Errorf("Failed to persist long running plugins - because of %s", err)
+	}
+}
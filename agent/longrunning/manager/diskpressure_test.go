@@ -0,0 +1,75 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDiskPressureKeepsPluginsRunningAndResumesPersistence(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(true)
+	pool := &task.MockedPool{}
+	m.startPlugin = pool
+
+	available := m.config.MinFreeDiskBytes - 1
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: available}, nil
+	}
+
+	// disk is full - persistence is skipped, plugins keep running
+	assert.NoError(t, m.writeDataStore())
+	m.ensurePluginsAreRunning()
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+	handler.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything)
+	pool.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+	stats := m.Stats()
+	assert.True(t, stats.ReadOnly)
+	assert.Equal(t, available, stats.AvailableDiskBytes)
+
+	// space was freed - the next health check persists the skipped changes
+	available = m.config.MinFreeDiskBytes * 2
+	ds.On("Write", m.runningPlugins).Return(nil).Once()
+	m.ensurePluginsAreRunning()
+	ds.AssertExpectations(t)
+	assert.False(t, m.Stats().ReadOnly)
+
+	// nothing is pending anymore
+	m.ensurePluginsAreRunning()
+	ds.AssertNumberOfCalls(t, "Write", 1)
+}
+
+func TestDiskPressureCheckDisabled(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.config.MinFreeDiskBytes = 0
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: 0}, nil
+	}
+	ds.On("Write", mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, m.writeDataStore())
+	ds.AssertExpectations(t)
+	assert.False(t, m.Stats().ReadOnly)
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"time"
+)
+
+// LifecycleEventType is the type of a lifecycle event of the long running plugin manager
+type LifecycleEventType string
+
+const (
+	// EventStarted is emitted when a long running plugin got started
+	EventStarted LifecycleEventType = "Started"
+
+	// EventStopped is emitted when a long running plugin got stopped
+	EventStopped LifecycleEventType = "Stopped"
+
+	// EventRestarted is emitted when a health check restarted a long running plugin that wasn't running
+	EventRestarted LifecycleEventType = "Restarted"
+
+	// EventReconfigured is emitted when a running long running plugin applied a new configuration in place
+	EventReconfigured LifecycleEventType = "Reconfigured"
+
+	// EventDegraded is emitted when the manager can't persist long running plugins anymore
+	EventDegraded LifecycleEventType = "Degraded"
+
+	// EventQuarantined is emitted when a long running plugin got quarantined
+	EventQuarantined LifecycleEventType = "Quarantined"
+
+	// EventUnhealthy is emitted when a running long running plugin failed its health check
+	EventUnhealthy LifecycleEventType = "Unhealthy"
+
+	// EventCrashLooping is emitted when a long running plugin keeps getting restarted without running stable in between
+	EventCrashLooping LifecycleEventType = "CrashLooping"
+)
+
+// crashLoopThreshold is the number of restarts without running stable in between after which a plugin is crash looping
+const crashLoopThreshold = 3
+
+// subscriberBufferSize is the number of events buffered for each subscriber, older events are dropped once it's full
+const subscriberBufferSize = 64
+
+// LifecycleEvent is a transition of the long running plugin manager or one of its plugins
+type LifecycleEvent struct {
+	Type LifecycleEventType
+	// Plugin is the name of the long running plugin, empty for events of the manager itself
+	Plugin string
+	Time   time.Time
+	Detail string
+}
+
+// Subscribe returns a channel receiving the lifecycle events of the manager and a function to unsubscribe.
+// Events are buffered - if a subscriber doesn't keep up the oldest buffered events are dropped so that the manager
+// never blocks on a subscriber. The channel is closed on unsubscribe.
+func (m *Manager) Subscribe() (<-chan LifecycleEvent, func()) {
+	events := make(chan LifecycleEvent, subscriberBufferSize)
+
+	m.subscribersLock.Lock()
+	defer m.subscribersLock.Unlock()
+	if m.subscribers == nil {
+		m.subscribers = map[chan LifecycleEvent]struct{}{}
+	}
+	m.subscribers[events] = struct{}{}
+
+	return events, func() {
+		m.subscribersLock.Lock()
+		defer m.subscribersLock.Unlock()
+		if _, isSubscribed := m.subscribers[events]; isSubscribed {
+			delete(m.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// emit publishes a lifecycle event to all subscribers without blocking
+func (m *Manager) emit(eventType LifecycleEventType, plugin, detail string) {
+	event := LifecycleEvent{
+		Type:   eventType,
+		Plugin: plugin,
+		Time:   m.clock.Now(),
+		Detail: detail,
+	}
+
+	if m.GetConfig().MirrorCriticalEventsToConsole && isCriticalEvent(eventType) {
+		m.mirrorToConsole(event)
+	}
+
+	m.subscribersLock.Lock()
+	defer m.subscribersLock.Unlock()
+	for events := range m.subscribers {
+		select {
+		case events <- event:
+			continue
+		default:
+		}
+		//buffer is full - drop the oldest event to make room
+		select {
+		case <-events:
+		default:
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
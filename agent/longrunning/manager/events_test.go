@@ -0,0 +1,71 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubscribeReceivesEventsUntilUnsubscribed(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	events, unsubscribe := m.Subscribe()
+	m.setQuarantine("plugin", "tampered executable")
+	ds.On("Write", mock.Anything).Return(fmt.Errorf("disk error")).Once()
+	m.writeDataStore()
+
+	event := <-events
+	assert.Equal(t, EventQuarantined, event.Type)
+	assert.Equal(t, "plugin", event.Plugin)
+	assert.Equal(t, "tampered executable", event.Detail)
+	event = <-events
+	assert.Equal(t, EventDegraded, event.Type)
+	assert.Empty(t, event.Plugin)
+
+	unsubscribe()
+	_, open := <-events
+	assert.False(t, open)
+
+	// unsubscribing twice and emitting without subscribers is safe
+	unsubscribe()
+	m.emit(EventStarted, "plugin", "")
+}
+
+func TestSubscribeDropsOldestEventsOfSlowConsumers(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	slow, unsubscribeSlow := m.Subscribe()
+	defer unsubscribeSlow()
+
+	total := subscriberBufferSize + 10
+	for i := 0; i < total; i++ {
+		m.emit(EventRestarted, fmt.Sprintf("plugin%v", i), "")
+	}
+
+	assert.Len(t, slow, subscriberBufferSize)
+	first := <-slow
+	assert.Equal(t, fmt.Sprintf("plugin%v", total-subscriberBufferSize), first.Plugin)
+	var last LifecycleEvent
+	for len(slow) > 0 {
+		last = <-slow
+	}
+	assert.Equal(t, fmt.Sprintf("plugin%v", total-1), last.Plugin)
+}
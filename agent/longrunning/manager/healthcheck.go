@@ -0,0 +1,28 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// checkHealth runs the health check of a running long running plugin.
+// Plugins that don't implement managerContracts.HealthChecker are always healthy.
+func (m *Manager) checkHealth(p managerContracts.Plugin) error {
+	if checker, ok := p.Handler.(managerContracts.HealthChecker); ok {
+		return checker.HealthCheck(m.context)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedHealthCheckPlugin is a long running plugin implementing managerContracts.HealthChecker
+type mockedHealthCheckPlugin struct {
+	mockedPlugin
+}
+
+func (m *mockedHealthCheckPlugin) HealthCheck(context context.T) error {
+	args := m.Called(context)
+	return args.Error(0)
+}
+
+// setupHealthCheckManager returns a manager with the given plugin enabled and a start pool running submitted jobs
+func setupHealthCheckManager(handler managerContracts.LongRunningPlugin) (*Manager, func()) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	ds.On("Write", mock.Anything).Return(nil)
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "plugin"},
+		Handler: handler,
+	}
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	pool := newRunningPool()
+	pool.On("HasJob", mock.Anything).Return(false)
+	m.startPlugin = pool
+	return m, restore
+}
+
+func TestUnhealthyRunningPluginIsRestarted(t *testing.T) {
+	handler := &mockedHealthCheckPlugin{}
+	m, restore := setupHealthCheckManager(handler)
+	defer restore()
+	events, unsubscribe := m.Subscribe()
+	defer unsubscribe()
+
+	var calls []string
+	handler.On("IsRunning", mock.Anything).Return(true)
+	handler.On("HealthCheck", mock.Anything).Return(errors.New("no metrics shipped"))
+	handler.On("Stop", mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "Stop") })
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "Start") })
+
+	m.ensurePluginsAreRunning()
+
+	assert.Equal(t, []string{"Stop", "Start"}, calls)
+	event := <-events
+	assert.Equal(t, EventUnhealthy, event.Type)
+	assert.Equal(t, "no metrics shipped", event.Detail)
+}
+
+func TestHealthyRunningPluginIsLeftAlone(t *testing.T) {
+	handler := &mockedHealthCheckPlugin{}
+	m, restore := setupHealthCheckManager(handler)
+	defer restore()
+
+	handler.On("IsRunning", mock.Anything).Return(true)
+	handler.On("HealthCheck", mock.Anything).Return(nil)
+
+	m.ensurePluginsAreRunning()
+
+	handler.AssertCalled(t, "HealthCheck", mock.Anything)
+	handler.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything)
+	m.startPlugin.(*task.MockedPool).AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUnhealthyPluginIsNotStartedIfStopFails(t *testing.T) {
+	handler := &mockedHealthCheckPlugin{}
+	m, restore := setupHealthCheckManager(handler)
+	defer restore()
+
+	handler.On("IsRunning", mock.Anything).Return(true)
+	handler.On("HealthCheck", mock.Anything).Return(errors.New("stuck"))
+	handler.On("Stop", mock.Anything, mock.Anything).Return(errors.New("unable to stop"))
+
+	m.ensurePluginsAreRunning()
+
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPluginsWithoutHealthCheckAreHealthy(t *testing.T) {
+	m, restore := setupHealthCheckManager(&mockedPlugin{})
+	defer restore()
+
+	assert.NoError(t, m.checkHealth(m.registeredPlugins["plugin"]))
+}
+
+func TestHealthCheckPrunesUnregisteredPlugins(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	//entries read from the data store of plugins an upgrade removed
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+	m.runningPlugins["removed"] = managerContracts.PluginInfo{Name: "removed", Configuration: "config"}
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	m.ensurePluginsAreRunning()
+
+	assert.NotContains(t, m.runningPlugins, "removed")
+	assert.Contains(t, m.runningPlugins, "plugin")
+	ds.AssertNumberOfCalls(t, "Write", 1)
+
+	// nothing left to prune
+	m.ensurePluginsAreRunning()
+	ds.AssertNumberOfCalls(t, "Write", 1)
+}
+
+func TestHealthCheckSkipsPluginsWithoutHandler(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: "plugin"}}
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+
+	assert.NotPanics(t, m.ensurePluginsAreRunning)
+	assert.Contains(t, m.runningPlugins, "plugin")
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+}
@@ -0,0 +1,176 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// HealthPath is the path the health report of long running plugins is served on
+const HealthPath = "/lrpm/health"
+
+// PluginHealth is the health of a single registered long running plugin
+type PluginHealth struct {
+	Name string
+
+	// Configured is true if the plugin is enabled by documents and managed by the manager
+	Configured bool
+
+	// IsRunning is the latest IsRunning result of the plugin, nil if it wasn't checked yet
+	IsRunning *bool
+
+	// LastRestart is the time of the latest restart of the plugin by the health check, nil if it never got restarted
+	LastRestart *time.Time
+
+	// LastPublished is the time of the latest successful publish of plugins publishing data,
+	// nil if the plugin doesn't report publishes or didn't publish yet
+	LastPublished *time.Time
+}
+
+// PoolHealth is the load of a task pool the manager starts or stops long running plugins with
+type PoolHealth struct {
+	QueueDepth    int
+	ActiveWorkers int
+	CompletedJobs uint64
+}
+
+// HealthReport is the health of the long running plugin manager, its task pools and its registered plugins
+type HealthReport struct {
+	Running bool
+
+	// StartPool and StopPool are nil if the manager didn't create its pools yet
+	StartPool *PoolHealth
+	StopPool  *PoolHealth
+
+	Plugins []PluginHealth
+}
+
+// poolHealth returns the load of the given task pool, nil if there's no pool
+func poolHealth(pool task.Pool) *PoolHealth {
+	if pool == nil {
+		return nil
+	}
+	return &PoolHealth{
+		QueueDepth:    pool.QueueDepth(),
+		ActiveWorkers: pool.ActiveWorkers(),
+		CompletedJobs: pool.CompletedJobs(),
+	}
+}
+
+// HealthReport returns a JSON snapshot of the health of the registered long running plugins.
+// It only reads state the manager already tracks, it's safe to call concurrently with the lifecycle job.
+func (m *Manager) HealthReport() ([]byte, error) {
+	lock.RLock()
+	report := HealthReport{
+		StartPool: poolHealth(m.startPlugin),
+		StopPool:  poolHealth(m.stopPlugin),
+		Plugins:   []PluginHealth{},
+	}
+	for name, p := range m.registeredPlugins {
+		_, configured := m.runningPlugins[name]
+		health := PluginHealth{Name: name, Configured: configured}
+		if reporter, ok := p.Handler.(managerContracts.PublishReporter); ok {
+			if lastPublished := reporter.LastPublished(); !lastPublished.IsZero() {
+				health.LastPublished = &lastPublished
+			}
+		}
+		report.Plugins = append(report.Plugins, health)
+	}
+	lock.RUnlock()
+
+	m.statusLock.RLock()
+	report.Running = m.running
+	for i := range report.Plugins {
+		p := &report.Plugins[i]
+		if isRunning, checked := m.lastIsRunning[p.Name]; checked {
+			p.IsRunning = &isRunning
+		}
+		if restartedAt, restarted := m.lastRestart[p.Name]; restarted {
+			p.LastRestart = &restartedAt
+		}
+	}
+	m.statusLock.RUnlock()
+
+	sort.Slice(report.Plugins, func(i, j int) bool {
+		return report.Plugins[i].Name < report.Plugins[j].Name
+	})
+	return json.Marshal(report)
+}
+
+// HealthHandler returns a read-only HTTP handler serving the HealthReport
+func (m *Manager) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		report, err := m.HealthReport()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(report)
+	})
+}
+
+// ServeHealth serves the HealthReport on HealthPath of the given localhost port until the manager stops
+func (m *Manager) ServeHealth(port int) {
+	log := m.context.Log()
+	mux := http.NewServeMux()
+	mux.Handle(HealthPath, m.HealthHandler())
+	server := &http.Server{Addr: fmt.Sprintf("localhost:%v", port), Handler: mux}
+
+	m.statusLock.Lock()
+	m.healthServer = server
+	m.statusLock.Unlock()
+
+	go func() {
+		log.Infof("Serving the health of long running plugins on %s%s", server.Addr, HealthPath)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Unable to serve the health of long running plugins - %v", err)
+		}
+	}()
+}
+
+// stopHealthServer stops serving the HealthReport if ServeHealth was called
+func (m *Manager) stopHealthServer() {
+	m.statusLock.Lock()
+	server := m.healthServer
+	m.healthServer = nil
+	m.statusLock.Unlock()
+
+	if server != nil {
+		server.Close()
+	}
+}
+
+// recordRestarted records the time a long running plugin got restarted by the health check
+func (m *Manager) recordRestarted(name string) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.lastRestart == nil {
+		m.lastRestart = map[string]time.Time{}
+	}
+	m.lastRestart[name] = m.clock.Now()
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthReport(t *testing.T) {
+	running := &mockedPlugin{}
+	stopped := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"running": running, "stopped": stopped, "unconfigured": {}})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	m.runningPlugins["running"] = managerContracts.PluginInfo{Name: "running", State: managerContracts.PluginState{IsEnabled: true}}
+	m.runningPlugins["stopped"] = managerContracts.PluginInfo{Name: "stopped", State: managerContracts.PluginState{IsEnabled: true}}
+	m.setRunning(true)
+	pool := newRunningPool()
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("QueueDepth").Return(2)
+	pool.On("ActiveWorkers").Return(1)
+	pool.On("CompletedJobs").Return(uint64(3))
+	m.startPlugin = pool
+	now := time.Now().Round(0)
+	m.clock = &steppedClock{now: now}
+
+	running.On("IsRunning", mock.Anything).Return(true)
+	stopped.On("IsRunning", mock.Anything).Return(false)
+	stopped.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.ensurePluginsAreRunning()
+
+	data, err := m.HealthReport()
+	assert.NoError(t, err)
+	var report HealthReport
+	assert.NoError(t, json.Unmarshal(data, &report))
+
+	isRunning, isNotRunning := true, false
+	assert.True(t, report.Running)
+	assert.Equal(t, &PoolHealth{QueueDepth: 2, ActiveWorkers: 1, CompletedJobs: 3}, report.StartPool)
+	assert.Nil(t, report.StopPool)
+	assert.Len(t, report.Plugins, 3)
+	assert.Equal(t, PluginHealth{Name: "running", Configured: true, IsRunning: &isRunning}, report.Plugins[0])
+	assert.Equal(t, "stopped", report.Plugins[1].Name)
+	assert.True(t, report.Plugins[1].Configured)
+	assert.Equal(t, &isNotRunning, report.Plugins[1].IsRunning)
+	if assert.NotNil(t, report.Plugins[1].LastRestart) {
+		assert.True(t, now.Equal(*report.Plugins[1].LastRestart))
+	}
+	assert.Equal(t, PluginHealth{Name: "unconfigured"}, report.Plugins[2])
+}
+
+// mockedPublishingPlugin is a mocked long running plugin reporting its latest publish
+type mockedPublishingPlugin struct {
+	*mockedPlugin
+	lastPublished time.Time
+}
+
+// LastPublished returns the stubbed time of the latest publish
+func (p *mockedPublishingPlugin) LastPublished() time.Time {
+	return p.lastPublished
+}
+
+func TestHealthReportIncludesLastPublished(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	publishedAt := time.Now().Round(0).UTC()
+	m.registeredPlugins["published"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "published"},
+		Handler: &mockedPublishingPlugin{mockedPlugin: &mockedPlugin{}, lastPublished: publishedAt},
+	}
+	m.registeredPlugins["silent"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "silent"},
+		Handler: &mockedPublishingPlugin{mockedPlugin: &mockedPlugin{}},
+	}
+
+	data, err := m.HealthReport()
+	assert.NoError(t, err)
+	var report HealthReport
+	assert.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Len(t, report.Plugins, 2)
+	if assert.NotNil(t, report.Plugins[0].LastPublished) {
+		assert.True(t, publishedAt.Equal(*report.Plugins[0].LastPublished))
+	}
+	assert.Nil(t, report.Plugins[1].LastPublished)
+}
+
+func TestHealthHandlerServesReport(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+
+	recorder := httptest.NewRecorder()
+	m.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	expected, _ := m.HealthReport()
+	assert.JSONEq(t, string(expected), recorder.Body.String())
+}
+
+func TestHealthHandlerIsReadOnly(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+
+	recorder := httptest.NewRecorder()
+	m.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, HealthPath, nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestHealthReportIsSafeDuringHealthCheck(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			m.ensurePluginsAreRunning()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		_, err := m.HealthReport()
+		assert.NoError(t, err)
+	}
+	<-done
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Healthz reports the readiness of the long running plugin manager, e.g. to back a container health check.
+// It only looks at state the manager already tracks - it doesn't call into the plugins.
+// When not ok, detail contains a concise reason.
+func (m *Manager) Healthz() (ok bool, detail string) {
+	m.statusLock.RLock()
+	running := m.running
+	persistenceDegraded := m.persistenceDegraded
+	lastIsRunning := make(map[string]bool, len(m.lastIsRunning))
+	for name, isRunning := range m.lastIsRunning {
+		lastIsRunning[name] = isRunning
+	}
+	m.statusLock.RUnlock()
+
+	if !running {
+		return false, "long running plugin manager isn't running"
+	}
+	if m.managingLifeCycleJob == nil {
+		return false, "lifecycle management job of long running plugins isn't scheduled"
+	}
+	if persistenceDegraded {
+		return false, "persisting long running plugins to the data store is failing"
+	}
+
+	lock.RLock()
+	var downPlugins []string
+	for name, info := range m.runningPlugins {
+		if isRunning, checked := lastIsRunning[name]; info.Critical && checked && !isRunning {
+			downPlugins = append(downPlugins, name)
+		}
+	}
+	lock.RUnlock()
+
+	if len(downPlugins) > 0 {
+		sort.Strings(downPlugins)
+		return false, fmt.Sprintf("critical long running plugins aren't running: %s", strings.Join(downPlugins, ", "))
+	}
+	return true, "ok"
+}
+
+// setRunning records whether the manager is running
+func (m *Manager) setRunning(running bool) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	m.running = running
+}
+
+// isExecuting returns whether the manager is running
+func (m *Manager) isExecuting() bool {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	return m.running
+}
+
+// recordIsRunning records the latest IsRunning result of a long running plugin
+func (m *Manager) recordIsRunning(name string, isRunning bool) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.lastIsRunning == nil {
+		m.lastIsRunning = map[string]bool{}
+	}
+	m.lastIsRunning[name] = isRunning
+
+	if isRunning && m.isStable(name) {
+		delete(m.restartAttempts, name)
+		delete(m.lastRestartAttempt, name)
+	}
+}
+
+// writeDataStore persists the running plugins in the data store and keeps track of whether persisting is failing.
+// While the manager is read-only because of disk pressure the write is skipped and deferred until disk space is available.
+// Callers are expected to hold lock.
+func (m *Manager) writeDataStore() error {
+	if m.checkDiskPressure() {
+		m.statusLock.Lock()
+		defer m.statusLock.Unlock()
+		m.persistencePending = true
+		m.context.Log().Debugf("Skipping persisting long running plugins - manager is read-only")
+		return nil
+	}
+
+	err := dataStore.Write(m.runningPlugins)
+
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if err != nil && !m.persistenceDegraded {
+		m.emit(EventDegraded, "", fmt.Sprintf("persisting long running plugins to the data store is failing: %v", err))
+	}
+	m.persistenceDegraded = err != nil
+	m.persistencePending = err != nil && m.persistencePending
+	return err
+}
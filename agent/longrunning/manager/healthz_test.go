@@ -0,0 +1,63 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthz(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"critical": {}, "optional": {}})
+	defer restore()
+
+	ok, detail := m.Healthz()
+	assert.False(t, ok)
+	assert.Equal(t, "long running plugin manager isn't running", detail)
+
+	m.setRunning(true)
+	ok, detail = m.Healthz()
+	assert.False(t, ok)
+	assert.Equal(t, "lifecycle management job of long running plugins isn't scheduled", detail)
+
+	m.managingLifeCycleJob = times.NewTicker(times.DefaultClock, time.Hour)
+	defer m.managingLifeCycleJob.Stop()
+	ds.On("Write", mock.Anything).Return(fmt.Errorf("disk full")).Once()
+	m.writeDataStore()
+	ok, detail = m.Healthz()
+	assert.False(t, ok)
+	assert.Equal(t, "persisting long running plugins to the data store is failing", detail)
+
+	ds.On("Write", mock.Anything).Return(nil).Once()
+	m.writeDataStore()
+	m.runningPlugins["critical"] = managerContracts.PluginInfo{Name: "critical", Critical: true}
+	m.runningPlugins["optional"] = managerContracts.PluginInfo{Name: "optional"}
+	m.recordIsRunning("critical", false)
+	m.recordIsRunning("optional", false)
+	ok, detail = m.Healthz()
+	assert.False(t, ok)
+	assert.Equal(t, "critical long running plugins aren't running: critical", detail)
+
+	m.recordIsRunning("critical", true)
+	ok, detail = m.Healthz()
+	assert.True(t, ok)
+	assert.Equal(t, "ok", detail)
+}
@@ -0,0 +1,194 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
+)
+
+const (
+	// includeDirective is the key of a configuration object that references shared configuration fragments
+	includeDirective = "$include"
+
+	// maxIncludeDepth is the maximum depth of nested includes in a long running plugin configuration
+	maxIncludeDepth = 10
+)
+
+// readConfigFragment reads a shared configuration fragment.
+// Assign method to global variable to allow unittest to override
+var readConfigFragment = fileutil.ReadAllText
+
+// expandPluginConfiguration resolves the includes of a long running plugin configuration. Relative includes
+// in the configuration itself are resolved against the plugins folder of the agent.
+func expandPluginConfiguration(configuration string) (string, error) {
+	return resolveConfigIncludes(configuration, appconfig.DefaultPluginPath)
+}
+
+// resolveConfigIncludes returns the configuration with every $include directive replaced by the content of the
+// referenced fragment files. The value of $include is either a path or a list of paths to json objects; relative
+// paths are resolved against the folder of the including file (baseDir for the configuration itself). Fragments are
+// merged in order and keys of the including object take precedence over keys of the fragments.
+// Configurations that aren't json or don't use $include are returned as is.
+func resolveConfigIncludes(configuration, baseDir string) (string, error) {
+	if !strings.Contains(configuration, includeDirective) {
+		return configuration, nil
+	}
+
+	var parsed interface{}
+	if err := unmarshalConfiguration([]byte(configuration), &parsed); err != nil {
+		return configuration, nil
+	}
+
+	expanded, err := expandIncludes(parsed, baseDir, []string{}, 0)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(expanded); err != nil {
+		return "", fmt.Errorf("unable to encode expanded configuration: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// expandIncludes expands the includes of the given json value. includeChain holds the fragment files currently being
+// expanded and is used to detect cycles.
+func expandIncludes(value interface{}, dir string, includeChain []string, depth int) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		if include, hasInclude := v[includeDirective]; hasInclude {
+			paths, err := includePaths(include)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				fragment, err := loadFragment(path, dir, includeChain, depth)
+				if err != nil {
+					return nil, err
+				}
+				mergeObjects(result, fragment)
+			}
+		}
+
+		local := map[string]interface{}{}
+		for key, item := range v {
+			if key == includeDirective {
+				continue
+			}
+			expanded, err := expandIncludes(item, dir, includeChain, depth)
+			if err != nil {
+				return nil, err
+			}
+			local[key] = expanded
+		}
+		mergeObjects(result, local)
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded, err := expandIncludes(item, dir, includeChain, depth)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = expanded
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// loadFragment reads, parses and expands the fragment referenced by an include
+func loadFragment(path, dir string, includeChain []string, depth int) (map[string]interface{}, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	for _, included := range includeChain {
+		if included == path {
+			return nil, fmt.Errorf("include cycle detected in configuration: %s -> %s", strings.Join(includeChain, " -> "), path)
+		}
+	}
+	if depth >= maxIncludeDepth {
+		return nil, fmt.Errorf("includes of configuration are nested deeper than %v levels at %s", maxIncludeDepth, path)
+	}
+
+	content, err := readConfigFragment(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read included configuration %s: %v", path, err)
+	}
+
+	var fragment map[string]interface{}
+	if err = unmarshalConfiguration([]byte(content), &fragment); err != nil {
+		return nil, fmt.Errorf("included configuration %s isn't a valid json object: %v", path, err)
+	}
+
+	chain := append(append([]string{}, includeChain...), path)
+	expanded, err := expandIncludes(fragment, filepath.Dir(path), chain, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(map[string]interface{}), nil
+}
+
+// includePaths returns the paths referenced by the value of an $include directive
+func includePaths(include interface{}) ([]string, error) {
+	switch v := include.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			path, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a path or a list of paths, found %v", includeDirective, item)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+	return nil, fmt.Errorf("%s must be a path or a list of paths, found %v", includeDirective, include)
+}
+
+// mergeObjects merges src into dst, values of src take precedence and nested objects are merged recursively
+func mergeObjects(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcObject, srcIsObject := srcValue.(map[string]interface{})
+		dstObject, dstIsObject := dst[key].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			mergeObjects(dstObject, srcObject)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
+
+// unmarshalConfiguration parses json keeping numbers as they are written in the configuration
+func unmarshalConfiguration(data []byte, dest interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(dest)
+}
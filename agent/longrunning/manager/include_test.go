@@ -0,0 +1,89 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFragments replaces readConfigFragment with in memory fragments keyed by path
+func fakeFragments(fragments map[string]string) func() {
+	original := readConfigFragment
+	readConfigFragment = func(path string) (string, error) {
+		if content, ok := fragments[path]; ok {
+			return content, nil
+		}
+		return "", fmt.Errorf("open %s: no such file or directory", path)
+	}
+	return func() { readConfigFragment = original }
+}
+
+func TestResolveConfigIncludesNested(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "config")
+	defer fakeFragments(map[string]string{
+		filepath.Join(base, "shared.json"):           `{"$include": "common/region.json", "Components": [{"Id": "Logs", "Level": 1}]}`,
+		filepath.Join(base, "common", "region.json"): `{"Region": "us-east-1", "Flags": {"a": true, "b": true}}`,
+	})()
+
+	expanded, err := resolveConfigIncludes(`{"$include": "shared.json", "Region": "us-west-2", "Flags": {"b": false}}`, base)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Region": "us-west-2", "Flags": {"a": true, "b": false}, "Components": [{"Id": "Logs", "Level": 1}]}`, expanded)
+}
+
+func TestResolveConfigIncludesWithoutDirective(t *testing.T) {
+	for _, configuration := range []string{`{"Region": "us-east-1"}`, "-config /etc/daemon.conf", ""} {
+		expanded, err := resolveConfigIncludes(configuration, "")
+		assert.NoError(t, err)
+		assert.Equal(t, configuration, expanded)
+	}
+}
+
+func TestResolveConfigIncludesMissingInclude(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "config")
+	defer fakeFragments(map[string]string{})()
+
+	_, err := resolveConfigIncludes(`{"$include": ["missing.json"]}`, base)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to read included configuration "+filepath.Join(base, "missing.json"))
+}
+
+func TestResolveConfigIncludesCycle(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "config")
+	defer fakeFragments(map[string]string{
+		filepath.Join(base, "a.json"): `{"$include": "b.json"}`,
+		filepath.Join(base, "b.json"): `{"Nested": {"$include": "a.json"}}`,
+	})()
+
+	_, err := resolveConfigIncludes(`{"$include": "a.json"}`, base)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle detected")
+}
+
+func TestResolveConfigIncludesDepthLimit(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "config")
+	fragments := map[string]string{}
+	for i := 0; i <= maxIncludeDepth; i++ {
+		fragments[filepath.Join(base, fmt.Sprintf("%v.json", i))] = fmt.Sprintf(`{"$include": "%v.json"}`, i+1)
+	}
+	defer fakeFragments(fragments)()
+
+	_, err := resolveConfigIncludes(`{"$include": "0.json"}`, base)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "nested deeper than")
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// newPluginInstance creates a separate instance of a registered long running plugin.
+// Assign method to global variable to allow unittest to override
+var newPluginInstance = managerContracts.NewInstance
+
+// isPluginInstance returns whether the given name is an instance of a registered long running plugin,
+// e.g. aws:cloudWatch:system, rather than the registered plugin itself
+func isPluginInstance(name string) bool {
+	_, instance := managerContracts.SplitInstanceName(name)
+	return instance != ""
+}
+
+// registeredPluginOrInstance returns the registered long running plugin with the given name. Instances of registered
+// plugins, e.g. aws:cloudWatch:system next to aws:cloudWatch, get registered on their first use so that each runs as a
+// singleton of its own. Instances of plugins that aren't registered, e.g. because they're disabled, aren't created.
+// It has to be called with the lock held for writing.
+func (m *Manager) registeredPluginOrInstance(name string) (p managerContracts.Plugin, isRegistered bool) {
+	if p, isRegistered = m.registeredPlugins[name]; isRegistered {
+		return
+	}
+	base, instance := managerContracts.SplitInstanceName(name)
+	if instance == "" {
+		return
+	}
+	if _, isRegistered = m.registeredPlugins[base]; !isRegistered {
+		return
+	}
+
+	var err error
+	if p, err = newPluginInstance(m.context, name); err != nil {
+		m.context.Log().Errorf("Unable to create long running plugin instance %s: %s", name, err)
+		return p, false
+	}
+	m.context.Log().Infof("Registering long running plugin instance %s of %s", name, base)
+	m.registeredPlugins[name] = p
+	return p, true
+}
@@ -0,0 +1,131 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testCollectorName is the name of a long running plugin registered with a factory so that it supports instances
+const testCollectorName = "aws:testCollector"
+
+var registerTestCollector sync.Once
+
+// stubPluginInstances registers the test collector and makes the manager create the given mocked plugins as its
+// instances, the returned function restores the creation of instances
+func stubPluginInstances(instances map[string]*mockedPlugin) func() {
+	registerTestCollector.Do(func() {
+		managerContracts.RegisterLongRunningPlugin(testCollectorName, func(context context.T, pluginConfig iohandler.PluginConfig) (managerContracts.Plugin, error) {
+			return managerContracts.Plugin{Handler: &mockedPlugin{}}, nil
+		})
+	})
+	original := newPluginInstance
+	newPluginInstance = func(context context.T, name string) (managerContracts.Plugin, error) {
+		return managerContracts.Plugin{
+			Info:    managerContracts.PluginInfo{Name: name},
+			Handler: instances[name],
+		}, nil
+	}
+	return func() {
+		newPluginInstance = original
+	}
+}
+
+func TestTwoInstancesRunConcurrently(t *testing.T) {
+	collector, system, logs := &mockedPlugin{}, &mockedPlugin{}, &mockedPlugin{}
+	systemName := managerContracts.InstanceName(testCollectorName, "system")
+	logsName := managerContracts.InstanceName(testCollectorName, "logs")
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{testCollectorName: collector})
+	defer restore()
+	defer stubPluginInstances(map[string]*mockedPlugin{systemName: system, logsName: logs})()
+	ds.On("Write", mock.Anything).Return(nil)
+	system.On("Start", mock.Anything, "system config", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	logs.On("Start", mock.Anything, "logs config", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	system.On("Stop", mock.Anything, mock.Anything).Return(nil)
+
+	var wg sync.WaitGroup
+	for name, config := range map[string]string{systemName: "system config", logsName: "logs config"} {
+		wg.Add(1)
+		go func(name, config string) {
+			defer wg.Done()
+			assert.NoError(t, m.StartPlugin(name, config, "orchestration", task.NewChanneledCancelFlag(), nil))
+		}(name, config)
+	}
+	wg.Wait()
+
+	running := m.GetRunningPlugins()
+	assert.Len(t, running, 2)
+	assert.Equal(t, "system config", running[systemName].Configuration)
+	assert.Equal(t, "logs config", running[logsName].Configuration)
+	assert.Contains(t, m.GetRegisteredPlugins(), systemName)
+	assert.Contains(t, m.GetRegisteredPlugins(), logsName)
+	system.AssertNumberOfCalls(t, "Start", 1)
+	logs.AssertNumberOfCalls(t, "Start", 1)
+	collector.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// instances are stopped on their own
+	assert.NoError(t, m.StopPlugin(systemName, task.NewChanneledCancelFlag()))
+	running = m.GetRunningPlugins()
+	assert.NotContains(t, running, systemName)
+	assert.Contains(t, running, logsName)
+	logs.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything)
+}
+
+func TestInstancesOfUnregisteredPluginsAreNotStarted(t *testing.T) {
+	instance := &mockedPlugin{}
+	name := managerContracts.InstanceName(testCollectorName, "system")
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	defer stubPluginInstances(map[string]*mockedPlugin{name: instance})()
+
+	assert.Error(t, m.StartPlugin(name, "config", "orchestration", task.NewChanneledCancelFlag(), nil))
+	assert.NotContains(t, m.GetRegisteredPlugins(), name)
+	instance.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreviouslyRunningInstancesGetRegistered(t *testing.T) {
+	name := managerContracts.InstanceName(testCollectorName, "system")
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{testCollectorName: {}})
+	defer restore()
+	defer stubPluginInstances(map[string]*mockedPlugin{name: {}})()
+
+	p, isRegistered := m.registeredPluginOrInstance(name)
+	assert.True(t, isRegistered)
+	assert.Equal(t, name, p.Info.Name)
+	assert.True(t, isPluginInstance(name))
+	assert.False(t, isPluginInstance(testCollectorName))
+
+	_, isRegistered = m.registeredPluginOrInstance("unknown:system")
+	assert.False(t, isRegistered)
+}
+
+func TestInvokedPluginName(t *testing.T) {
+	original := lrpName
+	lrpName = testCollectorName
+	defer func() { lrpName = original }()
+	defer stubPluginInstances(nil)()
+
+	assert.Equal(t, testCollectorName, invokedPluginName(testCollectorName))
+	assert.Equal(t, testCollectorName, invokedPluginName("configureCollector"))
+	assert.Equal(t, testCollectorName+":system", invokedPluginName(testCollectorName+":system"))
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// BinaryIntegrityError is returned when the executable of a long running plugin doesn't match its expected hash
+type BinaryIntegrityError struct {
+	Name     string
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// Error returns the description of the integrity violation
+func (e *BinaryIntegrityError) Error() string {
+	return fmt.Sprintf("security error: executable %s of long running plugin %s has sha256 %s, expected %s - plugin is quarantined",
+		e.Path, e.Name, e.Actual, e.Expected)
+}
+
+// hashFile returns the hex encoded sha256 of the given file.
+// Assign method to global variable to allow unittest to override
+var hashFile = func(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyPluginBinary checks the executable of the given plugin against its expected hash, if one is configured.
+// Plugins failing the check get quarantined - they aren't restarted by health checks until an explicit start
+// verifies their executable again. Verification fails closed if the executable can't be located or read.
+func (m *Manager) verifyPluginBinary(p managerContracts.Plugin) error {
+	expected := strings.ToLower(strings.TrimSpace(p.Info.ExpectedBinarySHA256))
+	if expected == "" {
+		return nil
+	}
+
+	var err error
+	locator, ok := p.Handler.(managerContracts.ExecutableLocator)
+	if !ok {
+		err = fmt.Errorf("security error: unable to verify long running plugin %s - it doesn't expose its executable", p.Info.Name)
+	} else if actual, hashErr := hashFile(locator.ExecutablePath()); hashErr != nil {
+		err = fmt.Errorf("security error: unable to verify executable %s of long running plugin %s: %v", locator.ExecutablePath(), p.Info.Name, hashErr)
+	} else if actual != expected {
+		err = &BinaryIntegrityError{
+			Name:     p.Info.Name,
+			Path:     locator.ExecutablePath(),
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+
+	if err != nil {
+		m.context.Log().Errorf("Quarantining long running plugin %s - %s", p.Info.Name, err)
+		m.setQuarantine(p.Info.Name, err.Error())
+		return err
+	}
+	m.setQuarantine(p.Info.Name, "")
+	return nil
+}
+
+// setQuarantine quarantines a long running plugin for the given reason, an empty reason lifts the quarantine
+func (m *Manager) setQuarantine(name, reason string) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if reason == "" {
+		delete(m.quarantined, name)
+		return
+	}
+	if m.quarantined == nil {
+		m.quarantined = map[string]string{}
+	}
+	if m.quarantined[name] != reason {
+		m.emit(EventQuarantined, name, reason)
+	}
+	m.quarantined[name] = reason
+}
+
+// quarantineReason returns why the given long running plugin is quarantined, if it is
+func (m *Manager) quarantineReason(name string) (reason string, isQuarantined bool) {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	reason, isQuarantined = m.quarantined[name]
+	return
+}
@@ -0,0 +1,111 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedExecutablePlugin is a mocked long running plugin that launches an executable
+type mockedExecutablePlugin struct {
+	*mockedPlugin
+	path string
+}
+
+// ExecutablePath returns the location of the mocked executable
+func (p *mockedExecutablePlugin) ExecutablePath() string {
+	return p.path
+}
+
+// setupExecutablePlugin registers a plugin launching a temporary executable with the given content
+func setupExecutablePlugin(t *testing.T, m *Manager, handler *mockedPlugin, content, expectedHash string) (managerContracts.Plugin, func()) {
+	f, err := ioutil.TempFile("", "lrpm-binary")
+	assert.NoError(t, err)
+	f.WriteString(content)
+	f.Close()
+
+	p := managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "plugin", ExpectedBinarySHA256: expectedHash},
+		Handler: &mockedExecutablePlugin{mockedPlugin: handler, path: f.Name()},
+	}
+	m.registeredPlugins["plugin"] = p
+	return p, func() { os.Remove(f.Name()) }
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestStartVerifiesMatchingBinaryHash(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	p, cleanup := setupExecutablePlugin(t, m, handler, "trusted binary", sha256Hex("trusted binary"))
+	defer cleanup()
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := m.startPluginWithDefaultIO(p, nil)
+
+	assert.NoError(t, err)
+	handler.AssertExpectations(t)
+	_, isQuarantined := m.quarantineReason("plugin")
+	assert.False(t, isQuarantined)
+}
+
+func TestStartQuarantinesMismatchingBinaryHash(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	p, cleanup := setupExecutablePlugin(t, m, handler, "tampered binary", sha256Hex("trusted binary"))
+	defer cleanup()
+	m.setRunning(true)
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(false)
+
+	err := m.startPluginWithDefaultIO(p, nil)
+
+	assert.IsType(t, &BinaryIntegrityError{}, err)
+	assert.Equal(t, sha256Hex("tampered binary"), err.(*BinaryIntegrityError).Actual)
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	reason, detail, _ := m.WhyNotRunning("plugin")
+	assert.Equal(t, ReasonQuarantined, reason)
+	assert.Equal(t, err.Error(), detail[DetailQuarantineReason])
+
+	// quarantined plugins aren't restarted by health checks
+	m.ensurePluginsAreRunning()
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStartWithoutExpectedHashSkipsVerification(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], nil)
+
+	assert.NoError(t, err)
+	handler.AssertExpectations(t)
+}
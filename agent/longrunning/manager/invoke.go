@@ -8,11 +8,48 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	logger "github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/lrpminvoker"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 )
 
 var lrpName = appconfig.PluginNameCloudWatch
 
+// invokedPluginName returns the name of the long running plugin configured by the given plugin ID - steps whose ID
+// names an instance of the plugin, e.g. aws:cloudWatch:system, configure that instance next to the plugin itself
+func invokedPluginName(pluginID string) string {
+	if base, instance := managerContracts.SplitInstanceName(pluginID); base == lrpName && instance != "" {
+		return pluginID
+	}
+	return lrpName
+}
+
+// PluginNotRegisteredError is the error of invocations of long running plugins the manager doesn't know about
+type PluginNotRegisteredError struct {
+	Name string
+}
+
+// Error names the unknown plugin
+func (e *PluginNotRegisteredError) Error() string {
+	return fmt.Sprintf("Plugin %s is not registered by agent", e.Name)
+}
+
+// NotRegistered reports that the plugin isn't registered
+func (e *PluginNotRegisteredError) NotRegistered() bool {
+	return true
+}
+
+// invokerOutput returns the plugin configuration handed off by lrpminvoker along with the orchestration directory
+// the plugin gets started under - the directory lrpminvoker received, else the given default
+func invokerOutput(res *contracts.PluginResult, defaultOrchestrationDir string) (property string, orchestrationDir string) {
+	var output lrpminvoker.InvokerOutput
+	jsonutil.Remarshal(res.Output, &output)
+	if output.OrchestrationDirectory == "" {
+		return output.Properties, defaultOrchestrationDir
+	}
+	return output.Properties, output.OrchestrationDirectory
+}
+
 func CreateResult(msg string, status contracts.ResultStatus, res *contracts.PluginResult) {
 	res.Output = msg
 
@@ -29,39 +66,50 @@ func CreateResult(msg string, status contracts.ResultStatus, res *contracts.Plug
 }
 
 func Invoke(log logger.T, pluginID string, res *contracts.PluginResult, orchestrationDir string) {
-	var lrpm T
-	var err error
 	var startType = res.StandardOutput
-	var property string
-	jsonutil.Remarshal(res.Output, &property)
+	property, orchestrationDir := invokerOutput(res, orchestrationDir)
 	res.StandardOutput = ""
 	res.Output = ""
-	lrpm, err = GetInstance()
+	lrpm, err := GetInstance()
+	if err != nil {
+		log.Errorf("Unable to hand off %s to lrpm: %v", pluginID, err)
+		lrpminvoker.ManagerErrorResult(err, res)
+		return
+	}
+	var name = invokedPluginName(pluginID)
 	var pluginsMap = lrpm.GetRegisteredPlugins()
+	//instances of the plugin get registered by the manager once they're started
 	if _, ok := pluginsMap[lrpName]; !ok {
 		log.Errorf("Given plugin - %s is not registered", lrpName)
-		CreateResult(fmt.Sprintf("Plugin %s is not registered by agent", lrpName),
+		lrpminvoker.ManagerErrorResult(&PluginNotRegisteredError{Name: lrpName}, res)
+		return
+	}
+	release, err := lrpm.AcquirePluginOperation(name, property)
+	if err != nil {
+		log.Errorf("Unable to apply the requested configuration to the plugin - %s: %s", name, err.Error())
+		CreateResult(fmt.Sprintf("Encountered error while configuring the plugin: %s", err.Error()),
 			contracts.ResultStatusFailed, res)
-
 		return
 	}
+	defer release()
+
 	cancelFlag := task.NewChanneledCancelFlag()
 	//NOTE: All long running plugins have json node similar to aws:cloudWatch as mentioned in SSM document - AWS-ConfigureCloudWatch
 
 	//check if plugin is enabled or not - which would be stored in settings
 	switch startType {
 	case "Enabled":
-		enablePlugin(log, orchestrationDir, pluginID, lrpm, cancelFlag, property, res)
+		enablePlugin(log, orchestrationDir, pluginID, name, lrpm, cancelFlag, property, res)
 
 	case "Disabled":
-		log.Infof("Disabling %s", lrpName)
-		if err = lrpm.StopPlugin(lrpName, cancelFlag); err != nil {
+		log.Infof("Disabling %s", name)
+		if err = lrpm.StopPlugin(name, cancelFlag); err != nil {
 			log.Errorf("Unable to stop the plugin - %s: %s", pluginID, err.Error())
 			CreateResult(fmt.Sprintf("Encountered error while stopping the plugin: %s", err.Error()),
 				contracts.ResultStatusFailed, res)
 
 		} else {
-			CreateResult(fmt.Sprintf("Disabled the plugin - %s successfully", lrpName),
+			CreateResult(fmt.Sprintf("Disabled the plugin - %s successfully", name),
 				contracts.ResultStatusSuccess, res)
 			res.Status = contracts.ResultStatusSuccess
 		}
@@ -75,16 +123,23 @@ func Invoke(log logger.T, pluginID string, res *contracts.PluginResult, orchestr
 	return
 }
 
-func enablePlugin(log logger.T, orchestrationDirectory string, pluginID string, lrpm T, cancelFlag task.CancelFlag, property string, res *contracts.PluginResult) {
-	log.Infof("Enabling %s", lrpName)
+func enablePlugin(log logger.T, orchestrationDirectory string, pluginID string, name string, lrpm T, cancelFlag task.CancelFlag, property string, res *contracts.PluginResult) {
+	log.Infof("Enabling %s", name)
+
+	//documents re-applying the configuration of a running plugin don't restart it
+	if info, isRunning := lrpm.GetRunningPlugins()[name]; isRunning && info.Configuration == property {
+		log.Infof("%s is already running with the requested configuration", name)
+		CreateResult("success", contracts.ResultStatusSuccess, res)
+		return
+	}
 
 	//loading properties as string since aws:cloudWatch uses properties as string. Properties has new configuration for cloudwatch plugin.
 	//For more details refer to AWS-ConfigureCloudWatch
 	// TODO cannot check if string is a valid json for cloudwatch
 	//stop the plugin before reconfiguring it
-	log.Debugf("Stopping %s - before applying new configuration", lrpName)
-	if err := lrpm.StopPlugin(lrpName, cancelFlag); err != nil {
-		log.Errorf("Unable to stop the plugin - %s: %s", lrpName, err.Error())
+	log.Debugf("Stopping %s - before applying new configuration", name)
+	if err := lrpm.StopPlugin(name, cancelFlag); err != nil {
+		log.Errorf("Unable to stop the plugin - %s: %s", name, err.Error())
 	}
 	ioConfig := contracts.IOConfiguration{
 		OrchestrationDirectory: orchestrationDirectory,
@@ -96,18 +151,18 @@ func enablePlugin(log logger.T, orchestrationDirectory string, pluginID string,
 	out.Init(log, appconfig.PluginNameCloudWatch)
 
 	//start the plugin with the new configuration
-	if err := lrpm.StartPlugin(lrpName, property, orchestrationDirectory, cancelFlag, out); err != nil {
-		log.Errorf("Unable to start the plugin - %s: %s", lrpName, err.Error())
+	if err := lrpm.StartPlugin(name, property, orchestrationDirectory, cancelFlag, out); err != nil {
+		log.Errorf("Unable to start the plugin - %s: %s", name, err.Error())
 		CreateResult(fmt.Sprintf("Encountered error while starting the plugin: %s", err.Error()),
 			contracts.ResultStatusFailed, res)
 	} else {
 
 		if len(out.GetStderr()) > 0 {
-			log.Errorf("Unable to start the plugin - %s: %s", lrpName, out.GetStderr())
+			log.Errorf("Unable to start the plugin - %s: %s", name, out.GetStderr())
 
 			// Stop the plugin if configuration failed.
-			if err := lrpm.StopPlugin(lrpName, cancelFlag); err != nil {
-				log.Errorf("Unable to start the plugin - %s: %s", lrpName, err.Error())
+			if err := lrpm.StopPlugin(name, cancelFlag); err != nil {
+				log.Errorf("Unable to start the plugin - %s: %s", name, err.Error())
 			}
 
 			CreateResult(fmt.Sprintf("Encountered error while starting the plugin: %s", out.GetStderr()),
@@ -0,0 +1,157 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/lrpminvoker"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// startRecordingMock is a mocked manager recording the plugins it stops and the orchestration directory plugins
+// get started under
+type startRecordingMock struct {
+	*Mock
+	running           map[string]managerContracts.PluginInfo
+	stopped           []string
+	orchestrationDirs []string
+}
+
+// GetRunningPlugins returns the stubbed running plugins
+func (m *startRecordingMock) GetRunningPlugins() map[string]managerContracts.PluginInfo {
+	return m.running
+}
+
+// StopPlugin records the stopped plugin
+func (m *startRecordingMock) StopPlugin(name string, cancelFlag task.CancelFlag) error {
+	m.stopped = append(m.stopped, name)
+	return nil
+}
+
+// StartPlugin records the given orchestration directory
+func (m *startRecordingMock) StartPlugin(name, configuration, orchestrationDir string, cancelFlag task.CancelFlag, out iohandler.IOHandler) error {
+	m.orchestrationDirs = append(m.orchestrationDirs, orchestrationDir)
+	return nil
+}
+
+func TestInvokerOutputUsesHandedOffOrchestrationDir(t *testing.T) {
+	res := &contracts.PluginResult{Output: lrpminvoker.InvokerOutput{Properties: "config", OrchestrationDirectory: "invoker"}}
+	property, dir := invokerOutput(res, "default")
+	assert.Equal(t, "config", property)
+	assert.Equal(t, "invoker", dir)
+
+	// results read back from the document state are json
+	var persisted interface{}
+	data, _ := json.Marshal(res.Output)
+	json.Unmarshal(data, &persisted)
+	property, dir = invokerOutput(&contracts.PluginResult{Output: persisted}, "default")
+	assert.Equal(t, "config", property)
+	assert.Equal(t, "invoker", dir)
+
+	property, dir = invokerOutput(&contracts.PluginResult{Output: lrpminvoker.InvokerOutput{Properties: "config"}}, "default")
+	assert.Equal(t, "config", property)
+	assert.Equal(t, "default", dir)
+}
+
+func TestEnablePluginPropagatesOrchestrationDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lrpminvoker")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	orchestrationDir := filepath.Join(dir, "document", "aws:cloudWatch")
+	lrpm := &startRecordingMock{Mock: NewMockDefault()}
+	res := &contracts.PluginResult{}
+
+	enablePlugin(log.NewMockLog(), orchestrationDir, "aws:cloudWatch", CloudWatchId, lrpm, task.NewChanneledCancelFlag(), "config", res)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	assert.Equal(t, []string{orchestrationDir}, lrpm.orchestrationDirs)
+}
+
+func TestInvokeBeforeInitializationIsRetriable(t *testing.T) {
+	defer resetSingleton()()
+	res := &contracts.PluginResult{StandardOutput: "Enabled", Output: lrpminvoker.InvokerOutput{Properties: "config"}}
+
+	Invoke(log.NewMockLog(), "aws:cloudWatch", res, "orchestration")
+
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, lrpminvoker.ResultCodeManagerNotReady, res.Code)
+	assert.Contains(t, res.StandardError, ErrNotInitialized.Error())
+}
+
+func TestInvokeOfUnregisteredPlugin(t *testing.T) {
+	defer resetSingleton()()
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	singletonInstance = m
+	res := &contracts.PluginResult{StandardOutput: "Enabled", Output: lrpminvoker.InvokerOutput{Properties: "config"}}
+
+	Invoke(log.NewMockLog(), "aws:cloudWatch", res, "orchestration")
+
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, lrpminvoker.ResultCodePluginNotRegistered, res.Code)
+	assert.Contains(t, res.StandardError, lrpName)
+}
+
+func TestEnablePluginAlreadyRunningWithSameConfigIsNoop(t *testing.T) {
+	lrpm := &startRecordingMock{Mock: NewMockDefault(), running: map[string]managerContracts.PluginInfo{
+		CloudWatchId: {Name: CloudWatchId, Configuration: "config"},
+	}}
+	res := &contracts.PluginResult{}
+
+	enablePlugin(log.NewMockLog(), "orchestration", "aws:cloudWatch", CloudWatchId, lrpm, task.NewChanneledCancelFlag(), "config", res)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	assert.Empty(t, lrpm.stopped)
+	assert.Empty(t, lrpm.orchestrationDirs)
+}
+
+func TestEnablePluginAlreadyRunningWithOtherConfigRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lrpminvoker")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	lrpm := &startRecordingMock{Mock: NewMockDefault(), running: map[string]managerContracts.PluginInfo{
+		CloudWatchId: {Name: CloudWatchId, Configuration: "previous config"},
+	}}
+	res := &contracts.PluginResult{}
+
+	enablePlugin(log.NewMockLog(), dir, "aws:cloudWatch", CloudWatchId, lrpm, task.NewChanneledCancelFlag(), "config", res)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	assert.Equal(t, []string{CloudWatchId}, lrpm.stopped)
+	assert.Equal(t, []string{dir}, lrpm.orchestrationDirs)
+}
+
+func TestEnablePluginNotRunningStarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lrpminvoker")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	lrpm := &startRecordingMock{Mock: NewMockDefault()}
+	res := &contracts.PluginResult{}
+
+	enablePlugin(log.NewMockLog(), dir, "aws:cloudWatch", CloudWatchId, lrpm, task.NewChanneledCancelFlag(), "config", res)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, res.Status)
+	assert.Equal(t, []string{dir}, lrpm.orchestrationDirs)
+}
@@ -44,7 +44,7 @@ func (m *Manager) StopPlugin(name string, cancelFlag task.CancelFlag) (err error
 
 	if isRegisteredPlugin && isRunningPlugin {
 		//stop the plugin
-		if err = p.Handler.Stop(m.context, cancelFlag); err != nil {
+		if err = m.stopPluginHandler(p, cancelFlag, 0); err != nil {
 			// check if cloud watch exe process has been terminated manually
 			if p.Handler.IsRunning(m.context) {
 				log.Errorf("Failed to stop long running plugin - %s because of %s", name, err)
@@ -53,14 +53,18 @@ func (m *Manager) StopPlugin(name string, cancelFlag task.CancelFlag) (err error
 		}
 		//remove the entry from the map of running plugins
 		delete(m.runningPlugins, name)
+		m.forgetCancelFlag(name)
+		m.emit(EventStopped, name, "")
 
-		if err = dataStore.Write(m.runningPlugins); err != nil {
+		if err = m.writeDataStore(); err != nil {
 			log.Errorf("Failed to update datastore - because of %s", err)
 		}
 
-		// Update the config file to "IsEnabled": "false"
-		if err = cloudwatch.Instance().Disable(); err != nil {
-			log.Errorf("Failed to update config file - because of %s", err)
+		// Update the config file to "IsEnabled": "false" - the config file belongs to the plugin, not its instances
+		if !isPluginInstance(name) {
+			if err = cloudwatch.Instance().Disable(); err != nil {
+				log.Errorf("Failed to update config file - because of %s", err)
+			}
 		}
 
 		return
@@ -81,22 +85,68 @@ func (m *Manager) StartPlugin(name, configuration string, orchestrationDir strin
 	//check if the plugin is registered - this is an extra check since ideally we expect invoker to be aware of registered plugins.
 	var p plugin.Plugin
 	var isRegisteredPlugin bool
-	if p, isRegisteredPlugin = m.registeredPlugins[name]; !isRegisteredPlugin {
+	if p, isRegisteredPlugin = m.registeredPluginOrInstance(name); !isRegisteredPlugin {
 		err = fmt.Errorf("unable to run %s since it's not even registered", name)
 		return
 	}
 
 	//set the config path of the long running plugin
 	p.Info.Configuration = configuration
-	if err = p.Handler.Start(m.context, p.Info.Configuration, orchestrationDir, cancelFlag, out); err != nil {
+
+	//resolve the includes of the configuration - the configuration is persisted as given so that changes of shared fragments get picked up on the next start
+	var expandedConfiguration string
+	if expandedConfiguration, err = expandPluginConfiguration(configuration); err != nil {
+		log.Errorf("Failed to resolve configuration of long running plugin - %s because of %s", name, err)
+		m.recordStartResult(name, err)
+		return
+	}
+	if err = m.verifyPluginBinary(p); err != nil {
+		m.recordStartResult(name, err)
+		return
+	}
+	if err = injectedStartFailure(name); err != nil {
+		log.Errorf("Failed to start long running plugin - %s because of %s", name, err)
+		m.recordStartResult(name, err)
+		return
+	}
+	//secrets are resolved just in time for the plugin - neither the datastore nor the cloudwatch config file get them
+	resolvedConfiguration, secrets, err := m.resolveSecrets(expandedConfiguration)
+	if err != nil {
+		log.Errorf("Failed to resolve secrets in configuration of long running plugin - %s because of %s", name, err)
+		m.recordStartResult(name, err)
+		return
+	}
+	//long running plugins are singletons - a start that races with a restart by the health check is skipped
+	if !m.beginStart(name) {
+		log.Warnf("Not starting long running plugin - %s since a start of it is already in progress", name)
+		err = ErrStartInProgress
+		return
+	}
+	err = p.Handler.Start(newRedactingContext(m.context, secrets), resolvedConfiguration, orchestrationDir, cancelFlag, out)
+	m.endStart(name)
+	if err != nil {
 		log.Errorf("Failed to start long running plugin - %s because of %s", name, err)
+		m.recordStartResult(name, err)
 		return
 	}
+	m.recordStartResult(name, nil)
+	m.recordCancelFlag(name, cancelFlag)
+	//the configuration was given by a document, so it's written with the current schema
+	m.setQuarantine(name, "")
+	m.emit(EventStarted, name, "")
 
 	//edit the plugin info
+	//keep track of the working directory of the plugin so that restarts use the same one
+	if orchestrationDir != "" {
+		p.Info.OrchestrationDir = orchestrationDir
+	} else if p.Info.OrchestrationDir == "" {
+		p.Info.OrchestrationDir = pluginOrchestrationDir(m.context, name)
+	}
+	//starting a lazy plugin through a document activates it
 	p.Info.State = plugin.PluginState{
 		LastConfigurationModifiedTime: time.Now(),
 		IsEnabled:                     true,
+		LazyActive:                    p.Info.Lazy,
 	}
 
 	// TODO move persisting out of executing logic
@@ -104,14 +154,17 @@ func (m *Manager) StartPlugin(name, configuration string, orchestrationDir strin
 	log.Debugf("Persisting info about %s in datastore", p.Info.Name)
 
 	// TODO separate persist part and actual running part
-	if err = dataStore.Write(m.runningPlugins); err != nil {
+	if err = m.writeDataStore(); err != nil {
 		err = fmt.Errorf("Failed to persist info about %s in datastore because : %s", p.Info.Name, err.Error())
 		log.Errorf(err.Error())
 	}
 
-	// Update the config file with new configuration
+	// Update the config file with new configuration - the config file belongs to the plugin, not its instances
+	if isPluginInstance(name) {
+		return
+	}
 	var engineConfigurationParser cloudwatch.EngineConfigurationParser
-	json.Unmarshal([]byte(p.Info.Configuration), &engineConfigurationParser)
+	json.Unmarshal([]byte(expandedConfiguration), &engineConfigurationParser)
 	log.Debugf("unmarshal engine configuration parser: %v", engineConfigurationParser)
 	if err = cloudwatch.Instance().Enable(engineConfigurationParser.EngineConfiguration); err != nil {
 		log.Errorf("Failed to update config file - because of %s", err)
@@ -0,0 +1,71 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// isLazyInactive returns true for lazy plugins that haven't been activated yet - the manager doesn't start them
+func isLazyInactive(p managerContracts.Plugin, info managerContracts.PluginInfo) bool {
+	return p.Info.Lazy && !info.State.LazyActive
+}
+
+// Activate starts a lazy long running plugin on demand with its current configuration (or the configuration it was
+// registered with) and persists that it's active, so that from now on it's managed like any other plugin.
+// Activating a plugin that isn't lazy or is already active has no effect.
+func (m *Manager) Activate(name string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	log := m.context.Log()
+	p, isRegistered := m.registeredPlugins[name]
+	if !isRegistered {
+		return fmt.Errorf("unable to activate %s since it's not even registered", name)
+	}
+	info, isConfigured := m.runningPlugins[name]
+	if !isConfigured {
+		info = p.Info
+	}
+	if !isLazyInactive(p, info) {
+		log.Debugf("%s doesn't need to be activated", name)
+		return nil
+	}
+
+	log.Infof("Activating lazy long running plugin - %s", name)
+	info.State = managerContracts.PluginState{
+		LastConfigurationModifiedTime: time.Now(),
+		IsEnabled:                     true,
+		LazyActive:                    true,
+	}
+	m.runningPlugins[name] = info
+	p.Info.Configuration = info.Configuration
+	p.Info.State = info.State
+	m.registeredPlugins[name] = p
+	if err := m.writeDataStore(); err != nil {
+		log.Errorf("Failed to persist activation of %s - because of %s", name, err)
+	}
+
+	if err := m.startPluginOnce(p, task.NewChanneledCancelFlag()); err != nil {
+		log.Errorf("Failed to start activated long running plugin - %s because of %s", name, err)
+		return err
+	}
+	m.emit(EventStarted, name, "")
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLazyPluginIsSkippedAtBootAndActivatedOnDemand(t *testing.T) {
+	lazyHandler := &mockedPlugin{}
+	eagerHandler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"lazy": lazyHandler, "eager": eagerHandler})
+	defer restore()
+	lazy := m.registeredPlugins["lazy"]
+	lazy.Info.Lazy = true
+	lazy.Info.Configuration = "lazy config"
+	m.registeredPlugins["lazy"] = lazy
+
+	enabled := managerContracts.PluginState{IsEnabled: true}
+	ds.On("Read").Return(map[string]managerContracts.PluginInfo{
+		"lazy":  {Name: "lazy", Configuration: "lazy config", State: enabled},
+		"eager": {Name: "eager", Configuration: "eager config", State: enabled},
+	}, nil)
+	eagerHandler.On("Start", mock.Anything, "eager config", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	m.ModuleExecute(m.context)
+	defer m.stopLifeCycleManagementJob()
+
+	eagerHandler.AssertExpectations(t)
+	lazyHandler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	lazyHandler.On("IsRunning", mock.Anything).Return(false)
+	eagerHandler.On("IsRunning", mock.Anything).Return(true)
+	reason, _, _ := m.WhyNotRunning("lazy")
+	assert.Equal(t, ReasonLazyInactive, reason)
+
+	// the health check doesn't start lazy plugins that weren't activated
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.startPlugin = pool
+	m.ensurePluginsAreRunning()
+	pool.AssertNotCalled(t, "Submit", mock.Anything, "lazy", mock.Anything)
+
+	// activation starts the plugin and persists that it's active
+	ds.On("Write", mock.Anything).Return(nil).Once()
+	lazyHandler.On("Start", mock.Anything, "lazy config", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	assert.NoError(t, m.Activate("lazy"))
+	lazyHandler.AssertExpectations(t)
+	persisted := ds.Calls[len(ds.Calls)-1].Arguments.Get(0).(map[string]managerContracts.PluginInfo)
+	assert.True(t, persisted["lazy"].State.LazyActive)
+
+	// once active, the plugin is managed normally
+	m.ensurePluginsAreRunning()
+	pool.AssertCalled(t, "Submit", mock.Anything, "lazy", mock.Anything)
+
+	// activating again has no effect
+	assert.NoError(t, m.Activate("lazy"))
+	lazyHandler.AssertNumberOfCalls(t, "Start", 1)
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+// MetricPluginRestarts counts the long running plugins revived by the manager, with the plugin name as dimension
+const MetricPluginRestarts = "LongRunningPluginRestarts"
+
+// MetricRunningPlugins is the number of long running plugins found running by the latest health check
+const MetricRunningPlugins = "LongRunningPluginsRunning"
+
+// DimensionPluginName is the dimension holding the name of a long running plugin
+const DimensionPluginName = "PluginName"
+
+// MetricsRecorder records the metrics of the long running plugin manager
+type MetricsRecorder interface {
+	IncrementCounter(name string, dimensions map[string]string)
+	SetGauge(name string, value float64, dimensions map[string]string)
+}
+
+// noMetrics is the MetricsRecorder used when the manager isn't given any, it drops all metrics
+type noMetrics struct{}
+
+// IncrementCounter drops the counter
+func (noMetrics) IncrementCounter(name string, dimensions map[string]string) {}
+
+// SetGauge drops the gauge
+func (noMetrics) SetGauge(name string, value float64, dimensions map[string]string) {}
+
+// recordRestart counts the revival of the given long running plugin
+func (m *Manager) recordRestart(name string) {
+	if m.metrics != nil {
+		m.metrics.IncrementCounter(MetricPluginRestarts, map[string]string{DimensionPluginName: name})
+	}
+}
+
+// recordRunningPlugins updates the number of long running plugins found running
+func (m *Manager) recordRunningPlugins(count int) {
+	if m.metrics != nil {
+		m.metrics.SetGauge(MetricRunningPlugins, float64(count), nil)
+	}
+}
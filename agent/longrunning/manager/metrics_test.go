@@ -0,0 +1,95 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedMetrics stands in for the MetricsRecorder of the manager
+type mockedMetrics struct {
+	mock.Mock
+}
+
+func (m *mockedMetrics) IncrementCounter(name string, dimensions map[string]string) {
+	m.Called(name, dimensions)
+}
+
+func (m *mockedMetrics) SetGauge(name string, value float64, dimensions map[string]string) {
+	m.Called(name, value, dimensions)
+}
+
+func TestHealthCheckRecordsRestartsAndRunningPlugins(t *testing.T) {
+	running := &mockedPlugin{}
+	stopped := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"running": running, "stopped": stopped})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	m.runningPlugins["running"] = managerContracts.PluginInfo{Name: "running", State: managerContracts.PluginState{IsEnabled: true}}
+	m.runningPlugins["stopped"] = managerContracts.PluginInfo{Name: "stopped", State: managerContracts.PluginState{IsEnabled: true}}
+	pool := newRunningPool()
+	pool.On("HasJob", mock.Anything).Return(false)
+	m.startPlugin = pool
+	metrics := &mockedMetrics{}
+	metrics.On("IncrementCounter", mock.Anything, mock.Anything).Return()
+	metrics.On("SetGauge", mock.Anything, mock.Anything, mock.Anything).Return()
+	m.metrics = metrics
+
+	running.On("IsRunning", mock.Anything).Return(true)
+	stopped.On("IsRunning", mock.Anything).Return(false)
+	stopped.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	m.ensurePluginsAreRunning()
+
+	metrics.AssertCalled(t, "SetGauge", MetricRunningPlugins, float64(1), map[string]string(nil))
+	metrics.AssertCalled(t, "IncrementCounter", MetricPluginRestarts, map[string]string{DimensionPluginName: "stopped"})
+	metrics.AssertNumberOfCalls(t, "IncrementCounter", 1)
+}
+
+func TestFailedRestartIsNotRecorded(t *testing.T) {
+	stopped := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"stopped": stopped})
+	defer restore()
+	m.runningPlugins["stopped"] = managerContracts.PluginInfo{Name: "stopped", State: managerContracts.PluginState{IsEnabled: true}}
+	pool := newRunningPool()
+	pool.On("HasJob", mock.Anything).Return(false)
+	m.startPlugin = pool
+	metrics := &mockedMetrics{}
+	metrics.On("SetGauge", mock.Anything, mock.Anything, mock.Anything).Return()
+	m.metrics = metrics
+
+	stopped.On("IsRunning", mock.Anything).Return(false)
+	stopped.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+
+	m.ensurePluginsAreRunning()
+
+	metrics.AssertCalled(t, "SetGauge", MetricRunningPlugins, float64(0), map[string]string(nil))
+	metrics.AssertNotCalled(t, "IncrementCounter", mock.Anything, mock.Anything)
+}
+
+func TestNilMetricsRecorderIsIgnored(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.metrics = nil
+
+	assert.NotPanics(t, func() {
+		m.recordRestart("plugin")
+		m.recordRunningPlugins(1)
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// migratePersistedConfigurations migrates the persisted configurations of long running plugins that were written
+// with an older configuration schema than the one of the registered plugin, and persists the migrated result.
+// Configurations that can't be migrated are kept as they are and their plugin gets quarantined, so that it isn't
+// started with a configuration it can't understand until a document configures it again.
+func (m *Manager) migratePersistedConfigurations() {
+	log := m.context.Log()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	migrated := false
+	for name, info := range m.runningPlugins {
+		p, isRegistered := m.registeredPlugins[name]
+		if !isRegistered || info.ConfigVersion >= p.Info.ConfigVersion {
+			continue
+		}
+
+		var err error
+		var configuration string
+		if migrator, ok := p.Handler.(managerContracts.ConfigMigrator); !ok {
+			err = fmt.Errorf("plugin doesn't support migrating configurations")
+		} else {
+			configuration, err = migrator.MigrateConfig(info.Configuration, info.ConfigVersion)
+		}
+
+		if err != nil {
+			reason := fmt.Sprintf("unable to migrate configuration from version %v to %v: %v", info.ConfigVersion, p.Info.ConfigVersion, err)
+			log.Errorf("Quarantining long running plugin %s - %s", name, reason)
+			m.setQuarantine(name, reason)
+			continue
+		}
+
+		log.Infof("Migrated configuration of long running plugin %s from version %v to %v", name, info.ConfigVersion, p.Info.ConfigVersion)
+		info.Configuration = configuration
+		info.ConfigVersion = p.Info.ConfigVersion
+		m.runningPlugins[name] = info
+		migrated = true
+	}
+
+	if migrated {
+		if err := m.writeDataStore(); err != nil {
+			log.Errorf("Failed to persist migrated configurations - because of %s", err)
+		}
+	}
+}
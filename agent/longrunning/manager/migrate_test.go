@@ -0,0 +1,107 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedMigratingPlugin is a mocked long running plugin that migrates its configuration
+type mockedMigratingPlugin struct {
+	*mockedPlugin
+}
+
+// MigrateConfig mocks migrating a configuration
+func (p *mockedMigratingPlugin) MigrateConfig(old string, fromVersion int) (string, error) {
+	args := p.Called(old, fromVersion)
+	return args.String(0), args.Error(1)
+}
+
+// setupMigratingPlugin registers a migrating plugin expecting configuration version 2 with a persisted version 1 configuration
+func setupMigratingPlugin(m *Manager, handler *mockedPlugin) *mockedMigratingPlugin {
+	migrator := &mockedMigratingPlugin{mockedPlugin: handler}
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "plugin", ConfigVersion: 2},
+		Handler: migrator,
+	}
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{
+		Name:          "plugin",
+		Configuration: `{"Region":"us-east-1"}`,
+		ConfigVersion: 1,
+		State:         managerContracts.PluginState{IsEnabled: true},
+	}
+	return migrator
+}
+
+func TestMigratePersistedConfigurations(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	migrator := setupMigratingPlugin(m, handler)
+	migrator.On("MigrateConfig", `{"Region":"us-east-1"}`, 1).Return(`{"Regions":["us-east-1"]}`, nil).Once()
+	ds.On("Write", mock.Anything).Return(nil).Once()
+
+	m.migratePersistedConfigurations()
+
+	migrator.AssertExpectations(t)
+	ds.AssertExpectations(t)
+	assert.Equal(t, `{"Regions":["us-east-1"]}`, m.runningPlugins["plugin"].Configuration)
+	assert.Equal(t, 2, m.runningPlugins["plugin"].ConfigVersion)
+	_, isQuarantined := m.quarantineReason("plugin")
+	assert.False(t, isQuarantined)
+
+	// up to date configurations aren't migrated again
+	m.migratePersistedConfigurations()
+	migrator.AssertNumberOfCalls(t, "MigrateConfig", 1)
+}
+
+func TestMigratePersistedConfigurationsFlagsFailedMigration(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	migrator := setupMigratingPlugin(m, handler)
+	migrator.On("MigrateConfig", mock.Anything, 1).Return("", fmt.Errorf("unknown format")).Once()
+
+	m.migratePersistedConfigurations()
+
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+	assert.Equal(t, `{"Region":"us-east-1"}`, m.runningPlugins["plugin"].Configuration)
+	assert.Equal(t, 1, m.runningPlugins["plugin"].ConfigVersion)
+	reason, isQuarantined := m.quarantineReason("plugin")
+	assert.True(t, isQuarantined)
+	assert.Contains(t, reason, "unable to migrate configuration from version 1 to 2: unknown format")
+}
+
+func TestMigratePersistedConfigurationsWithoutMigrator(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	setupMigratingPlugin(m, handler)
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "plugin", ConfigVersion: 2},
+		Handler: handler,
+	}
+
+	m.migratePersistedConfigurations()
+
+	_, isQuarantined := m.quarantineReason("plugin")
+	assert.True(t, isQuarantined)
+	assert.Contains(t, m.runningPlugins, "plugin")
+}
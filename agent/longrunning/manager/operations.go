@@ -0,0 +1,73 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+)
+
+// ErrPluginOperationConflict is returned when an operation on a long running plugin gets rejected because
+// another operation on the same plugin is in progress
+var ErrPluginOperationConflict = errors.New("another operation on the long running plugin is in progress")
+
+// pluginOperation is an operation in progress on a long running plugin
+type pluginOperation struct {
+	configuration string
+	done          chan struct{}
+}
+
+// AcquirePluginOperation serializes operations (e.g. stop & start with a new configuration) on the given long running
+// plugin. If another operation on the plugin is in progress, the configured ConflictPolicy decides whether the call
+// waits for it to finish or fails with ErrPluginOperationConflict. The returned release function must be called
+// once the operation is done.
+func (m *Manager) AcquirePluginOperation(name, configuration string) (release func(), err error) {
+	log := m.context.Log()
+	policy := m.GetConfig().ConflictPolicy
+
+	for {
+		m.operationsLock.Lock()
+		current, inProgress := m.operations[name]
+		if !inProgress {
+			if m.operations == nil {
+				m.operations = map[string]*pluginOperation{}
+			}
+			op := &pluginOperation{
+				configuration: configuration,
+				done:          make(chan struct{}),
+			}
+			m.operations[name] = op
+			m.operationsLock.Unlock()
+
+			return func() {
+				m.operationsLock.Lock()
+				delete(m.operations, name)
+				m.operationsLock.Unlock()
+				close(op.done)
+			}, nil
+		}
+		m.operationsLock.Unlock()
+
+		log.Warnf("Conflicting operations requested for long running plugin %s (policy %s). Configuration in progress: %s; requested configuration: %s",
+			name,
+			policy,
+			printableConfiguration(log, name, current.configuration),
+			printableConfiguration(log, name, configuration))
+
+		if policy == ConflictPolicyReject {
+			return nil, ErrPluginOperationConflict
+		}
+		<-current.done
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquirePluginOperationRejectsConflict(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	m.config.ConflictPolicy = ConflictPolicyReject
+
+	release, err := m.AcquirePluginOperation("plugin", "config1")
+	assert.NoError(t, err)
+
+	_, err = m.AcquirePluginOperation("plugin", "config2")
+	assert.Equal(t, ErrPluginOperationConflict, err)
+
+	// operations on other plugins aren't affected
+	releaseOther, err := m.AcquirePluginOperation("other", "config")
+	assert.NoError(t, err)
+	releaseOther()
+
+	release()
+	release, err = m.AcquirePluginOperation("plugin", "config2")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestAcquirePluginOperationQueuesConflict(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	m.config.ConflictPolicy = ConflictPolicyQueue
+
+	release, err := m.AcquirePluginOperation("plugin", "config1")
+	assert.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		queuedRelease, err := m.AcquirePluginOperation("plugin", "config2")
+		assert.NoError(t, err)
+		close(acquired)
+		queuedRelease()
+	}()
+
+	select {
+	case <-acquired:
+		assert.Fail(t, "queued operation ran while another operation was in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		assert.Fail(t, "queued operation didn't run after the operation in progress was done")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubPluginDirs records the orchestration directories created by the manager, the returned function restores
+// the original implementation
+func stubPluginDirs(err error) (*[]string, func()) {
+	var created []string
+	original := makePluginDirs
+	makePluginDirs = func(dir string) error {
+		created = append(created, dir)
+		return err
+	}
+	return &created, func() { makePluginDirs = original }
+}
+
+func TestPluginOrchestrationDirIsDeterministic(t *testing.T) {
+	ctx := context.NewMockDefault()
+
+	dir := pluginOrchestrationDir(ctx, appconfig.PluginNameCloudWatch)
+
+	assert.Equal(t, dir, pluginOrchestrationDir(ctx, appconfig.PluginNameCloudWatch))
+	assert.Equal(t, filepath.Join(defaultOrchestrationDir(ctx), appconfig.LongRunningPluginsLocation, "aws_cloudWatch"), dir)
+	assert.NotEqual(t, dir, pluginOrchestrationDir(ctx, "other"))
+}
+
+func TestStartPassesOrchestrationDirToPlugin(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	created, restoreDirs := stubPluginDirs(nil)
+	defer restoreDirs()
+
+	expected := pluginOrchestrationDir(m.context, "plugin")
+	handler.On("Start", mock.Anything, mock.Anything, expected, mock.Anything, mock.Anything).Return(nil)
+
+	assert.NoError(t, m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag()))
+	assert.Equal(t, []string{expected}, *created)
+}
+
+func TestStartUsesPersistedOrchestrationDir(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	_, restoreDirs := stubPluginDirs(nil)
+	defer restoreDirs()
+	p := m.registeredPlugins["plugin"]
+	p.Info.OrchestrationDir = "persisted"
+
+	handler.On("Start", mock.Anything, mock.Anything, "persisted", mock.Anything, mock.Anything).Return(nil)
+
+	assert.NoError(t, m.startPluginWithDefaultIO(p, task.NewChanneledCancelFlag()))
+}
+
+func TestStartFailsWithoutOrchestrationDir(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	_, restoreDirs := stubPluginDirs(errors.New("read-only file system"))
+	defer restoreDirs()
+
+	err := m.startPluginWithDefaultIO(managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: "plugin"}, Handler: handler}, task.NewChanneledCancelFlag())
+
+	assert.Error(t, err)
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStartPluginPersistsHandedOffOrchestrationDir(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	handler.On("Start", mock.Anything, "config", "document", mock.Anything, mock.Anything).Return(nil)
+
+	assert.NoError(t, m.StartPlugin("plugin", "config", "document", task.NewChanneledCancelFlag(), nil))
+
+	assert.Equal(t, "document", m.GetRunningPlugins()["plugin"].OrchestrationDir)
+	ds.AssertCalled(t, "Write", mock.MatchedBy(func(data map[string]managerContracts.PluginInfo) bool {
+		return data["plugin"].OrchestrationDir == "document"
+	}))
+}
+
+func TestStartPluginWithoutOrchestrationDirUsesDefault(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	handler.On("Start", mock.Anything, "config", "", mock.Anything, mock.Anything).Return(nil)
+
+	assert.NoError(t, m.StartPlugin("plugin", "config", "", task.NewChanneledCancelFlag(), nil))
+
+	assert.Equal(t, pluginOrchestrationDir(m.context, "plugin"), m.GetRunningPlugins()["plugin"].OrchestrationDir)
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// DefaultPreStopTimeout is the time the pre-stop hook of a long running plugin gets when the plugin doesn't set one
+const DefaultPreStopTimeout = 5 * time.Second
+
+// ErrPreStopTimeout is returned when the pre-stop hook of a long running plugin didn't finish in time
+var ErrPreStopTimeout = errors.New("pre-stop hook of long running plugin timed out")
+
+// runPreStop runs the pre-stop hook of the given plugin if it has one. The hook gets the timeout of the plugin,
+// capped by the remaining stop budget if there is one (budget 0 means no budget).
+func (m *Manager) runPreStop(p managerContracts.Plugin, budget time.Duration) error {
+	hook, hasHook := p.Handler.(managerContracts.PreStopper)
+	if !hasHook {
+		return nil
+	}
+	log := m.context.Log()
+
+	timeout := p.Info.PreStopTimeout
+	if timeout <= 0 {
+		timeout = DefaultPreStopTimeout
+	}
+	if budget > 0 && budget < timeout {
+		timeout = budget
+	}
+
+	log.Infof("Running pre-stop hook of long running plugin - %s (timeout %v)", p.Info.Name, timeout)
+	cancelFlag := task.NewChanneledCancelFlag()
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.PreStop(m.context, cancelFlag)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Errorf("Pre-stop hook of long running plugin - %s failed because of %s", p.Info.Name, err)
+			return err
+		}
+		log.Infof("Pre-stop hook of long running plugin - %s succeeded", p.Info.Name)
+		return nil
+	case <-m.clock.After(timeout):
+		cancelFlag.SetWithReason(task.Canceled, task.CancelReasonTimeout)
+		log.Errorf("Pre-stop hook of long running plugin - %s didn't finish within %v", p.Info.Name, timeout)
+		return ErrPreStopTimeout
+	}
+}
+
+// stopPluginHandler runs the pre-stop hook of the given plugin and then stops it. The hook is bounded by the stop
+// budget (budget 0 means no budget). If the hook fails and the plugin's pre-stop policy is
+// PreStopPolicyBlock, the plugin isn't stopped and the hook error is returned.
+func (m *Manager) stopPluginHandler(p managerContracts.Plugin, cancelFlag task.CancelFlag, budget time.Duration) error {
+	if err := m.runPreStop(p, budget); err != nil {
+		if p.Info.PreStopPolicy == managerContracts.PreStopPolicyBlock {
+			m.context.Log().Warnf("Not stopping long running plugin - %s since its pre-stop hook failed", p.Info.Name)
+			return err
+		}
+		m.context.Log().Warnf("Stopping long running plugin - %s despite the failed pre-stop hook", p.Info.Name)
+	}
+	return p.Handler.Stop(m.context, cancelFlag)
+}
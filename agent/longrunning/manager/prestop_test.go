@@ -0,0 +1,141 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedPreStopPlugin is a long running plugin with a pre-stop hook that records the order of hook and stop calls
+type mockedPreStopPlugin struct {
+	mockedPlugin
+	hookDuration time.Duration
+	hookErr      error
+
+	callsLock sync.Mutex
+	calls     []string
+}
+
+func (m *mockedPreStopPlugin) PreStop(context context.T, cancelFlag task.CancelFlag) error {
+	m.record("PreStop")
+	canceled := make(chan struct{})
+	go func() {
+		cancelFlag.Wait()
+		close(canceled)
+	}()
+	select {
+	case <-time.After(m.hookDuration):
+	case <-canceled:
+	}
+	return m.hookErr
+}
+
+func (m *mockedPreStopPlugin) Stop(context context.T, cancelFlag task.CancelFlag) error {
+	m.record("Stop")
+	return nil
+}
+
+func (m *mockedPreStopPlugin) record(call string) {
+	m.callsLock.Lock()
+	defer m.callsLock.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *mockedPreStopPlugin) recorded() []string {
+	m.callsLock.Lock()
+	defer m.callsLock.Unlock()
+	return append([]string{}, m.calls...)
+}
+
+func setupPreStopManager(handler *mockedPreStopPlugin, timeout time.Duration, policy managerContracts.PreStopPolicy) (*Manager, func()) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{
+		Info: managerContracts.PluginInfo{
+			Name:           "plugin",
+			PreStopTimeout: timeout,
+			PreStopPolicy:  policy,
+		},
+		Handler: handler,
+	}
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+	return m, restore
+}
+
+func TestSoftStopRunsPreStopHookBeforeStop(t *testing.T) {
+	handler := &mockedPreStopPlugin{hookDuration: 10 * time.Millisecond}
+	m, restore := setupPreStopManager(handler, time.Second, "")
+	defer restore()
+
+	m.stopLongRunningPlugins(contracts.StopTypeSoftStop, SoftStopTimeout)
+
+	assert.Equal(t, []string{"PreStop", "Stop"}, handler.recorded())
+}
+
+func TestSoftStopProceedsAfterPreStopHookTimeout(t *testing.T) {
+	handler := &mockedPreStopPlugin{hookDuration: time.Minute}
+	m, restore := setupPreStopManager(handler, 50*time.Millisecond, managerContracts.PreStopPolicyProceed)
+	defer restore()
+
+	start := time.Now()
+	m.stopLongRunningPlugins(contracts.StopTypeSoftStop, SoftStopTimeout)
+
+	assert.True(t, time.Since(start) < SoftStopTimeout, "pre-stop hook wasn't bounded by its timeout")
+	assert.Equal(t, []string{"PreStop", "Stop"}, handler.recorded())
+}
+
+func TestPreStopHookTimeoutIsCappedByStopBudget(t *testing.T) {
+	handler := &mockedPreStopPlugin{hookDuration: time.Minute}
+	m, restore := setupPreStopManager(handler, time.Minute, "")
+	defer restore()
+
+	start := time.Now()
+	err := m.runPreStop(m.registeredPlugins["plugin"], 50*time.Millisecond)
+
+	assert.Equal(t, ErrPreStopTimeout, err)
+	assert.True(t, time.Since(start) < time.Second, "pre-stop hook wasn't bounded by the stop budget")
+}
+
+func TestFailingPreStopHookBlocksStop(t *testing.T) {
+	handler := &mockedPreStopPlugin{hookErr: errors.New("flush failed")}
+	m, restore := setupPreStopManager(handler, time.Second, managerContracts.PreStopPolicyBlock)
+	defer restore()
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	err := m.StopPlugin("plugin", task.NewChanneledCancelFlag())
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"PreStop"}, handler.recorded())
+	assert.Contains(t, m.runningPlugins, "plugin")
+}
+
+func TestPreStopHookTimeoutBlocksStopWithBlockPolicy(t *testing.T) {
+	handler := &mockedPreStopPlugin{hookDuration: time.Minute}
+	m, restore := setupPreStopManager(handler, 50*time.Millisecond, managerContracts.PreStopPolicyBlock)
+	defer restore()
+
+	m.stopLongRunningPlugins(contracts.StopTypeSoftStop, SoftStopTimeout)
+
+	assert.Equal(t, []string{"PreStop"}, handler.recorded())
+}
@@ -0,0 +1,83 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/times"
+)
+
+// restartLimiter is a token bucket limiting the restarts of long running plugins across all plugins.
+// The bucket holds up to one minute worth of restarts and refills continuously.
+type restartLimiter struct {
+	mu         sync.Mutex
+	clock      times.Clock
+	perMinute  int
+	tokens     float64
+	lastRefill time.Time
+}
+
+// newRestartLimiter creates a restart limiter allowing perMinute restarts per minute, a value <= 0 disables limiting
+func newRestartLimiter(clock times.Clock, perMinute int) *restartLimiter {
+	return &restartLimiter{
+		clock:      clock,
+		perMinute:  perMinute,
+		tokens:     float64(perMinute),
+		lastRefill: clock.Now(),
+	}
+}
+
+// allow takes a token from the bucket and returns false if there was none left
+func (l *restartLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perMinute <= 0 {
+		return true
+	}
+
+	now := l.clock.Now()
+	// ignore backward clock steps, they would drain the bucket
+	if elapsed := now.Sub(l.lastRefill); elapsed > 0 {
+		l.tokens += elapsed.Minutes() * float64(l.perMinute)
+		if l.tokens > float64(l.perMinute) {
+			l.tokens = float64(l.perMinute)
+		}
+	}
+	l.lastRefill = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// setRate changes the restarts allowed per minute, a value <= 0 disables limiting. The bucket starts full again.
+func (l *restartLimiter) setRate(perMinute int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.perMinute = perMinute
+	l.tokens = float64(perMinute)
+	l.lastRefill = l.clock.Now()
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestartLimiter(t *testing.T) {
+	clock := &times.MockedClock{}
+	now := time.Now()
+	clock.On("Now").Return(now).Once()
+	limiter := newRestartLimiter(clock, 2)
+
+	clock.On("Now").Return(now).Times(3)
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow())
+
+	// half a minute refills a single token
+	clock.On("Now").Return(now.Add(30 * time.Second)).Times(2)
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow())
+
+	// the bucket never holds more than a minute worth of restarts
+	clock.On("Now").Return(now.Add(time.Hour)).Times(3)
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow())
+}
+
+func TestRestartLimiterDisabled(t *testing.T) {
+	limiter := newRestartLimiter(times.DefaultClock, 0)
+	for i := 0; i < 100; i++ {
+		assert.True(t, limiter.allow())
+	}
+}
+
+func TestEnsurePluginsAreRunningPacesRestarts(t *testing.T) {
+	plugins := map[string]*mockedPlugin{}
+	for i := 0; i < 5; i++ {
+		plugins[fmt.Sprintf("plugin%v", i)] = &mockedPlugin{}
+	}
+	m, _, restore := setupTestManager(plugins)
+	defer restore()
+
+	for name, handler := range plugins {
+		handler.On("IsRunning", mock.Anything).Return(false)
+		m.runningPlugins[name] = managerContracts.PluginInfo{Name: name, State: managerContracts.PluginState{IsEnabled: true}}
+	}
+	p := m.registeredPlugins["plugin3"]
+	p.Info.Priority = 10
+	m.registeredPlugins["plugin3"] = p
+
+	clock := &times.MockedClock{}
+	clock.On("Now").Return(time.Now())
+	m.clock = clock
+	m.config.MaxRestartsPerMinute = 2
+	m.restartLimiter = newRestartLimiter(clock, m.config.MaxRestartsPerMinute)
+
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.startPlugin = pool
+
+	m.ensurePluginsAreRunning()
+
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+	pool.AssertCalled(t, "Submit", mock.Anything, "plugin3", mock.Anything)
+	pool.AssertCalled(t, "Submit", mock.Anything, "plugin0", mock.Anything)
+
+	// deferred plugins are retried by the next pass, within the limit
+	m.ensurePluginsAreRunning()
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+
+	// a minute later the bucket is refilled, the restarted plugins are running by now
+	m.restartLimiter.lastRefill = m.restartLimiter.lastRefill.Add(-time.Minute)
+	for _, name := range []string{"plugin0", "plugin3"} {
+		plugins[name].ExpectedCalls = nil
+		plugins[name].On("IsRunning", mock.Anything).Return(true)
+	}
+	pool.ExpectedCalls = nil
+	pool.Calls = nil
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.ensurePluginsAreRunning()
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+	pool.AssertCalled(t, "Submit", mock.Anything, "plugin1", mock.Anything)
+	pool.AssertCalled(t, "Submit", mock.Anything, "plugin2", mock.Anything)
+}
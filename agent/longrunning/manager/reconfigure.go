@@ -0,0 +1,114 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// Reconfigure applies a new configuration to the given running long running plugin. Plugins implementing
+// managerContracts.Reconfigurer apply it in place, the others get stopped and started with the new configuration.
+// The new configuration is persisted in the data store either way. Plugins implementing
+// managerContracts.ConfigValidator reject an invalid configuration before anything is stopped.
+func (m *Manager) Reconfigure(name, newConfiguration string) (err error) {
+	log := m.context.Log()
+
+	lock.RLock()
+	p, isRegisteredPlugin := m.registeredPlugins[name]
+	info, isRunningPlugin := m.runningPlugins[name]
+	lock.RUnlock()
+
+	if !isRegisteredPlugin {
+		return fmt.Errorf("unable to reconfigure %s since it's not even registered", name)
+	}
+	if !isRunningPlugin {
+		return fmt.Errorf("unable to reconfigure %s since it's not running", name)
+	}
+	if validator, ok := p.Handler.(managerContracts.ConfigValidator); ok {
+		if err = validator.ValidateConfig(newConfiguration); err != nil {
+			return fmt.Errorf("unable to reconfigure %s with an invalid configuration: %v", name, err)
+		}
+	}
+
+	var release func()
+	if release, err = m.AcquirePluginOperation(name, newConfiguration); err != nil {
+		return
+	}
+	defer release()
+
+	reconfigurer, canReconfigure := p.Handler.(managerContracts.Reconfigurer)
+	if !canReconfigure {
+		log.Infof("Long running plugin - %s can't be reconfigured in place, restarting it with the new configuration", name)
+		p.Info.OrchestrationDir = info.OrchestrationDir
+		return m.restartWithConfiguration(p, newConfiguration)
+	}
+
+	log.Infof("Reconfiguring long running plugin - %s", name)
+	configuration, err := expandPluginConfiguration(newConfiguration)
+	if err != nil {
+		return fmt.Errorf("unable to resolve configuration of %s: %v", name, err)
+	}
+	configuration, secrets, err := m.resolveSecrets(configuration)
+	if err != nil {
+		return fmt.Errorf("unable to resolve secrets in configuration of %s: %v", name, err)
+	}
+	if err = reconfigurer.Reconfigure(newRedactingContext(m.context, secrets), configuration, task.NewChanneledCancelFlag()); err != nil {
+		log.Errorf("Failed to reconfigure long running plugin - %s because of %s", name, err)
+		return
+	}
+	m.emit(EventReconfigured, name, "")
+
+	lock.Lock()
+	defer lock.Unlock()
+	info = m.runningPlugins[name]
+	info.Configuration = newConfiguration
+	info.State.LastConfigurationModifiedTime = time.Now()
+	m.runningPlugins[name] = info
+	p.Info.Configuration = newConfiguration
+	m.registeredPlugins[name] = p
+
+	if err = m.writeDataStore(); err != nil {
+		err = fmt.Errorf("Failed to persist info about %s in datastore because : %s", name, err.Error())
+		log.Errorf(err.Error())
+	}
+	return
+}
+
+// restartWithConfiguration stops the given long running plugin and starts it with the given configuration
+func (m *Manager) restartWithConfiguration(p managerContracts.Plugin, configuration string) (err error) {
+	log := m.context.Log()
+	name := p.Info.Name
+	if err = m.StopPlugin(name, task.NewChanneledCancelFlag()); err != nil {
+		return fmt.Errorf("unable to stop %s: %v", name, err)
+	}
+
+	orchestrationDir, err := m.orchestrationDirOf(p)
+	if err != nil {
+		return
+	}
+	ioConfig := contracts.IOConfiguration{
+		OrchestrationDirectory: orchestrationDir,
+		OutputS3BucketName:     "",
+		OutputS3KeyPrefix:      "",
+	}
+	out := newPluginIOHandler(log, ioConfig, name)
+	defer out.Close(log)
+	return m.StartPlugin(name, configuration, orchestrationDir, task.NewChanneledCancelFlag(), out)
+}
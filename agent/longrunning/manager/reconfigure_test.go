@@ -0,0 +1,113 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedReconfigurePlugin is a long running plugin implementing managerContracts.Reconfigurer
+type mockedReconfigurePlugin struct {
+	mockedPlugin
+}
+
+func (m *mockedReconfigurePlugin) Reconfigure(context context.T, configuration string, cancelFlag task.CancelFlag) error {
+	args := m.Called(context, configuration, cancelFlag)
+	return args.Error(0)
+}
+
+// setupReconfigureManager returns a manager running the given plugin with the configuration "old"
+func setupReconfigureManager(handler managerContracts.LongRunningPlugin) (*Manager, *mockedDataStore, func()) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	m.registeredPlugins["plugin"] = managerContracts.Plugin{
+		Info:    managerContracts.PluginInfo{Name: "plugin", Configuration: "old"},
+		Handler: handler,
+	}
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", Configuration: "old", State: managerContracts.PluginState{IsEnabled: true}}
+	return m, ds, restore
+}
+
+func TestReconfigureAppliesConfigurationInPlace(t *testing.T) {
+	handler := &mockedReconfigurePlugin{}
+	m, ds, restore := setupReconfigureManager(handler)
+	defer restore()
+	handler.On("Reconfigure", mock.Anything, "new", mock.Anything).Return(nil)
+	ds.On("Write", mock.Anything).Return(nil)
+
+	assert.NoError(t, m.Reconfigure("plugin", "new"))
+
+	handler.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything)
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, "new", m.runningPlugins["plugin"].Configuration)
+	assert.True(t, m.runningPlugins["plugin"].State.IsEnabled)
+	assert.Equal(t, "new", m.registeredPlugins["plugin"].Info.Configuration)
+	ds.AssertCalled(t, "Write", m.runningPlugins)
+}
+
+func TestFailedReconfigureKeepsConfiguration(t *testing.T) {
+	handler := &mockedReconfigurePlugin{}
+	m, ds, restore := setupReconfigureManager(handler)
+	defer restore()
+	handler.On("Reconfigure", mock.Anything, "new", mock.Anything).Return(errors.New("invalid configuration"))
+
+	assert.Error(t, m.Reconfigure("plugin", "new"))
+
+	assert.Equal(t, "old", m.runningPlugins["plugin"].Configuration)
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+}
+
+func TestReconfigureFallsBackToStopAndStart(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupReconfigureManager(handler)
+	defer restore()
+	handler.On("Stop", mock.Anything, mock.Anything).Return(errors.New("unable to stop"))
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	assert.Error(t, m.Reconfigure("plugin", "new"))
+
+	handler.AssertCalled(t, "Stop", mock.Anything, mock.Anything)
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, "old", m.runningPlugins["plugin"].Configuration)
+}
+
+func TestReconfigureRejectsPluginsThatAreNotRunning(t *testing.T) {
+	m, _, restore := setupReconfigureManager(&mockedReconfigurePlugin{})
+	defer restore()
+	delete(m.runningPlugins, "plugin")
+
+	assert.Error(t, m.Reconfigure("plugin", "new"))
+	assert.Error(t, m.Reconfigure("unknown", "new"))
+}
+
+func TestReconfigureRejectsInvalidConfiguration(t *testing.T) {
+	handler := &mockedValidatingPlugin{mockedPlugin: &mockedPlugin{}}
+	m, ds, restore := setupReconfigureManager(handler)
+	defer restore()
+	handler.On("ValidateConfig", "new").Return(errors.New("configuration isn't valid json")).Once()
+
+	assert.Error(t, m.Reconfigure("plugin", "new"))
+
+	handler.AssertExpectations(t)
+	handler.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything)
+	assert.Equal(t, "old", m.runningPlugins["plugin"].Configuration)
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+}
@@ -0,0 +1,61 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// resourceUsageTotals is the resource consumption of all running long running plugins combined
+type resourceUsageTotals struct {
+	cpuPercent float64
+	rssBytes   uint64
+	// nonReporting is the number of running plugins whose consumption isn't part of the totals
+	nonReporting int
+}
+
+// AggregateResourceUsage returns the CPU and memory consumed by all running long running plugins combined, as of the
+// latest health check. Plugins that don't report their resource usage are excluded, see Stats for their count.
+func (m *Manager) AggregateResourceUsage() (cpuPercent float64, rssBytes uint64) {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	return m.resourceUsage.cpuPercent, m.resourceUsage.rssBytes
+}
+
+// collectResourceUsage sums up the resource usage reported by the given running plugins
+func (m *Manager) collectResourceUsage(running []managerContracts.Plugin) {
+	log := m.context.Log()
+
+	var totals resourceUsageTotals
+	for _, p := range running {
+		reporter, ok := p.Handler.(managerContracts.ResourceReporter)
+		if !ok {
+			totals.nonReporting++
+			continue
+		}
+		usage, err := reporter.ResourceUsage(m.context)
+		if err != nil {
+			log.Debugf("Unable to get resource usage of %s - %v", p.Info.Name, err)
+			totals.nonReporting++
+			continue
+		}
+		totals.cpuPercent += usage.CPUPercent
+		totals.rssBytes += usage.RSSBytes
+	}
+
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	m.resourceUsage = totals
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockedReportingPlugin is a mocked long running plugin that reports its resource usage
+type mockedReportingPlugin struct {
+	*mockedPlugin
+}
+
+// ResourceUsage mocks reporting the resource usage
+func (p *mockedReportingPlugin) ResourceUsage(context context.T) (managerContracts.ResourceUsage, error) {
+	args := p.Called(context)
+	return args.Get(0).(managerContracts.ResourceUsage), args.Error(1)
+}
+
+func TestAggregateResourceUsage(t *testing.T) {
+	handlers := map[string]*mockedPlugin{"a": {}, "b": {}, "failing": {}, "silent": {}, "stopped": {}}
+	m, _, restore := setupTestManager(handlers)
+	defer restore()
+	for name, handler := range handlers {
+		m.runningPlugins[name] = managerContracts.PluginInfo{Name: name, State: managerContracts.PluginState{IsEnabled: true}}
+		handler.On("IsRunning", mock.Anything).Return(name != "stopped")
+		if name != "silent" {
+			p := m.registeredPlugins[name]
+			p.Handler = &mockedReportingPlugin{mockedPlugin: handler}
+			m.registeredPlugins[name] = p
+		}
+	}
+	handlers["a"].On("ResourceUsage", mock.Anything).Return(managerContracts.ResourceUsage{CPUPercent: 1.5, RSSBytes: 100}, nil)
+	handlers["b"].On("ResourceUsage", mock.Anything).Return(managerContracts.ResourceUsage{CPUPercent: 2.25, RSSBytes: 50}, nil)
+	handlers["failing"].On("ResourceUsage", mock.Anything).Return(managerContracts.ResourceUsage{}, fmt.Errorf("process gone"))
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, "stopped", mock.Anything).Return(nil)
+	m.startPlugin = pool
+
+	m.ensurePluginsAreRunning()
+
+	cpu, rss := m.AggregateResourceUsage()
+	assert.Equal(t, 3.75, cpu)
+	assert.Equal(t, uint64(150), rss)
+	stats := m.Stats()
+	assert.Equal(t, 3.75, stats.CPUPercent)
+	assert.Equal(t, uint64(150), stats.RSSBytes)
+	assert.Equal(t, 2, stats.NonReportingPlugins)
+	handlers["stopped"].AssertNotCalled(t, "ResourceUsage", mock.Anything)
+}
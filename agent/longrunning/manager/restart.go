@@ -0,0 +1,94 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// restartStopCheckInterval is how often Restart checks whether a stopped plugin is still running
+var restartStopCheckInterval = 500 * time.Millisecond
+
+// Restart stops the given long running plugin through the stop pool, waits until it actually stopped and then
+// submits its start with the stored configuration to the start pool. The plugin stays configured in the meantime,
+// so a health check that runs during the restart starts it the same way. Returns an error if the plugin isn't
+// registered or isn't running, or if it didn't stop within the soft stop timeout.
+func (m *Manager) Restart(pluginName string) (err error) {
+	log := m.context.Log()
+
+	lock.RLock()
+	p, isRegisteredPlugin := m.registeredPlugins[pluginName]
+	info, isRunningPlugin := m.runningPlugins[pluginName]
+	lock.RUnlock()
+
+	if !isRegisteredPlugin {
+		return fmt.Errorf("unable to restart %s since it's not even registered", pluginName)
+	}
+	if !isRunningPlugin {
+		return fmt.Errorf("unable to restart %s since it's not running", pluginName)
+	}
+	p.Info.Configuration = info.Configuration
+	p.Info.State = info.State
+
+	var release func()
+	if release, err = m.AcquirePluginOperation(pluginName, info.Configuration); err != nil {
+		return
+	}
+	defer release()
+
+	log.Infof("Restarting long running plugin - %s", pluginName)
+	timeout := m.stopTimeout(contracts.StopTypeSoftStop)
+	stopped := make(chan error, 1)
+	if err = m.stopPlugin.Submit(log, pluginName, func(cancelFlag task.CancelFlag) {
+		stopped <- m.stopPluginHandler(p, cancelFlag, timeout)
+	}); err != nil {
+		return fmt.Errorf("unable to stop %s: %v", pluginName, err)
+	}
+
+	deadline := m.clock.After(timeout)
+	select {
+	case err = <-stopped:
+	case <-deadline:
+		return fmt.Errorf("%s didn't stop within %v", pluginName, timeout)
+	}
+	// stopping may fail if the plugin exited on its own already - only a plugin that is still running blocks the restart
+	for p.Handler.IsRunning(m.context) {
+		if err != nil {
+			return fmt.Errorf("unable to stop %s: %v", pluginName, err)
+		}
+		select {
+		case <-time.After(restartStopCheckInterval):
+		case <-deadline:
+			return fmt.Errorf("%s didn't stop within %v", pluginName, timeout)
+		}
+	}
+	m.emit(EventStopped, pluginName, "restart")
+
+	if err = m.startPlugin.Submit(log, pluginName, func(cancelFlag task.CancelFlag) {
+		if err := m.startPluginOnce(p, cancelFlag); err != nil {
+			log.Errorf("Failed to start long running plugin - %s after its restart because of %s", pluginName, err)
+			return
+		}
+		m.persistRestarted(pluginName)
+		m.emit(EventRestarted, pluginName, "")
+	}); err != nil {
+		return fmt.Errorf("unable to start %s: %v", pluginName, err)
+	}
+	return nil
+}
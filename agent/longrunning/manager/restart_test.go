@@ -0,0 +1,83 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newRunningPool returns a mocked pool that runs submitted jobs right away
+func newRunningPool() *task.MockedPool {
+	pool := &task.MockedPool{}
+	pool.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(2).(task.Job)(task.NewChanneledCancelFlag())
+	})
+	return pool
+}
+
+func TestRestartStopsAndStartsPluginWithStoredConfiguration(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil)
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", Configuration: "stored", State: managerContracts.PluginState{IsEnabled: true}}
+	m.stopPlugin = newRunningPool()
+	m.startPlugin = newRunningPool()
+
+	var calls []string
+	handler.On("Stop", mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "Stop") })
+	handler.On("IsRunning", mock.Anything).Return(false)
+	handler.On("Start", mock.Anything, "stored", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "Start") })
+
+	assert.NoError(t, m.Restart("plugin"))
+
+	assert.Equal(t, []string{"Stop", "Start"}, calls)
+	assert.Contains(t, m.runningPlugins, "plugin")
+	m.stopPlugin.(*task.MockedPool).AssertCalled(t, "Submit", mock.Anything, "plugin", mock.Anything)
+	m.startPlugin.(*task.MockedPool).AssertCalled(t, "Submit", mock.Anything, "plugin", mock.Anything)
+}
+
+func TestRestartFailsIfPluginDoesNotStop(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	m.config.SoftStopTimeout = 100 * time.Millisecond
+	restartStopCheckInterval = 10 * time.Millisecond
+	defer func() { restartStopCheckInterval = 500 * time.Millisecond }()
+	m.stopPlugin = newRunningPool()
+	startPool := &task.MockedPool{}
+	m.startPlugin = startPool
+
+	handler.On("Stop", mock.Anything, mock.Anything).Return(nil)
+	handler.On("IsRunning", mock.Anything).Return(true)
+
+	assert.Error(t, m.Restart("plugin"))
+	startPool.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestartRejectsPluginsThatAreNotRunning(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+
+	assert.Error(t, m.Restart("unknown"))
+	assert.Error(t, m.Restart("plugin"))
+}
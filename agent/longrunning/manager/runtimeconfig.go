@@ -0,0 +1,165 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// ImmutableConfigError is returned when a configuration update changes settings that can't be changed at runtime
+type ImmutableConfigError struct {
+	Settings []string
+}
+
+// Error lists the settings that require a restart
+func (e *ImmutableConfigError) Error() string {
+	return fmt.Sprintf("settings %s can't be changed at runtime - they require a restart of the agent", strings.Join(e.Settings, ", "))
+}
+
+// GetConfig returns the current settings of the manager
+func (m *Manager) GetConfig() ManagerConfig {
+	m.configLock.RLock()
+	defer m.configLock.RUnlock()
+	return m.config
+}
+
+// UpdateConfig applies new settings to the running manager and persists them so that they survive a restart.
+// The health check gets rescheduled if its poll frequency changed. Settings that require a restart (the number of
+// workers and the maximum data store size) can't be changed, an *ImmutableConfigError lists them if they differ.
+func (m *Manager) UpdateConfig(config ManagerConfig) error {
+	log := m.context.Log()
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+
+	current := m.config
+	if changed := immutableChanges(current, config); len(changed) > 0 {
+		return &ImmutableConfigError{Settings: changed}
+	}
+
+	m.config = config
+	if config.MaxRestartsPerMinute != current.MaxRestartsPerMinute {
+		m.restartLimiter.setRate(config.MaxRestartsPerMinute)
+	}
+	if config.PollFrequency != current.PollFrequency && m.isExecuting() {
+		if err := m.rescheduleHealthCheck(config.PollFrequency); err != nil {
+			log.Errorf("Unable to reschedule the health check of long running plugins - %v", err)
+			m.config.PollFrequency = current.PollFrequency
+			return err
+		}
+	}
+	log.Infof("Updated the settings of the long running plugin manager to %+v", config)
+
+	if err := writeManagerConfig(config); err != nil {
+		log.Errorf("Failed to persist the settings of the long running plugin manager - because of %s", err)
+	}
+	return nil
+}
+
+// validateConfig checks the settings of the manager for invalid values
+func validateConfig(config ManagerConfig) error {
+	if config.ConflictPolicy != ConflictPolicyQueue && config.ConflictPolicy != ConflictPolicyReject {
+		return fmt.Errorf("unsupported conflict policy %v", config.ConflictPolicy)
+	}
+	if config.PollFrequency < time.Second {
+		return fmt.Errorf("poll frequency %v is below the minimum of %v", config.PollFrequency, time.Second)
+	}
+	if config.SoftStopTimeout <= 0 || config.HardStopTimeout <= 0 {
+		return fmt.Errorf("stop timeouts must be positive")
+	}
+	if config.MaxRestartsPerMinute < 0 || config.MinFreeDiskBytes < 0 || config.MaxDataStoreBytes < 0 || config.StableUptime < 0 ||
+		config.RestartBackoff < 0 || config.MaxRestartBackoff < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if config.StartWorkers <= 0 || config.StopWorkers <= 0 {
+		return fmt.Errorf("number of workers must be positive")
+	}
+	return nil
+}
+
+// immutableChanges returns the names of the settings that differ and require a restart to change
+func immutableChanges(current, config ManagerConfig) (changed []string) {
+	if config.StartWorkers != current.StartWorkers {
+		changed = append(changed, "StartWorkers")
+	}
+	if config.StopWorkers != current.StopWorkers {
+		changed = append(changed, "StopWorkers")
+	}
+	if config.MaxDataStoreBytes != current.MaxDataStoreBytes {
+		changed = append(changed, "MaxDataStoreBytes")
+	}
+	return
+}
+
+// rescheduleHealthCheck replaces the scheduled health check and its watchdog by ones with the given poll frequency.
+// Callers are expected to hold configLock.
+func (m *Manager) rescheduleHealthCheck(pollFrequency time.Duration) error {
+	if pollFrequency <= 0 {
+		return fmt.Errorf("poll frequency must be positive")
+	}
+	if m.managingLifeCycleJob != nil {
+		m.managingLifeCycleJob.Stop()
+	}
+	m.managingLifeCycleJob = m.scheduleHealthCheck(pollFrequency, false)
+	m.stopHealthCheckWatchdog()
+	m.startHealthCheckWatchdog(pollFrequency)
+	return nil
+}
+
+// stopTimeout returns the time the manager waits for long running plugins to stop
+func (m *Manager) stopTimeout(stopType contracts.StopType) time.Duration {
+	config := m.GetConfig()
+	if stopType == contracts.StopTypeSoftStop {
+		return config.SoftStopTimeout
+	}
+	return config.HardStopTimeout
+}
+
+// loadManagerConfig returns the default settings of the manager overridden by the agent configuration and then by
+// the settings persisted at runtime. Persisted settings that can't be changed at runtime are ignored.
+func loadManagerConfig(log log.T, appConfig appconfig.SsmagentConfig) ManagerConfig {
+	defaults := DefaultManagerConfig()
+	//the agent configuration is validated when it's loaded, out of range values are replaced by the defaults
+	defaults.PollFrequency = time.Duration(appConfig.Ssm.LongRunningPluginsPollFrequencyMinutes) * time.Minute
+	defaults.StartWorkers = appConfig.Ssm.LongRunningPluginStartWorkers
+	defaults.StopWorkers = appConfig.Ssm.LongRunningPluginStopWorkers
+	persisted, found, err := readManagerConfig()
+	if err != nil {
+		log.Warnf("Unable to read the persisted settings of the long running plugin manager, using the defaults - %v", err)
+		return defaults
+	}
+	if !found {
+		return defaults
+	}
+
+	persisted.StartWorkers = defaults.StartWorkers
+	persisted.StopWorkers = defaults.StopWorkers
+	persisted.MaxDataStoreBytes = defaults.MaxDataStoreBytes
+	if err = validateConfig(persisted); err != nil {
+		log.Warnf("Ignoring invalid persisted settings of the long running plugin manager - %v", err)
+		return defaults
+	}
+	log.Infof("Using the persisted settings of the long running plugin manager %+v", persisted)
+	return persisted
+}
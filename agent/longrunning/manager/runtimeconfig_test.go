@@ -0,0 +1,150 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubManagerConfigStore keeps the persisted settings in memory, the returned function restores the real store
+func stubManagerConfigStore(persisted *[]ManagerConfig) func() {
+	original := writeManagerConfig
+	writeManagerConfig = func(config ManagerConfig) error {
+		*persisted = append(*persisted, config)
+		return nil
+	}
+	return func() { writeManagerConfig = original }
+}
+
+func TestUpdateConfigAppliesPollFrequencyLive(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	var persisted []ManagerConfig
+	defer stubManagerConfigStore(&persisted)()
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+
+	var checks int32
+	handler.On("IsRunning", mock.Anything).Return(true).Run(func(mock.Arguments) {
+		atomic.AddInt32(&checks, 1)
+	})
+
+	m.setRunning(true)
+	assert.NoError(t, m.rescheduleHealthCheck(time.Hour))
+	defer m.stopLifeCycleManagementJob()
+
+	config := m.GetConfig()
+	config.PollFrequency = time.Second
+	assert.NoError(t, m.UpdateConfig(config))
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.True(t, atomic.LoadInt32(&checks) >= 1, "health check didn't run at the new poll frequency")
+	assert.Equal(t, time.Second, m.GetConfig().PollFrequency)
+	assert.Equal(t, []ManagerConfig{config}, persisted)
+}
+
+func TestUpdateConfigRejectsImmutableSettings(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	var persisted []ManagerConfig
+	defer stubManagerConfigStore(&persisted)()
+
+	config := m.GetConfig()
+	config.StartWorkers++
+	config.MaxDataStoreBytes *= 2
+	config.SoftStopTimeout = time.Minute
+	err := m.UpdateConfig(config)
+
+	immutableErr, isImmutable := err.(*ImmutableConfigError)
+	assert.True(t, isImmutable, "unexpected error %v", err)
+	assert.Equal(t, []string{"StartWorkers", "MaxDataStoreBytes"}, immutableErr.Settings)
+	assert.Equal(t, DefaultManagerConfig(), m.GetConfig())
+	assert.Empty(t, persisted)
+}
+
+func TestUpdateConfigRejectsInvalidSettings(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	var persisted []ManagerConfig
+	defer stubManagerConfigStore(&persisted)()
+
+	config := m.GetConfig()
+	config.PollFrequency = 0
+
+	assert.Error(t, m.UpdateConfig(config))
+	assert.Equal(t, DefaultManagerConfig(), m.GetConfig())
+}
+
+func TestLoadManagerConfigKeepsImmutableDefaults(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	original := readManagerConfig
+	defer func() { readManagerConfig = original }()
+
+	persisted := DefaultManagerConfig()
+	persisted.PollFrequency = time.Minute
+	persisted.StartWorkers = 100
+	readManagerConfig = func() (ManagerConfig, bool, error) {
+		return persisted, true, nil
+	}
+
+	config := loadManagerConfig(m.context.Log(), appconfig.DefaultConfig())
+
+	assert.Equal(t, time.Minute, config.PollFrequency)
+	assert.Equal(t, NumberOfLongRunningPluginWorkers, config.StartWorkers)
+}
+
+func TestLoadManagerConfigUsesConfiguredPollFrequency(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	original := readManagerConfig
+	defer func() { readManagerConfig = original }()
+	readManagerConfig = func() (ManagerConfig, bool, error) {
+		return ManagerConfig{}, false, nil
+	}
+
+	appConfig := appconfig.DefaultConfig()
+	assert.Equal(t, PollFrequencyMinutes*time.Minute, loadManagerConfig(m.context.Log(), appConfig).PollFrequency)
+
+	appConfig.Ssm.LongRunningPluginsPollFrequencyMinutes = 1
+	assert.Equal(t, time.Minute, loadManagerConfig(m.context.Log(), appConfig).PollFrequency)
+}
+
+func TestLoadManagerConfigUsesConfiguredWorkers(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	original := readManagerConfig
+	defer func() { readManagerConfig = original }()
+	readManagerConfig = func() (ManagerConfig, bool, error) {
+		return ManagerConfig{}, false, nil
+	}
+
+	appConfig := appconfig.DefaultConfig()
+	config := loadManagerConfig(m.context.Log(), appConfig)
+	assert.Equal(t, NumberOfLongRunningPluginWorkers, config.StartWorkers)
+	assert.Equal(t, NumberOfCancelWorkers, config.StopWorkers)
+
+	appConfig.Ssm.LongRunningPluginStartWorkers = 20
+	appConfig.Ssm.LongRunningPluginStopWorkers = 1
+	config = loadManagerConfig(m.context.Log(), appConfig)
+	assert.Equal(t, 20, config.StartWorkers)
+	assert.Equal(t, 1, config.StopWorkers)
+}
@@ -0,0 +1,217 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/ssm"
+)
+
+// secretReference matches references to secrets in configurations of long running plugins - secret://<provider>/<name>
+var secretReference = regexp.MustCompile(`secret://([A-Za-z0-9]+)/([A-Za-z0-9_.\-/]+)`)
+
+// redactedSecret replaces resolved secrets in logs
+const redactedSecret = "********"
+
+// SecretProvider resolves secrets referenced by configurations of long running plugins
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+// envSecretProvider resolves secrets from environment variables of the agent
+type envSecretProvider struct{}
+
+// Resolve returns the value of the environment variable with the given name
+func (envSecretProvider) Resolve(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s isn't set", ref)
+	}
+	return value, nil
+}
+
+// parameterStoreSecretProvider resolves secrets from SSM Parameter Store SecureString parameters
+type parameterStoreSecretProvider struct {
+	log log.T
+}
+
+// Resolve returns the decrypted value of the parameter with the given name
+func (p *parameterStoreSecretProvider) Resolve(ref string) (string, error) {
+	response, err := ssm.NewService().GetDecryptedParameters(p.log, []string{ref})
+	if err != nil {
+		return "", err
+	}
+	if len(response.Parameters) == 0 || response.Parameters[0].Value == nil {
+		return "", fmt.Errorf("parameter %s doesn't exist", ref)
+	}
+	return *response.Parameters[0].Value, nil
+}
+
+// defaultSecretProviders returns the secret providers shipped with the agent, keyed by the provider of secret references
+func defaultSecretProviders(log log.T) map[string]SecretProvider {
+	return map[string]SecretProvider{
+		"env": envSecretProvider{},
+		"ssm": &parameterStoreSecretProvider{log: log},
+	}
+}
+
+// RegisterSecretProvider registers a provider resolving secret references of the form secret://<name>/<ref>
+func (m *Manager) RegisterSecretProvider(name string, provider SecretProvider) {
+	m.secretProvidersLock.Lock()
+	defer m.secretProvidersLock.Unlock()
+	if m.secretProviders == nil {
+		m.secretProviders = map[string]SecretProvider{}
+	}
+	m.secretProviders[name] = provider
+}
+
+// resolveSecrets replaces the secret references in a configuration with their values. The resolved configuration
+// must only be handed to the plugin - it's never persisted. The resolved values are returned to allow redacting them.
+func (m *Manager) resolveSecrets(configuration string) (resolved string, secrets []string, err error) {
+	if !strings.Contains(configuration, "secret://") {
+		return configuration, nil, nil
+	}
+
+	//secrets end up in json string literals, so they need to be escaped
+	isJSON := json.Valid([]byte(configuration))
+
+	m.secretProvidersLock.Lock()
+	defer m.secretProvidersLock.Unlock()
+
+	resolved = secretReference.ReplaceAllStringFunc(configuration, func(reference string) string {
+		if err != nil {
+			return reference
+		}
+		match := secretReference.FindStringSubmatch(reference)
+		provider, ok := m.secretProviders[match[1]]
+		if !ok {
+			err = fmt.Errorf("unknown secret provider %s in %s", match[1], reference)
+			return reference
+		}
+		value, resolveErr := provider.Resolve(match[2])
+		if resolveErr != nil {
+			err = fmt.Errorf("unable to resolve secret %s: %v", reference, resolveErr)
+			return reference
+		}
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+		if isJSON {
+			escaped, _ := json.Marshal(value)
+			return string(escaped[1 : len(escaped)-1])
+		}
+		return value
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return resolved, secrets, nil
+}
+
+// redactingContext is a context whose logger redacts the given secrets
+type redactingContext struct {
+	context.T
+	secrets []string
+}
+
+// newRedactingContext returns a context that redacts the given secrets from everything it logs
+func newRedactingContext(context context.T, secrets []string) context.T {
+	if len(secrets) == 0 {
+		return context
+	}
+	return &redactingContext{T: context, secrets: secrets}
+}
+
+// Log returns the redacting logger of the context
+func (c *redactingContext) Log() log.T {
+	return &redactingLogger{T: c.T.Log(), secrets: c.secrets}
+}
+
+// With returns a redacting context with the given log context
+func (c *redactingContext) With(logContext string) context.T {
+	return &redactingContext{T: c.T.With(logContext), secrets: c.secrets}
+}
+
+// redactingLogger is a logger that redacts the given secrets
+type redactingLogger struct {
+	log.T
+	secrets []string
+}
+
+func (l *redactingLogger) redact(message string) string {
+	for _, secret := range l.secrets {
+		message = strings.Replace(message, secret, redactedSecret, -1)
+	}
+	return message
+}
+
+func (l *redactingLogger) WithContext(context ...string) log.T {
+	return &redactingLogger{T: l.T.WithContext(context...), secrets: l.secrets}
+}
+
+func (l *redactingLogger) Tracef(format string, params ...interface{}) {
+	l.T.Trace(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Debugf(format string, params ...interface{}) {
+	l.T.Debug(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Infof(format string, params ...interface{}) {
+	l.T.Info(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Warnf(format string, params ...interface{}) error {
+	return l.T.Warn(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Errorf(format string, params ...interface{}) error {
+	return l.T.Error(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Criticalf(format string, params ...interface{}) error {
+	return l.T.Critical(l.redact(fmt.Sprintf(format, params...)))
+}
+
+func (l *redactingLogger) Trace(v ...interface{}) {
+	l.T.Trace(l.redact(fmt.Sprint(v...)))
+}
+
+func (l *redactingLogger) Debug(v ...interface{}) {
+	l.T.Debug(l.redact(fmt.Sprint(v...)))
+}
+
+func (l *redactingLogger) Info(v ...interface{}) {
+	l.T.Info(l.redact(fmt.Sprint(v...)))
+}
+
+func (l *redactingLogger) Warn(v ...interface{}) error {
+	return l.T.Warn(l.redact(fmt.Sprint(v...)))
+}
+
+func (l *redactingLogger) Error(v ...interface{}) error {
+	return l.T.Error(l.redact(fmt.Sprint(v...)))
+}
+
+func (l *redactingLogger) Critical(v ...interface{}) error {
+	return l.T.Critical(l.redact(fmt.Sprint(v...)))
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeSecretProvider resolves secrets from a map
+type fakeSecretProvider map[string]string
+
+// Resolve returns the secret with the given name
+func (p fakeSecretProvider) Resolve(ref string) (string, error) {
+	if value, ok := p[ref]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("secret %s not found", ref)
+}
+
+func TestResolveSecrets(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.RegisterSecretProvider("fake", fakeSecretProvider{"token": `s3"cr3t`})
+
+	resolved, secrets, err := m.resolveSecrets(`{"Token": "secret://fake/token", "Region": "us-east-1"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Token": "s3\"cr3t", "Region": "us-east-1"}`, resolved)
+	assert.Equal(t, []string{`s3"cr3t`}, secrets)
+
+	resolved, _, err = m.resolveSecrets(`-token secret://fake/token`)
+	assert.NoError(t, err)
+	assert.Equal(t, `-token s3"cr3t`, resolved)
+
+	_, _, err = m.resolveSecrets(`{"Token": "secret://fake/missing"}`)
+	assert.Error(t, err)
+
+	_, _, err = m.resolveSecrets(`{"Token": "secret://unknown/token"}`)
+	assert.Error(t, err)
+}
+
+func TestResolveSecretsFromEnvironment(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.secretProviders = defaultSecretProviders(m.context.Log())
+	os.Setenv("LRPM_TEST_SECRET", "from-env")
+	defer os.Unsetenv("LRPM_TEST_SECRET")
+
+	resolved, _, err := m.resolveSecrets(`{"Token": "secret://env/LRPM_TEST_SECRET"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Token": "from-env"}`, resolved)
+}
+
+func TestStartResolvesSecretsWithoutPersistingThem(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.RegisterSecretProvider("fake", fakeSecretProvider{"token": "s3cr3t"})
+
+	p := m.registeredPlugins["plugin"]
+	p.Info.Configuration = `{"Token": "secret://fake/token"}`
+	p.Info.State = managerContracts.PluginState{IsEnabled: true}
+	m.runningPlugins["plugin"] = p.Info
+	handler.On("Start", mock.Anything, `{"Token": "s3cr3t"}`, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := m.startPluginWithDefaultIO(p, nil)
+	assert.NoError(t, err)
+	handler.AssertExpectations(t)
+
+	ds.On("Write", mock.Anything).Return(nil).Once()
+	m.writeDataStore()
+	persisted := ds.Calls[0].Arguments.Get(0).(map[string]managerContracts.PluginInfo)
+	assert.Equal(t, `{"Token": "secret://fake/token"}`, persisted["plugin"].Configuration)
+}
+
+func TestRedactingContext(t *testing.T) {
+	logger := log.NewMockLog()
+	logger.On("Info", mock.Anything).Return()
+	ctx := &context.Mock{}
+	ctx.On("Log").Return(logger)
+
+	redacting := newRedactingContext(ctx, []string{"s3cr3t"})
+	redacting.Log().Infof("configuration %s", `{"Token": "s3cr3t"}`)
+
+	logger.AssertCalled(t, "Info", []interface{}{`configuration {"Token": "********"}`})
+	assert.Equal(t, ctx, newRedactingContext(ctx, nil))
+}
@@ -0,0 +1,144 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// SnapshotVersion is the version of the snapshot format produced by SnapshotState
+const SnapshotVersion = 1
+
+// PluginSnapshot is the portable state of a single long running plugin.
+// Values that are specific to an instance (orchestration directories, timestamps) are not part of the snapshot
+// and are resolved again when the snapshot gets restored.
+type PluginSnapshot struct {
+	Name          string
+	Configuration string
+	IsEnabled     bool
+	Priority      int               `json:",omitempty"`
+	Tags          map[string]string `json:",omitempty"`
+}
+
+// StateSnapshot is the portable state of the long running plugin manager
+type StateSnapshot struct {
+	Version int
+	Plugins []PluginSnapshot
+}
+
+// SnapshotState captures the configured set of long running plugins into a portable, versioned snapshot
+func (m *Manager) SnapshotState() ([]byte, error) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	snapshot := StateSnapshot{
+		Version: SnapshotVersion,
+		Plugins: []PluginSnapshot{},
+	}
+	for name, info := range m.runningPlugins {
+		snapshot.Plugins = append(snapshot.Plugins, PluginSnapshot{
+			Name:          name,
+			Configuration: info.Configuration,
+			IsEnabled:     info.State.IsEnabled,
+			Priority:      info.Priority,
+			Tags:          info.Tags,
+		})
+	}
+	// keep the snapshot stable so that two snapshots of the same state are byte for byte identical
+	sort.Slice(snapshot.Plugins, func(i, j int) bool {
+		return snapshot.Plugins[i].Name < snapshot.Plugins[j].Name
+	})
+
+	return json.Marshal(snapshot)
+}
+
+// RestoreState restores the long running plugins captured by SnapshotState and persists them in the data store.
+// Plugins that aren't registered on this instance are skipped. If start is true, the restored plugins that are
+// enabled get started right away, otherwise they are left for the next Execute or health check to start.
+func (m *Manager) RestoreState(data []byte, start bool) error {
+	log := m.context.Log()
+
+	var snapshot StateSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("unable to parse lrpm state snapshot: %v", err)
+	}
+	if snapshot.Version <= 0 || snapshot.Version > SnapshotVersion {
+		return fmt.Errorf("unsupported lrpm state snapshot version %v, supported version is %v", snapshot.Version, SnapshotVersion)
+	}
+
+	lock.Lock()
+	var restored []managerContracts.Plugin
+	for _, ps := range snapshot.Plugins {
+		p, isRegistered := m.registeredPlugins[ps.Name]
+		if !isRegistered {
+			log.Warnf("Skipping %s while restoring lrpm state - plugin isn't registered", ps.Name)
+			continue
+		}
+		p.Info.Name = ps.Name
+		p.Info.Configuration = ps.Configuration
+		//snapshots without a priority or tags keep the ones of the registered plugin
+		if ps.Priority != 0 {
+			p.Info.Priority = ps.Priority
+		}
+		if len(ps.Tags) > 0 {
+			p.Info.Tags = ps.Tags
+		}
+		p.Info.State = managerContracts.PluginState{
+			LastConfigurationModifiedTime: time.Now(),
+			IsEnabled:                     ps.IsEnabled,
+		}
+		m.runningPlugins[ps.Name] = p.Info
+		m.registeredPlugins[ps.Name] = p
+		restored = append(restored, p)
+	}
+	log.Infof("Restored %v long running plugins from snapshot", len(restored))
+
+	err := m.writeDataStore()
+	//plugins are started without holding the lock so that health checks and queries aren't blocked by slow starts
+	lock.Unlock()
+	if err != nil {
+		log.Errorf("Failed to persist restored lrpm state - because of %s", err)
+		return err
+	}
+
+	if !start {
+		return nil
+	}
+
+	var startErrors []string
+	for _, p := range restored {
+		if !p.Info.State.IsEnabled {
+			continue
+		}
+		log.Infof("Starting restored long running plugin - %s", p.Info.Name)
+		if err := m.startPluginOnce(p, task.NewChanneledCancelFlag()); err != nil {
+			log.Errorf("Failed to start restored long running plugin - %s because of %s", p.Info.Name, err)
+			startErrors = append(startErrors, fmt.Sprintf("%s: %v", p.Info.Name, err))
+			continue
+		}
+		m.emit(EventStarted, p.Info.Name, "")
+	}
+	if len(startErrors) > 0 {
+		return fmt.Errorf("failed to start restored long running plugins - %s", strings.Join(startErrors, "; "))
+	}
+	return nil
+}
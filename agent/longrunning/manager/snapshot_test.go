@@ -0,0 +1,119 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSnapshotAndRestoreState(t *testing.T) {
+	source, _, restore := setupTestManager(map[string]*mockedPlugin{"pluginA": {}, "pluginB": {}})
+	source.runningPlugins["pluginA"] = managerContracts.PluginInfo{
+		Name:          "pluginA",
+		Configuration: "configA",
+		State:         managerContracts.PluginState{IsEnabled: true, LastConfigurationModifiedTime: time.Now()},
+		Priority:      10,
+		Tags:          map[string]string{"team": "metrics"},
+	}
+	source.runningPlugins["pluginB"] = managerContracts.PluginInfo{
+		Name:          "pluginB",
+		Configuration: "configB",
+		State:         managerContracts.PluginState{IsEnabled: false},
+	}
+	data, err := source.SnapshotState()
+	restore()
+	assert.Nil(t, err)
+
+	handlerA := &mockedPlugin{}
+	handlerB := &mockedPlugin{}
+	target, ds, restore := setupTestManager(map[string]*mockedPlugin{"pluginA": handlerA, "pluginB": handlerB})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil).Once()
+	handlerA.On("Start", mock.Anything, "configA", pluginOrchestrationDir(target.context, "pluginA"), mock.Anything, mock.Anything).Return(nil).Once()
+
+	err = target.RestoreState(data, true)
+
+	assert.Nil(t, err)
+	assert.Len(t, target.runningPlugins, 2)
+	assert.Equal(t, "configA", target.runningPlugins["pluginA"].Configuration)
+	assert.True(t, target.runningPlugins["pluginA"].State.IsEnabled)
+	assert.Equal(t, 10, target.runningPlugins["pluginA"].Priority)
+	assert.Equal(t, map[string]string{"team": "metrics"}, target.runningPlugins["pluginA"].Tags)
+	assert.Equal(t, 10, target.registeredPlugins["pluginA"].Info.Priority)
+	assert.Equal(t, "configB", target.runningPlugins["pluginB"].Configuration)
+	assert.False(t, target.runningPlugins["pluginB"].State.IsEnabled)
+	ds.AssertExpectations(t)
+	handlerA.AssertExpectations(t)
+	handlerB.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestoreStateWithoutStart(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"pluginA": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil).Once()
+
+	data := []byte(`{"Version":1,"Plugins":[{"Name":"pluginA","Configuration":"configA","IsEnabled":true},{"Name":"unknown","Configuration":"","IsEnabled":true}]}`)
+	err := m.RestoreState(data, false)
+
+	assert.Nil(t, err)
+	assert.Len(t, m.runningPlugins, 1)
+	assert.Equal(t, "configA", m.runningPlugins["pluginA"].Configuration)
+	handler.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestoreStateStartsPluginsWithoutHoldingLock(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{"pluginA": handler})
+	defer restore()
+	ds.On("Write", mock.Anything).Return(nil).Once()
+
+	queriedDuringStart := false
+	handler.On("Start", mock.Anything, "configA", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		queried := make(chan bool)
+		go func() {
+			_, isRunning := m.GetRunningPlugins()["pluginA"]
+			queried <- isRunning
+		}()
+		select {
+		case queriedDuringStart = <-queried:
+		case <-time.After(time.Second):
+		}
+	})
+
+	data := []byte(`{"Version":1,"Plugins":[{"Name":"pluginA","Configuration":"configA","IsEnabled":true}]}`)
+	err := m.RestoreState(data, true)
+
+	assert.Nil(t, err)
+	assert.True(t, queriedDuringStart, "running plugins should be readable while restored plugins start")
+	handler.AssertExpectations(t)
+}
+
+func TestRestoreStateRejectsUnsupportedVersion(t *testing.T) {
+	m, ds, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	err := m.RestoreState([]byte(`{"Version":2,"Plugins":[]}`), false)
+	assert.NotNil(t, err)
+
+	err = m.RestoreState([]byte(`{"Plugins":[]}`), false)
+	assert.NotNil(t, err)
+	ds.AssertNotCalled(t, "Write", mock.Anything)
+}
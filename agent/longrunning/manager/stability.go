@@ -0,0 +1,38 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"time"
+)
+
+// recordStarted records the start time of a long running plugin, its stability is assessed against it.
+// Callers are expected to hold statusLock.
+func (m *Manager) recordStarted(name string) {
+	if m.startedAt == nil {
+		m.startedAt = map[string]time.Time{}
+	}
+	m.startedAt[name] = m.clock.Now()
+}
+
+// isStable returns whether a long running plugin stayed up for the configured stable uptime since its latest start.
+// A plugin whose start wasn't observed by the manager is considered stable. Callers are expected to hold statusLock.
+func (m *Manager) isStable(name string) bool {
+	startedAt, isStarted := m.startedAt[name]
+	if !isStarted {
+		return true
+	}
+	return m.clock.Now().Sub(startedAt) >= m.GetConfig().StableUptime
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/stretchr/testify/assert"
+)
+
+// crashAfter starts a plugin, observes it running after the given uptime and then observes it crashed and restarts it
+func crashAfter(m *Manager, clock *times.MockedClock, start time.Time, uptime time.Duration) {
+	clock.On("Now").Return(start).Once()
+	m.recordStartResult("plugin", nil)
+
+	clock.On("Now").Return(start.Add(uptime)).Twice()
+	m.recordIsRunning("plugin", true)
+
+	m.recordIsRunning("plugin", false)
+	m.recordRestartAttempt("plugin")
+}
+
+func TestCrashBeforeStableUptimeKeepsRestartAttempts(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	clock := &times.MockedClock{}
+	m.clock = clock
+	m.config.StableUptime = 5 * time.Minute
+	m.restartAttempts = map[string]int{"plugin": 1}
+
+	crashAfter(m, clock, time.Now(), 5*time.Minute-time.Second)
+
+	assert.Equal(t, 2, m.restartAttempts["plugin"])
+}
+
+func TestCrashAfterStableUptimeResetsRestartAttempts(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	clock := &times.MockedClock{}
+	m.clock = clock
+	m.config.StableUptime = 5 * time.Minute
+	m.restartAttempts = map[string]int{"plugin": 1}
+
+	crashAfter(m, clock, time.Now(), 5*time.Minute+time.Second)
+
+	assert.Equal(t, 1, m.restartAttempts["plugin"])
+}
+
+func TestStableUptimeIsIndependentOfPollFrequency(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	clock := &times.MockedClock{}
+	m.clock = clock
+	m.config.StableUptime = PollFrequencyMinutes * time.Minute * 2
+	m.restartAttempts = map[string]int{"plugin": 1}
+
+	// surviving a full poll interval isn't enough
+	crashAfter(m, clock, time.Now(), PollFrequencyMinutes*time.Minute)
+
+	assert.Equal(t, 2, m.restartAttempts["plugin"])
+}
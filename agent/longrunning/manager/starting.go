@@ -0,0 +1,63 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// ErrStartInProgress is returned when a long running plugin is asked to start while a start of it is in progress
+var ErrStartInProgress = errors.New("start of the long running plugin is already in progress")
+
+// beginStart marks the given long running plugin as starting. Returns false if it's already starting, so that
+// no more than one start of a plugin runs at a time.
+func (m *Manager) beginStart(name string) bool {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.starting[name] {
+		return false
+	}
+	if m.starting == nil {
+		m.starting = map[string]bool{}
+	}
+	m.starting[name] = true
+	return true
+}
+
+// isStarting returns whether a start of the given long running plugin is in progress
+func (m *Manager) isStarting(name string) bool {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	return m.starting[name]
+}
+
+// endStart clears the starting state of the given long running plugin once its start returned
+func (m *Manager) endStart(name string) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	delete(m.starting, name)
+}
+
+// startPluginOnce starts the given long running plugin with the default IO unless a start of it is in progress
+func (m *Manager) startPluginOnce(p managerContracts.Plugin, cancelFlag task.CancelFlag) error {
+	if !m.beginStart(p.Info.Name) {
+		return ErrStartInProgress
+	}
+	defer m.endStart(p.Info.Name)
+	return m.startPluginWithDefaultIO(p, cancelFlag)
+}
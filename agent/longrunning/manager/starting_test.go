@@ -0,0 +1,59 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConcurrentStartsOfPluginStartItOnce(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	pool := newRunningPool()
+	pool.On("HasJob", mock.Anything).Return(false)
+	m.startPlugin = pool
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler.On("IsRunning", mock.Anything).Return(false)
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		close(started)
+		<-release
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- m.startPluginOnce(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag())
+	}()
+	<-started
+
+	// a start that races with the slow start is skipped, no matter where it comes from
+	m.ensurePluginsAreRunning()
+	assert.Equal(t, ErrStartInProgress, m.startPluginOnce(m.registeredPlugins["plugin"], task.NewChanneledCancelFlag()))
+	assert.True(t, m.GetRunningPlugins()["plugin"].Starting)
+
+	close(release)
+	assert.NoError(t, <-done)
+	handler.AssertNumberOfCalls(t, "Start", 1)
+	pool.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+	assert.False(t, m.GetRunningPlugins()["plugin"].Starting)
+}
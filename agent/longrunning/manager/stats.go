@@ -0,0 +1,59 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+// Stats reflects the operational state of the long running plugin manager
+type Stats struct {
+	// RegisteredPlugins is the number of long running plugins known to the agent
+	RegisteredPlugins int
+
+	// ConfiguredPlugins is the number of long running plugins enabled by documents
+	ConfiguredPlugins int
+
+	// ReadOnly is true while the manager skips persisting plugins because of disk pressure
+	ReadOnly bool
+
+	// AvailableDiskBytes is the latest available disk space observed by the manager
+	AvailableDiskBytes int64
+
+	// CPUPercent is the CPU consumed by all running plugins combined, as of the latest health check
+	CPUPercent float64
+
+	// RSSBytes is the memory consumed by all running plugins combined, as of the latest health check
+	RSSBytes uint64
+
+	// NonReportingPlugins is the number of running plugins that aren't part of CPUPercent and RSSBytes
+	// since they don't report their resource usage
+	NonReportingPlugins int
+}
+
+// Stats returns the operational state of the long running plugin manager
+func (m *Manager) Stats() Stats {
+	lock.RLock()
+	stats := Stats{
+		RegisteredPlugins: len(m.registeredPlugins),
+		ConfiguredPlugins: len(m.runningPlugins),
+	}
+	lock.RUnlock()
+
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+	stats.ReadOnly = m.readOnly
+	stats.AvailableDiskBytes = m.availableDiskBytes
+	stats.CPUPercent = m.resourceUsage.cpuPercent
+	stats.RSSBytes = m.resourceUsage.rssBytes
+	stats.NonReportingPlugins = m.resourceUsage.nonReporting
+	return stats
+}
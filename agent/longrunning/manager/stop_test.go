@@ -0,0 +1,137 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newDrainingPool returns a mocked pool whose ShutdownAndWaitForJobs takes the given time to drain
+func newDrainingPool(drain time.Duration) *task.MockedPool {
+	pool := &task.MockedPool{}
+	pool.On("ShutdownAndWaitForJobs", mock.Anything).Return(true, nil).Run(func(mock.Arguments) { time.Sleep(drain) })
+	return pool
+}
+
+func TestRequestStopWithContextDrainsPoolsWithinDeadline(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.startPlugin = newDrainingPool(0)
+	m.stopPlugin = newDrainingPool(0)
+
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, m.RequestStopWithContext(ctx))
+	withinDeadline := mock.MatchedBy(func(d time.Duration) bool { return d > 0 && d <= 2*time.Second })
+	m.startPlugin.(*task.MockedPool).AssertCalled(t, "ShutdownAndWaitForJobs", withinDeadline)
+	m.stopPlugin.(*task.MockedPool).AssertCalled(t, "ShutdownAndWaitForJobs", withinDeadline)
+}
+
+func TestRequestStopWithContextReturnsEarlyWhenCancelled(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.startPlugin = newDrainingPool(time.Minute)
+	m.stopPlugin = newDrainingPool(0)
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := m.RequestStopWithContext(ctx)
+
+	assert.True(t, time.Since(start) < time.Minute, "stop didn't return when the context got cancelled")
+	_, isInterrupted := err.(*StopInterruptedError)
+	assert.True(t, isInterrupted)
+	assert.True(t, errors.Is(err, gocontext.Canceled))
+}
+
+func TestRequestStopWithContextReportsPoolsThatDidNotShutDownBeforeDeadline(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	startPool := &task.MockedPool{}
+	startPool.On("ShutdownAndWaitForJobs", mock.Anything).Return(false, []string{"plugin"}).Run(func(args mock.Arguments) {
+		time.Sleep(args.Get(0).(time.Duration))
+	})
+	m.startPlugin = startPool
+	m.stopPlugin = newDrainingPool(0)
+
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, &PoolShutdownError{Pools: []string{startPluginPoolName}}, m.RequestStopWithContext(ctx))
+	beforeDeadline := mock.MatchedBy(func(d time.Duration) bool { return d > 0 && d <= 200*time.Millisecond-stopDeadlineSlack })
+	startPool.AssertCalled(t, "ShutdownAndWaitForJobs", beforeDeadline)
+}
+
+func TestRequestStopUsesStopTypeTimeout(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.startPlugin = newDrainingPool(0)
+	m.stopPlugin = newDrainingPool(0)
+
+	assert.NoError(t, m.ModuleRequestStop(contracts.StopTypeHardStop))
+	withinHardStop := mock.MatchedBy(func(d time.Duration) bool { return d > 0 && d <= HardStopTimeout })
+	m.startPlugin.(*task.MockedPool).AssertCalled(t, "ShutdownAndWaitForJobs", withinHardStop)
+}
+
+func TestRequestStopReportsPoolsThatDidNotShutDown(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	startPool := &task.MockedPool{}
+	startPool.On("ShutdownAndWaitForJobs", mock.Anything).Return(false, []string{"plugin"})
+	m.startPlugin = startPool
+	m.stopPlugin = newDrainingPool(0)
+
+	err := m.ModuleRequestStop(contracts.StopTypeSoftStop)
+
+	assert.Equal(t, &PoolShutdownError{Pools: []string{startPluginPoolName}}, err)
+}
+
+func TestRequestStopFailsWhenPoolBlocksPastTimeout(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.config.HardStopTimeout = 50 * time.Millisecond
+	stopPool := &task.MockedPool{}
+	stopPool.On("ShutdownAndWaitForJobs", mock.Anything).Return(false, []string{"plugin"}).Run(func(args mock.Arguments) {
+		time.Sleep(args.Get(0).(time.Duration))
+	})
+	m.startPlugin = newDrainingPool(0)
+	m.stopPlugin = stopPool
+
+	start := time.Now()
+	err := m.ModuleRequestStop(contracts.StopTypeHardStop)
+
+	assert.Equal(t, &PoolShutdownError{Pools: []string{stopPluginPoolName}}, err)
+	assert.True(t, time.Since(start) < time.Minute, "stop didn't return after its timeout")
+}
+
+func TestRequestStopWaitsForPoolsPastStopTimeout(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	m.config.HardStopTimeout = 10 * time.Millisecond
+	m.startPlugin = newDrainingPool(0)
+	m.stopPlugin = newDrainingPool(100 * time.Millisecond)
+
+	assert.NoError(t, m.ModuleRequestStop(contracts.StopTypeHardStop))
+}
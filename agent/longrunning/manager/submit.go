@@ -0,0 +1,70 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+// SubmitStartPlugin submits the start of the given long running plugin with the given configuration to the start
+// pool and returns without waiting for it. Like StartPlugin, the job updates the running plugins and persists them.
+// All long running plugins are singletons - the plugin name is the job id, so a start of a plugin that is already
+// being started gets rejected by the pool.
+func (m *Manager) SubmitStartPlugin(name, configuration, orchestrationDir string) error {
+	log := m.context.Log()
+	if !m.isRegistered(name) {
+		return fmt.Errorf("unable to run %s since it's not even registered", name)
+	}
+
+	return m.startPlugin.Submit(log, name, func(cancelFlag task.CancelFlag) {
+		ioConfig := contracts.IOConfiguration{
+			OrchestrationDirectory: orchestrationDir,
+			OutputS3BucketName:     "",
+			OutputS3KeyPrefix:      "",
+		}
+		out := newPluginIOHandler(log, ioConfig, name)
+		defer out.Close(log)
+		if err := m.StartPlugin(name, configuration, orchestrationDir, cancelFlag, out); err != nil {
+			log.Errorf("Submitted start of long running plugin - %s failed because of %s", name, err)
+		}
+	})
+}
+
+// SubmitStopPlugin submits the stop of the given long running plugin to the stop pool and returns without waiting
+// for it. Like StopPlugin, the job updates the running plugins and persists them. The plugin name is the job id, so
+// a stop of a plugin that is already being stopped gets rejected by the pool.
+func (m *Manager) SubmitStopPlugin(name string) error {
+	log := m.context.Log()
+	if !m.isRegistered(name) {
+		return fmt.Errorf("unable to stop %s since it's not even registered", name)
+	}
+
+	return m.stopPlugin.Submit(log, name, func(cancelFlag task.CancelFlag) {
+		if err := m.StopPlugin(name, cancelFlag); err != nil {
+			log.Errorf("Submitted stop of long running plugin - %s failed because of %s", name, err)
+		}
+	})
+}
+
+// isRegistered returns whether a long running plugin with the given name is registered
+func (m *Manager) isRegistered(name string) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	_, isRegisteredPlugin := m.registeredPlugins[name]
+	return isRegisteredPlugin
+}
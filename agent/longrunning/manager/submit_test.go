@@ -0,0 +1,81 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubmitStartPluginUsesPluginNameAsJobId(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	pool := &task.MockedPool{}
+	pool.On("Submit", mock.Anything, "plugin", mock.Anything).Return(nil).Once()
+	pool.On("Submit", mock.Anything, "plugin", mock.Anything).Return(task.ErrDuplicateJob)
+	m.startPlugin = pool
+
+	assert.NoError(t, m.SubmitStartPlugin("plugin", "config", "orchestration"))
+	// a second start while the first one is pending is rejected
+	assert.True(t, errors.Is(m.SubmitStartPlugin("plugin", "config", "orchestration"), task.ErrDuplicateJob))
+	pool.AssertNumberOfCalls(t, "Submit", 2)
+}
+
+func TestSubmitStopPluginUsesPluginNameAsJobId(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}})
+	defer restore()
+	pool := &task.MockedPool{}
+	pool.On("Submit", mock.Anything, "plugin", mock.Anything).Return(nil)
+	m.stopPlugin = pool
+
+	assert.NoError(t, m.SubmitStopPlugin("plugin"))
+	pool.AssertCalled(t, "Submit", mock.Anything, "plugin", mock.Anything)
+}
+
+func TestSubmitRejectsUnregisteredPlugins(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	pool := &task.MockedPool{}
+	m.startPlugin = pool
+	m.stopPlugin = pool
+
+	assert.Error(t, m.SubmitStartPlugin("unknown", "config", "orchestration"))
+	assert.Error(t, m.SubmitStopPlugin("unknown"))
+	pool.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestartRejectedAsDuplicateIsSkipped(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": {}, "other": {}})
+	defer restore()
+	logger := m.context.Log().(*log.Mock)
+	pool := &task.MockedPool{}
+	pool.On("HasJob", mock.Anything).Return(false)
+	pool.On("Submit", mock.Anything, "plugin", mock.Anything).Return(task.ErrDuplicateJob)
+	pool.On("Submit", mock.Anything, "other", mock.Anything).Return(errors.New("pool is shut down"))
+	m.startPlugin = pool
+
+	// the plugin is being started already, so the rejection isn't an error
+	m.submitRestart(m.registeredPlugins["plugin"], false)
+	logger.AssertCalled(t, "Debugf", "Plugin %s already running, skipping", []interface{}{"plugin"})
+	logger.AssertNotCalled(t, "Errorf", mock.Anything, mock.Anything)
+
+	m.submitRestart(m.registeredPlugins["other"], false)
+	logger.AssertCalled(t, "Errorf", "Failed to submit the start of long running plugin - %s because of %s", mock.Anything)
+}
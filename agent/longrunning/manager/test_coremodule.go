@@ -15,6 +15,7 @@
 package manager
 
 import (
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
@@ -33,7 +34,7 @@ type Mock struct {
 }
 
 // CloudWatchId represents the ID of cloud watch plugin
-const CloudWatchId = "aws:cloudWatch"
+const CloudWatchId = appconfig.PluginNameCloudWatch
 
 // NewMockDefault returns an instance of Mock with default expectations set.
 func NewMockDefault() *Mock {
@@ -59,6 +60,11 @@ func (m *Mock) GetRegisteredPlugins() map[string]managerContracts.Plugin {
 	return args.Get(0).(map[string]managerContracts.Plugin)
 }
 
+// GetRunningPlugins returns a copy of the currently managed long running plugins - returns an empty map for testing
+func (m *Mock) GetRunningPlugins() map[string]managerContracts.PluginInfo {
+	return map[string]managerContracts.PluginInfo{}
+}
+
 // Name returns the module name
 func (m *Mock) ModuleName() string {
 	args := m.Called()
@@ -86,7 +92,22 @@ func (m *Mock) StartPlugin(name, configuration, orchestrationDir string, cancelF
 	return nil
 }
 
+// SubmitStopPlugin submits the stop of a given plugin and returns encountered error - returns nil here for testing
+func (m *Mock) SubmitStopPlugin(name string) error {
+	return nil
+}
+
+// SubmitStartPlugin submits the start of the given plugin with the given configuration and returns encountered error - returns nil here for testing
+func (m *Mock) SubmitStartPlugin(name, configuration, orchestrationDir string) error {
+	return nil
+}
+
 // EnsurePluginRegistered adds a long-running plugin if it is not already in the registry
 func (m *Mock) EnsurePluginRegistered(name string, plugin managerContracts.Plugin) (err error) {
 	return nil
 }
+
+// AcquirePluginOperation serializes operations on the given plugin - returns a no-op release function for testing
+func (m *Mock) AcquirePluginOperation(name, configuration string) (release func(), err error) {
+	return func() {}, nil
+}
@@ -15,21 +15,29 @@
 package manager
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"path/filepath"
+	"runtime/debug"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil"
-	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
-	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 )
 
 var (
+	//lock guards runningPlugins and registeredPlugins of the manager, nothing else
 	lock sync.RWMutex
 )
 
@@ -37,51 +45,321 @@ var (
 func (m *Manager) ensurePluginsAreRunning() {
 
 	log := m.context.Log()
+	m.recordHealthCheck()
 
+	stopped, unhealthy, orphaned, hasRunningPlugins := m.checkRunningPlugins()
+	if len(orphaned) > 0 {
+		m.pruneOrphanedPlugins(orphaned)
+	}
+	if !hasRunningPlugins {
+		log.Infof("There are no long running plugins currently getting executed - skipping their healthcheck")
+		return
+	}
+
+	//restart plugins with a higher priority first in case the restart rate limit gets reached
+	sort.Slice(stopped, func(i, j int) bool {
+		if stopped[i].Info.Priority != stopped[j].Info.Priority {
+			return stopped[i].Info.Priority > stopped[j].Info.Priority
+		}
+		return stopped[i].Info.Name < stopped[j].Info.Name
+	})
+	for _, p := range stopped {
+		if unhealthy[p.Info.Name] {
+			m.restartUnhealthyPlugin(p)
+		} else {
+			m.restartPlugin(p)
+		}
+	}
+}
+
+// checkRunningPlugins health checks the running plugins and returns the ones that need to be restarted, unhealthy
+// names those which are running but failed their health check and orphaned those which are no longer registered.
+// hasRunningPlugins is false if no plugin is running.
+// Restarts persist the running plugins once they're done, so they must not be submitted with lock held, which is
+// released here even if a plugin panics.
+func (m *Manager) checkRunningPlugins() (stopped []managerContracts.Plugin, unhealthy map[string]bool, orphaned []string, hasRunningPlugins bool) {
+	log := m.context.Log()
 	lock.RLock()
 	defer lock.RUnlock()
+	m.flushPendingPersistence()
 
-	if len(m.runningPlugins) > 0 {
-		for n := range m.runningPlugins {
-			p, isRegistered := m.registeredPlugins[n]
-			if isRegistered && !p.Handler.IsRunning(m.context) {
-				log.Infof("Starting %s since it wasn't running before")
-				//todo: we arent using task pools anymore -> change the following implementation
-				m.startPlugin.Submit(m.context.Log(), n, func(cancelFlag task.CancelFlag) {
-					instanceID, _ := platform.InstanceID()
-					orchestrationRootDir := filepath.Join(
-						appconfig.DefaultDataStorePath,
-						instanceID,
-						appconfig.DefaultDocumentRootDirName,
-						m.context.AppConfig().Agent.OrchestrationRootDir)
-					orchestrationDir := fileutil.BuildPath(orchestrationRootDir)
-
-					ioConfig := contracts.IOConfiguration{
-						OrchestrationDirectory: orchestrationDir,
-						OutputS3BucketName:     "",
-						OutputS3KeyPrefix:      "",
-					}
-					out := iohandler.NewDefaultIOHandler(log, ioConfig)
-					defer out.Close(log)
-					out.Init(log, p.Info.Name)
-					p.Handler.Start(m.context, p.Info.Configuration, "", cancelFlag, out)
-					out.Close(log)
-				})
+	if len(m.runningPlugins) == 0 {
+		return nil, nil, nil, false
+	}
+	var running []managerContracts.Plugin
+	unhealthy = map[string]bool{}
+	for n, info := range m.runningPlugins {
+		p, isRegistered := m.registeredPlugins[n]
+		if !isRegistered {
+			orphaned = append(orphaned, n)
+			continue
+		}
+		if isLazyInactive(p, info) {
+			continue
+		}
+		if p.Handler == nil {
+			log.Warnf("Skipping health check of long running plugin %s - it's registered without a handler", n)
+			continue
+		}
+		isRunning := !injectedNotRunning(n) && p.Handler.IsRunning(m.context)
+		m.recordIsRunning(n, isRunning)
+		if isRunning {
+			running = append(running, p)
+			err := m.checkHealth(p)
+			if err == nil {
+				continue
 			}
+			log.Warnf("Long running plugin %s is running but failed its health check - %v", n, err)
+			m.emit(EventUnhealthy, n, err.Error())
+			unhealthy[n] = true
 		}
+		if reason, isQuarantined := m.quarantineReason(n); isQuarantined {
+			log.Debugf("Not starting %s since it's quarantined - %s", n, reason)
+			continue
+		}
+		stopped = append(stopped, p)
+	}
+
+	m.collectResourceUsage(running)
+	m.recordRunningPlugins(len(running))
+	return stopped, unhealthy, orphaned, true
+}
+
+// pruneOrphanedPlugins removes the given running plugins that are no longer registered, e.g. because an upgrade of
+// the agent removed them, and persists the remaining running plugins
+func (m *Manager) pruneOrphanedPlugins(names []string) {
+	log := m.context.Log()
+	lock.Lock()
+	defer lock.Unlock()
+
+	pruned := false
+	for _, name := range names {
+		if _, isRunning := m.runningPlugins[name]; !isRunning {
+			continue
+		}
+		if _, isRegistered := m.registeredPlugins[name]; isRegistered {
+			continue
+		}
+		log.Warnf("Removing long running plugin %s from the running plugins - it's no longer registered", name)
+		delete(m.runningPlugins, name)
+		pruned = true
+	}
+	if !pruned {
+		return
+	}
+	if err := m.writeDataStore(); err != nil {
+		log.Errorf("Failed to update datastore after removing unregistered plugins - because of %s", err)
+	}
+}
+
+// restartPlugin submits the start of a long running plugin that isn't running, within the restart rate limit
+func (m *Manager) restartPlugin(p managerContracts.Plugin) {
+	m.submitRestart(p, false)
+}
+
+// restartUnhealthyPlugin submits the stop and the start of a running long running plugin that failed its health
+// check, within the restart rate limit
+func (m *Manager) restartUnhealthyPlugin(p managerContracts.Plugin) {
+	m.submitRestart(p, true)
+}
+
+// submitRestart submits the start of a long running plugin, stopping it first if stopFirst is true
+func (m *Manager) submitRestart(p managerContracts.Plugin, stopFirst bool) {
+	log := m.context.Log()
+	n := p.Info.Name
+	if m.startPlugin.HasJob(n) || m.isStarting(n) {
+		log.Debugf("Start of %s is already in progress", n)
+		return
+	}
+	if deferral := m.restartDeferral(n); deferral > 0 {
+		log.Infof("Deferring restart of %s for another %v - backing off after %v restarts", n, deferral, m.restartAttemptsOf(n))
+		return
+	}
+	if !m.restartLimiter.allow() {
+		log.Warnf("Deferring restart of %s to the next health check - restart limit of %v per minute reached", n, m.GetConfig().MaxRestartsPerMinute)
+		return
+	}
+	m.recordRestartAttempt(n)
+	if stopFirst {
+		log.Infof("Restarting %s since it failed its health check", n)
 	} else {
-		log.Infof("There are no long running plugins currently getting executed - skipping their healthcheck")
+		log.Infof("Starting %s since it wasn't running before", n)
+	}
+	//the start runs on the start pool with the plugin name as job id, like the starts submitted by SubmitStartPlugin
+	err := m.startPlugin.Submit(m.context.Log(), n, func(cancelFlag task.CancelFlag) {
+		if !m.beginStart(n) {
+			log.Debugf("Not starting %s since a start of it is already in progress", n)
+			return
+		}
+		defer m.endStart(n)
+		if stopFirst {
+			if err := m.stopPluginHandler(p, cancelFlag, m.stopTimeout(contracts.StopTypeSoftStop)); err != nil {
+				log.Errorf("Failed to stop unhealthy long running plugin - %s because of %s", n, err)
+				return
+			}
+		}
+		if err := m.startPluginWithDefaultIO(p, cancelFlag); err == nil {
+			m.recordRestarted(n)
+			m.recordRestart(n)
+			m.persistRestarted(n)
+			m.emit(EventRestarted, n, "")
+		}
+	})
+	//the plugin name is the job id - a rejected duplicate means the plugin is being started already
+	if errors.Is(err, task.ErrDuplicateJob) {
+		log.Debugf("Plugin %s already running, skipping", n)
+	} else if err != nil {
+		log.Errorf("Failed to submit the start of long running plugin - %s because of %s", n, err)
+	}
+}
+
+// persistRestarted persists the running plugins after the manager restarted the given plugin, along with the working
+// directory the plugin got restarted in. Plugins that got stopped in the meantime aren't persisted again.
+func (m *Manager) persistRestarted(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	info, isRunning := m.runningPlugins[name]
+	if !isRunning {
+		return
+	}
+	if info.OrchestrationDir == "" {
+		info.OrchestrationDir = pluginOrchestrationDir(m.context, name)
+		m.runningPlugins[name] = info
+	}
+	if err := m.writeDataStore(); err != nil {
+		m.context.Log().Errorf("Failed to update datastore after restarting %s - because of %s", name, err)
+	}
+}
+
+// startPluginWithDefaultIO starts the given long running plugin with an IO handler rooted at the default orchestration directory
+func (m *Manager) startPluginWithDefaultIO(p managerContracts.Plugin, cancelFlag task.CancelFlag) (err error) {
+	defer func() { m.recordStartResult(p.Info.Name, err) }()
+
+	log := m.context.Log()
+	configuration, err := expandPluginConfiguration(p.Info.Configuration)
+	if err != nil {
+		return fmt.Errorf("unable to resolve configuration of %s: %v", p.Info.Name, err)
+	}
+	if err = m.verifyPluginBinary(p); err != nil {
+		return err
+	}
+	if err = injectedStartFailure(p.Info.Name); err != nil {
+		return err
+	}
+	configuration, secrets, err := m.resolveSecrets(configuration)
+	if err != nil {
+		return fmt.Errorf("unable to resolve secrets in configuration of %s: %v", p.Info.Name, err)
+	}
+	orchestrationDir, err := m.orchestrationDirOf(p)
+	if err != nil {
+		return err
+	}
+	ioConfig := contracts.IOConfiguration{
+		OrchestrationDirectory: orchestrationDir,
+		OutputS3BucketName:     "",
+		OutputS3KeyPrefix:      "",
+	}
+	out := newPluginIOHandler(log, ioConfig, p.Info.Name)
+	defer out.Close(log)
+	if err = p.Handler.Start(newRedactingContext(m.context, secrets), configuration, orchestrationDir, cancelFlag, out); err != nil {
+		return err
+	}
+	m.recordCancelFlag(p.Info.Name, cancelFlag)
+	return nil
+}
+
+// pluginOrchestrationDir returns the working directory of the given long running plugin, it's the same for a given
+// plugin name across restarts of the plugin and the agent
+func pluginOrchestrationDir(context context.T, name string) string {
+	return fileutil.BuildPath(defaultOrchestrationDir(context), appconfig.LongRunningPluginsLocation, pluginDirName(name))
+}
+
+// pluginDirName returns a directory name for the given plugin name that is valid on all platforms, e.g. aws:cloudWatch
+// becomes aws_cloudWatch
+func pluginDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '/', '\\', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// orchestrationDirOf returns the working directory of the given long running plugin and creates it if it's missing
+func (m *Manager) orchestrationDirOf(p managerContracts.Plugin) (string, error) {
+	dir := p.Info.OrchestrationDir
+	if dir == "" {
+		dir = pluginOrchestrationDir(m.context, p.Info.Name)
+	}
+	if err := makePluginDirs(dir); err != nil {
+		return "", fmt.Errorf("unable to create orchestration directory %s of %s: %v", dir, p.Info.Name, err)
+	}
+	return dir, nil
+}
+
+// defaultOrchestrationDir returns the orchestration root directory of the current instance
+func defaultOrchestrationDir(context context.T) string {
+	instanceID, _ := platform.InstanceID()
+	orchestrationRootDir := filepath.Join(
+		appconfig.DefaultDataStorePath,
+		instanceID,
+		appconfig.DefaultDocumentRootDirName,
+		context.AppConfig().Agent.OrchestrationRootDir)
+	return fileutil.BuildPath(orchestrationRootDir)
+}
+
+// printableConfiguration returns the configuration of a long running plugin in a form that is safe to log
+func printableConfiguration(logger log.T, name, configuration string) string {
+	if name == appconfig.PluginNameCloudWatch {
+		return log.PrintCWConfig(configuration, logger)
+	}
+	return configuration
+}
+
+// scheduleHealthCheck health checks long running plugins every interval of the manager's clock, right away first
+// if immediately is set. Stopping the returned ticker stops the health checks.
+func (m *Manager) scheduleHealthCheck(interval time.Duration, immediately bool) times.Ticker {
+	ticker := times.NewTicker(m.clock, interval)
+	healthCheck := m.safeJob(m.ensurePluginsAreRunning)
+	go func() {
+		if immediately {
+			healthCheck()
+		}
+		for range ticker.C() {
+			healthCheck()
+		}
+	}()
+	return ticker
+}
+
+// safeJob wraps a job run on a schedule so that a panic of the job gets logged instead of ending the schedule,
+// the job runs again on the next tick
+func (m *Manager) safeJob(job func()) func() {
+	return func() {
+		defer func() {
+			if msg := recover(); msg != nil {
+				log := m.context.Log()
+				log.Errorf("Scheduled job of the long running plugin manager panicked: %v", msg)
+				log.Errorf("Stacktrace:\n%s", debug.Stack())
+			}
+		}()
+		job()
 	}
 }
 
 // stopLifeCycleManagementJob stops periodic health checks of long running plugins
 func (m *Manager) stopLifeCycleManagementJob() {
 	if m.managingLifeCycleJob != nil {
-		m.managingLifeCycleJob.Quit <- true
+		m.managingLifeCycleJob.Stop()
 	}
+	m.stopHealthCheckWatchdog()
+	m.stopCloseWatches()
+	m.stopCloudWatchReloadWatch()
 }
 
 // RegisteredPlugins loads all registered long running plugins in memory
-func RegisteredPlugins(context context.T) map[string]plugin.Plugin {
-	return plugin.RegisteredPlugins(context)
+func RegisteredPlugins(context context.T) map[string]managerContracts.Plugin {
+	return managerContracts.RegisteredPlugins(context)
 }
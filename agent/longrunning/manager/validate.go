@@ -0,0 +1,70 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"sort"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+)
+
+// ValidationError describes a persisted long running plugin that wouldn't start as it is
+type ValidationError struct {
+	Name string
+	Err  error
+}
+
+// Error describes why the persisted plugin is invalid
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("long running plugin %s is invalid - %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying reason
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks the persisted long running plugins without starting anything: every plugin must still be
+// registered and its configuration must pass the validator of the plugin, if it has one. The problems are
+// returned ordered by plugin name, so that a preflight check can surface a stale data store - e.g. a plugin
+// that got removed in an upgrade - instead of Execute silently skipping it.
+func (m *Manager) Validate() []error {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	names := make([]string, 0, len(m.runningPlugins))
+	for name := range m.runningPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		p, isRegistered := m.registeredPlugins[name]
+		if !isRegistered {
+			errs = append(errs, &ValidationError{Name: name, Err: fmt.Errorf("plugin isn't registered")})
+			continue
+		}
+		validator, ok := p.Handler.(managerContracts.ConfigValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.ValidateConfig(m.runningPlugins[name].Configuration); err != nil {
+			errs = append(errs, &ValidationError{Name: name, Err: fmt.Errorf("invalid configuration: %v", err)})
+		}
+	}
+	return errs
+}
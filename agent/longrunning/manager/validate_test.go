@@ -0,0 +1,89 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockedValidatingPlugin is a mocked long running plugin that validates its configuration
+type mockedValidatingPlugin struct {
+	*mockedPlugin
+}
+
+// ValidateConfig mocks validating a configuration
+func (p *mockedValidatingPlugin) ValidateConfig(configuration string) error {
+	args := p.Called(configuration)
+	return args.Error(0)
+}
+
+func TestValidateReportsUnregisteredPlugins(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin"}
+	m.runningPlugins["removed"] = managerContracts.PluginInfo{Name: "removed"}
+
+	errs := m.Validate()
+
+	assert.Len(t, errs, 1)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(errs[0], &validationErr))
+	assert.Equal(t, "removed", validationErr.Name)
+	_, isRunning := m.runningPlugins["removed"]
+	assert.True(t, isRunning, "validation must not change the persisted plugins")
+	handler.AssertNotCalled(t, "Start")
+}
+
+func TestValidateChecksConfigurations(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	valid := &mockedValidatingPlugin{mockedPlugin: &mockedPlugin{}}
+	invalid := &mockedValidatingPlugin{mockedPlugin: &mockedPlugin{}}
+	m.registeredPlugins["valid"] = managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: "valid"}, Handler: valid}
+	m.registeredPlugins["invalid"] = managerContracts.Plugin{Info: managerContracts.PluginInfo{Name: "invalid"}, Handler: invalid}
+	m.runningPlugins["valid"] = managerContracts.PluginInfo{Name: "valid", Configuration: `{"Region":"us-east-1"}`}
+	m.runningPlugins["invalid"] = managerContracts.PluginInfo{Name: "invalid", Configuration: `{"Region":`}
+	valid.On("ValidateConfig", `{"Region":"us-east-1"}`).Return(nil).Once()
+	invalid.On("ValidateConfig", `{"Region":`).Return(fmt.Errorf("unexpected end of JSON input")).Once()
+
+	errs := m.Validate()
+
+	valid.AssertExpectations(t)
+	invalid.AssertExpectations(t)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "invalid")
+	assert.Contains(t, errs[0].Error(), "unexpected end of JSON input")
+}
+
+func TestValidateOrdersProblemsByName(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	for _, name := range []string{"c", "a", "b"} {
+		m.runningPlugins[name] = managerContracts.PluginInfo{Name: name}
+	}
+
+	errs := m.Validate()
+
+	assert.Len(t, errs, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		assert.Equal(t, name, errs[i].(*ValidationError).Name)
+	}
+}
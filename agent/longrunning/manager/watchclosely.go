@@ -0,0 +1,106 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"time"
+)
+
+// WatchClosely health checks the given long running plugin every interval for the given duration, on top of the
+// periodic health check of all plugins, e.g. to confirm a plugin stabilized after a remediation. A plugin found not
+// running is restarted the same way the periodic health check does. Watching a plugin that is already watched
+// closely replaces the previous watch.
+func (m *Manager) WatchClosely(name string, duration, interval time.Duration) {
+	log := m.context.Log()
+	if duration <= 0 || interval <= 0 {
+		log.Errorf("Unable to watch %s closely - duration %v and interval %v must be positive", name, duration, interval)
+		return
+	}
+
+	lock.RLock()
+	_, isRegistered := m.registeredPlugins[name]
+	lock.RUnlock()
+	if !isRegistered {
+		log.Errorf("Unable to watch %s closely - it isn't a registered long running plugin", name)
+		return
+	}
+
+	m.closeWatchesLock.Lock()
+	defer m.closeWatchesLock.Unlock()
+	if m.closeWatches == nil {
+		m.closeWatches = map[string]chan struct{}{}
+	}
+	if quit, isWatched := m.closeWatches[name]; isWatched {
+		close(quit)
+	}
+	quit := make(chan struct{})
+	m.closeWatches[name] = quit
+
+	log.Infof("Watching %s closely every %v for %v", name, interval, duration)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		deadline := time.After(duration)
+		for {
+			select {
+			case <-ticker.C:
+				m.probePlugin(name)
+			case <-deadline:
+				log.Infof("Done watching %s closely", name)
+				m.closeWatchesLock.Lock()
+				if m.closeWatches[name] == quit {
+					delete(m.closeWatches, name)
+				}
+				m.closeWatchesLock.Unlock()
+				return
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// probePlugin health checks a single long running plugin and restarts it if it's enabled but not running
+func (m *Manager) probePlugin(name string) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	p, isRegistered := m.registeredPlugins[name]
+	info, isConfigured := m.runningPlugins[name]
+	if !isRegistered || !isConfigured || !info.State.IsEnabled || isLazyInactive(p, info) {
+		return
+	}
+
+	isRunning := !injectedNotRunning(name) && p.Handler.IsRunning(m.context)
+	m.recordIsRunning(name, isRunning)
+	if isRunning {
+		return
+	}
+	if reason, isQuarantined := m.quarantineReason(name); isQuarantined {
+		m.context.Log().Debugf("Not starting %s since it's quarantined - %s", name, reason)
+		return
+	}
+	m.restartPlugin(p)
+}
+
+// stopCloseWatches stops all targeted health checks started by WatchClosely
+func (m *Manager) stopCloseWatches() {
+	m.closeWatchesLock.Lock()
+	defer m.closeWatchesLock.Unlock()
+	for name, quit := range m.closeWatches {
+		close(quit)
+		delete(m.closeWatches, name)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWatchCloselyProbesAtTighterCadence(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+
+	var probes int32
+	handler.On("IsRunning", mock.Anything).Return(true).Run(func(mock.Arguments) {
+		atomic.AddInt32(&probes, 1)
+	})
+
+	m.WatchClosely("plugin", 200*time.Millisecond, 20*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+
+	// ~10 probes are expected within the duration, allow for scheduling jitter
+	probed := atomic.LoadInt32(&probes)
+	assert.True(t, probed >= 5 && probed <= 10, "unexpected number of probes: %v", probed)
+
+	// probes stop after the duration
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, probed, atomic.LoadInt32(&probes))
+	m.closeWatchesLock.Lock()
+	assert.Empty(t, m.closeWatches)
+	m.closeWatchesLock.Unlock()
+}
+
+func TestWatchCloselyRestartsPluginThatIsNotRunning(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(false)
+
+	submitted := make(chan struct{}, 10)
+	pool := &task.MockedPool{}
+	pool.On("HasJob", "plugin").Return(false)
+	pool.On("Submit", mock.Anything, "plugin", mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		submitted <- struct{}{}
+	})
+	m.startPlugin = pool
+
+	m.WatchClosely("plugin", time.Second, 10*time.Millisecond)
+	defer m.stopCloseWatches()
+
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		assert.Fail(t, "plugin that isn't running wasn't restarted by the targeted health check")
+	}
+}
+
+func TestWatchCloselyStop(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+
+	m.WatchClosely("plugin", time.Hour, time.Hour)
+	m.WatchClosely("plugin", time.Hour, time.Hour)
+	m.closeWatchesLock.Lock()
+	assert.Len(t, m.closeWatches, 1)
+	m.closeWatchesLock.Unlock()
+
+	m.stopCloseWatches()
+	assert.Empty(t, m.closeWatches)
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"time"
+)
+
+// healthCheckWatchdogFactor is the number of poll intervals without any health check after which the watchdog forces one
+const healthCheckWatchdogFactor = 2
+
+// recordHealthCheck stores the time of the latest health check of long running plugins
+func (m *Manager) recordHealthCheck() {
+	m.healthCheckLock.Lock()
+	defer m.healthCheckLock.Unlock()
+	m.lastHealthCheck = m.clock.Now()
+}
+
+// startHealthCheckWatchdog starts a watchdog that makes sure long running plugins get health checked even if the
+// health check ticker stalls. The ticker waits on monotonic timers, the watchdog is a safety net on top of it and
+// it uses a monotonic ticker as well so that wall clock steps (e.g. NTP moving the clock backward) can't affect it.
+func (m *Manager) startHealthCheckWatchdog(interval time.Duration) {
+	m.stopWatchdog = make(chan struct{})
+	m.recordHealthCheck()
+
+	check := m.safeJob(func() {
+		m.checkHealthCheckWatchdog(interval)
+	})
+	go func(quit chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-quit:
+				return
+			}
+		}
+	}(m.stopWatchdog)
+}
+
+// stopHealthCheckWatchdog stops the health check watchdog if it was started
+func (m *Manager) stopHealthCheckWatchdog() {
+	if m.stopWatchdog != nil {
+		close(m.stopWatchdog)
+		m.stopWatchdog = nil
+	}
+}
+
+// checkHealthCheckWatchdog forces a health check of long running plugins if none ran within healthCheckWatchdogFactor
+// poll intervals, or if the clock reports the latest health check in the future. Returns true if a health check was forced.
+func (m *Manager) checkHealthCheckWatchdog(interval time.Duration) bool {
+	m.healthCheckLock.Lock()
+	elapsed := m.clock.Now().Sub(m.lastHealthCheck)
+	m.healthCheckLock.Unlock()
+
+	if elapsed >= 0 && elapsed <= healthCheckWatchdogFactor*interval {
+		return false
+	}
+
+	if elapsed < 0 {
+		m.context.Log().Warnf("Detected a backward clock step of %v since the last health check of long running plugins - forcing a health check", -elapsed)
+	} else {
+		m.context.Log().Warnf("No health check of long running plugins ran for %v (poll interval %v) - forcing a health check", elapsed, interval)
+	}
+	m.ensurePluginsAreRunning()
+	return true
+}
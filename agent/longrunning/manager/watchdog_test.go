@@ -0,0 +1,158 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/times"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthCheckWatchdog(t *testing.T) {
+	interval := 15 * time.Minute
+	lastHealthCheck := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		now    time.Time
+		forced bool
+	}{
+		{"within interval", lastHealthCheck.Add(interval), false},
+		{"within watchdog window", lastHealthCheck.Add(2 * interval), false},
+		{"scheduler stalled", lastHealthCheck.Add(2*interval + time.Second), true},
+		{"backward clock step", lastHealthCheck.Add(-time.Hour), true},
+	}
+
+	for _, testCase := range testCases {
+		m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+		clock := times.NewMockedClock()
+		clock.On("Now").Return(testCase.now)
+		m.clock = clock
+		m.lastHealthCheck = lastHealthCheck
+
+		forced := m.checkHealthCheckWatchdog(interval)
+
+		assert.Equal(t, testCase.forced, forced, testCase.name)
+		if testCase.forced {
+			assert.Equal(t, testCase.now, m.lastHealthCheck, testCase.name)
+		} else {
+			assert.Equal(t, lastHealthCheck, m.lastHealthCheck, testCase.name)
+		}
+		restore()
+	}
+}
+
+func TestHealthCheckWatchdogStop(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	m.startHealthCheckWatchdog(time.Hour)
+	assert.NotNil(t, m.stopWatchdog)
+	m.stopHealthCheckWatchdog()
+	assert.Nil(t, m.stopWatchdog)
+
+	// stopping again is a no-op
+	m.stopHealthCheckWatchdog()
+}
+
+func TestScheduledHealthCheckRunsWhenClockAdvances(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+	m.setRunning(true)
+	interval := m.GetConfig().PollFrequency
+	clock := times.NewMockedClock()
+	clock.On("After", interval).Return(clock.AfterChannel)
+	clock.On("Now").Return(time.Now())
+	m.clock = clock
+	checked := make(chan struct{}, 1)
+	handler.On("IsRunning", mock.Anything).Return(true).Run(func(mock.Arguments) {
+		select {
+		case checked <- struct{}{}:
+		default:
+		}
+	})
+
+	ticker := m.scheduleHealthCheck(interval, false)
+	defer ticker.Stop()
+
+	select {
+	case <-checked:
+		assert.Fail(t, "health check ran before the clock advanced")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.AfterChannel <- struct{}{}
+	select {
+	case <-checked:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "health check didn't run once the poll interval elapsed")
+	}
+}
+
+func TestScheduledHealthCheckSurvivesPanic(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.runningPlugins["plugin"] = m.registeredPlugins["plugin"].Info
+	m.setRunning(true)
+	interval := m.GetConfig().PollFrequency
+	clock := times.NewMockedClock()
+	clock.On("After", interval).Return(clock.AfterChannel)
+	clock.On("Now").Return(time.Now())
+	m.clock = clock
+	checked := make(chan struct{}, 1)
+	handler.On("IsRunning", mock.Anything).Run(func(mock.Arguments) {
+		panic("nil handler")
+	}).Return(true).Once()
+	handler.On("IsRunning", mock.Anything).Run(func(mock.Arguments) {
+		checked <- struct{}{}
+	}).Return(true).Once()
+
+	ticker := m.scheduleHealthCheck(interval, true)
+	defer ticker.Stop()
+
+	clock.AfterChannel <- struct{}{}
+	select {
+	case <-checked:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "health check didn't run on the tick after a panic")
+	}
+	handler.AssertNumberOfCalls(t, "IsRunning", 2)
+
+	// the panic released the lock of the running plugins
+	lock.Lock()
+	lock.Unlock()
+}
+
+func TestSafeJobRecoversPanics(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+	runs := 0
+	job := m.safeJob(func() {
+		runs++
+		if runs == 1 {
+			panic("first run")
+		}
+	})
+
+	assert.NotPanics(t, job)
+	assert.NotPanics(t, job)
+	assert.Equal(t, 2, runs)
+}
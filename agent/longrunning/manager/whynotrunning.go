@@ -0,0 +1,124 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Reasons returned by WhyNotRunning
+const (
+	// ReasonRunning means the plugin is running
+	ReasonRunning = "Running"
+
+	// ReasonManagerStopped means the long running plugin manager itself isn't running
+	ReasonManagerStopped = "ManagerStopped"
+
+	// ReasonDisabled means the plugin isn't enabled by any document
+	ReasonDisabled = "Disabled"
+
+	// ReasonLazyInactive means the plugin is lazy and waits to be activated
+	ReasonLazyInactive = "LazyInactive"
+
+	// ReasonQuarantined means the plugin is quarantined, e.g. because its executable failed the integrity check
+	ReasonQuarantined = "Quarantined"
+
+	// ReasonStartFailed means the latest attempt to start the plugin failed
+	ReasonStartFailed = "StartFailed"
+
+	// ReasonExited means the plugin is enabled but exited - it gets started again by the next health check
+	ReasonExited = "Exited"
+)
+
+// Keys of the detail returned by WhyNotRunning
+const (
+	DetailDisabled         = "disabled"
+	DetailLastError        = "lastError"
+	DetailLastErrorTime    = "lastErrorTime"
+	DetailQuarantineReason = "quarantineReason"
+)
+
+// startFailure is the latest failed attempt to start a long running plugin
+type startFailure struct {
+	err  string
+	time time.Time
+}
+
+// WhyNotRunning returns the reason why the given long running plugin isn't running together with structured detail
+// about it. ReasonRunning is returned for plugins that are running. An error is returned for unknown plugins.
+func (m *Manager) WhyNotRunning(name string) (reason string, detail map[string]string, err error) {
+	lock.RLock()
+	p, isRegistered := m.registeredPlugins[name]
+	info, isConfigured := m.runningPlugins[name]
+	lock.RUnlock()
+
+	if !isRegistered {
+		return "", nil, fmt.Errorf("%s isn't a registered long running plugin", name)
+	}
+
+	m.statusLock.RLock()
+	running := m.running
+	failure, hasFailed := m.lastStartFailure[name]
+	quarantineReason, isQuarantined := m.quarantined[name]
+	m.statusLock.RUnlock()
+
+	detail = map[string]string{
+		DetailDisabled: strconv.FormatBool(!isConfigured || !info.State.IsEnabled),
+	}
+	if hasFailed {
+		detail[DetailLastError] = failure.err
+		detail[DetailLastErrorTime] = failure.time.UTC().Format(time.RFC3339)
+	}
+	if isQuarantined {
+		detail[DetailQuarantineReason] = quarantineReason
+	}
+
+	if p.Handler.IsRunning(m.context) {
+		return ReasonRunning, detail, nil
+	}
+	if !running {
+		return ReasonManagerStopped, detail, nil
+	}
+	if !isConfigured || !info.State.IsEnabled {
+		return ReasonDisabled, detail, nil
+	}
+	if isLazyInactive(p, info) {
+		return ReasonLazyInactive, detail, nil
+	}
+	if isQuarantined {
+		return ReasonQuarantined, detail, nil
+	}
+	if hasFailed {
+		return ReasonStartFailed, detail, nil
+	}
+	return ReasonExited, detail, nil
+}
+
+// recordStartResult keeps track of the latest failed attempt to start a long running plugin, a successful start clears it
+func (m *Manager) recordStartResult(name string, err error) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if err == nil {
+		delete(m.lastStartFailure, name)
+		m.recordStarted(name)
+		return
+	}
+	if m.lastStartFailure == nil {
+		m.lastStartFailure = map[string]startFailure{}
+	}
+	m.lastStartFailure[name] = startFailure{err: err.Error(), time: m.clock.Now()}
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package manager encapsulates everything related to long running plugin manager that starts, stops & configures long running plugins
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWhyNotRunning(t *testing.T) {
+	enabled := managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+
+	testCases := []struct {
+		name             string
+		managerRunning   bool
+		configured       bool
+		isRunning        bool
+		startErr         error
+		expectedReason   string
+		expectedDisabled string
+	}{
+		{"running", true, true, true, nil, ReasonRunning, "false"},
+		{"manager stopped", false, true, false, nil, ReasonManagerStopped, "false"},
+		{"disabled", true, false, false, nil, ReasonDisabled, "true"},
+		{"failed start", true, true, false, fmt.Errorf("exe not found"), ReasonStartFailed, "false"},
+		{"exited", true, true, false, nil, ReasonExited, "false"},
+	}
+
+	for _, tc := range testCases {
+		handler := &mockedPlugin{}
+		m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+		handler.On("IsRunning", mock.Anything).Return(tc.isRunning)
+		m.setRunning(tc.managerRunning)
+		if tc.configured {
+			m.runningPlugins["plugin"] = enabled
+		}
+		if tc.startErr != nil {
+			m.recordStartResult("plugin", tc.startErr)
+		}
+
+		reason, detail, err := m.WhyNotRunning("plugin")
+
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expectedReason, reason, tc.name)
+		assert.Equal(t, tc.expectedDisabled, detail[DetailDisabled], tc.name)
+		if tc.startErr != nil {
+			assert.Equal(t, tc.startErr.Error(), detail[DetailLastError], tc.name)
+			assert.NotEmpty(t, detail[DetailLastErrorTime], tc.name)
+		} else {
+			assert.NotContains(t, detail, DetailLastError, tc.name)
+		}
+		restore()
+	}
+}
+
+func TestWhyNotRunningClearsFailureOnSuccessfulStart(t *testing.T) {
+	handler := &mockedPlugin{}
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{"plugin": handler})
+	defer restore()
+	m.setRunning(true)
+	m.runningPlugins["plugin"] = managerContracts.PluginInfo{Name: "plugin", State: managerContracts.PluginState{IsEnabled: true}}
+	handler.On("IsRunning", mock.Anything).Return(false)
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("exe not found")).Once()
+	handler.On("Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], nil)
+	reason, _, _ := m.WhyNotRunning("plugin")
+	assert.Equal(t, ReasonStartFailed, reason)
+
+	m.startPluginWithDefaultIO(m.registeredPlugins["plugin"], nil)
+	reason, _, _ = m.WhyNotRunning("plugin")
+	assert.Equal(t, ReasonExited, reason)
+}
+
+func TestWhyNotRunningUnknownPlugin(t *testing.T) {
+	m, _, restore := setupTestManager(map[string]*mockedPlugin{})
+	defer restore()
+
+	_, _, err := m.WhyNotRunning("unknown")
+	assert.Error(t, err)
+}
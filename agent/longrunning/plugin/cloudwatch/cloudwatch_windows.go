@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -32,6 +33,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/jsonutil"
 	logger "github.com/aws/amazon-ssm-agent/agent/log"
+	managerContracts "github.com/aws/amazon-ssm-agent/agent/longrunning/plugin"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/task"
@@ -45,6 +47,10 @@ type Plugin struct {
 	ExeLocation                        string
 	Name                               string
 	DefaultHealthCheckOrchestrationDir string
+	// tracked is the cloudwatch.exe process started by the plugin
+	tracked trackedProcess
+	// published tracks the latest successful publish of cloudwatch.exe
+	published *publishTracker
 }
 
 const (
@@ -100,22 +106,69 @@ func NewPlugin(pluginConfig iohandler.PluginConfig) (*Plugin, error) {
 		plugin.Name)
 	_ = fileutil.MakeDirsWithExecuteAccess(plugin.DefaultHealthCheckOrchestrationDir)
 	plugin.CommandExecuter = exec
+	plugin.published = &publishTracker{}
 
 	return &plugin, nil
 }
 
+func init() {
+	managerContracts.RegisterLongRunningPlugin(Name(), newLongRunningPlugin)
+}
+
+// newLongRunningPlugin creates the cloudwatch long running plugin registered with the long running plugin manager
+func newLongRunningPlugin(context context.T, pluginConfig iohandler.PluginConfig) (p managerContracts.Plugin, err error) {
+	var handler *Plugin
+	if handler, err = NewPlugin(pluginConfig); err != nil {
+		return
+	}
+	p.Info = managerContracts.PluginInfo{
+		Name:                 Name(),
+		ExpectedBinarySHA256: context.AppConfig().Ssm.CloudWatchExeSHA256,
+	}
+	p.Handler = handler
+	return
+}
+
 // Name returns the plugin name
 func Name() string {
 	return appconfig.PluginNameCloudWatch
 }
 
+// ExecutablePath returns the location of cloudwatch.exe
+func (p *Plugin) ExecutablePath() string {
+	return p.ExeLocation
+}
+
 // IsRunning returns if the said plugin is running or not
 func (p *Plugin) IsRunning(context context.T) bool {
 	log := context.Log()
+	//trust the liveness of the started cloudwatch.exe over looking up processes by name as long as its PID is known
+	if p.tracked.Pid != 0 {
+		if p.tracked.isAlive() {
+			return true
+		}
+		log.Infof("Process %v of cloudwatch.exe isn't running anymore", p.tracked.Pid)
+		return false
+	}
 	//working directory here doesn't really matter much since we run a powershell script to determine if exe is running
 	return p.IsCloudWatchExeRunning(log, p.DefaultHealthCheckOrchestrationDir, p.DefaultHealthCheckOrchestrationDir, task.NewChanneledCancelFlag())
 }
 
+// LastPublished returns the time cloudwatch.exe last reported a successful publish, the zero time if it didn't yet
+func (p *Plugin) LastPublished() time.Time {
+	return p.published.LastPublished()
+}
+
+// HealthCheck reports cloudwatch as unhealthy if it stopped publishing for longer than publishStaleThreshold
+func (p *Plugin) HealthCheck(context context.T) error {
+	return p.published.checkPublished(time.Now(), publishStaleThreshold)
+}
+
+// ValidateConfig checks the configuration before cloudwatch is started or reconfigured with it
+func (p *Plugin) ValidateConfig(configuration string) error {
+	return ValidateConfiguration(configuration)
+}
+
 // Start starts the executable file and returns encountered errors
 func (p *Plugin) Start(context context.T, configuration string, orchestrationDir string, cancelFlag task.CancelFlag, out iohandler.IOHandler) (err error) {
 	log := context.Log()
@@ -210,13 +263,14 @@ func (p *Plugin) Start(context context.T, configuration string, orchestrationDir
 	fileutil.DeleteFile(stdoutFilePath)
 	fileutil.DeleteFile(stderrFilePath)
 
-	process, exitCode, err := p.CommandExecuter.StartExe(log, p.WorkingDir, out.GetStdoutWriter(), out.GetStderrWriter(), cancelFlag, commandName, commandArguments)
-	if err != nil || exitCode != 0 {
-		return fmt.Errorf("Errors occurred while starting Cloudwatch exit code %v, error %v", exitCode, err)
+	process, err := startWatched(log, p.CommandExecuter, p.WorkingDir, &publishWatcher{writer: out.GetStdoutWriter(), tracker: p.published}, out.GetStderrWriter(), cancelFlag, commandName, commandArguments, startupWatchWindow)
+	if err != nil {
+		return err
 	}
 
 	// Cloudwatch process details
 	p.Process = *process
+	p.tracked = newTrackedProcess(process.Pid)
 	log.Infof("Process id of cloudwatch.exe -> %v", p.Process.Pid)
 
 	return nil
@@ -253,6 +307,7 @@ func (p *Plugin) Stop(context context.T, cancelFlag task.CancelFlag) (err error)
 			log.Infof("Successfully killed the process %v", p.Process.Pid)
 		}
 	}
+	p.tracked = trackedProcess{}
 	if p.IsRunning(context) || processKillError != nil {
 		log.Errorf("There was an error while killing Cloudwatch: %s", processKillError)
 		return processKillError
@@ -0,0 +1,41 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+// trackedProcess identifies a started process by its PID and start time, so that a PID
+// recycled by the OS for another process isn't mistaken for the started one
+type trackedProcess struct {
+	Pid       int
+	StartTime uint64
+}
+
+// newTrackedProcess records the start time of the process with the given PID
+func newTrackedProcess(pid int) trackedProcess {
+	startTime, _ := processStartTime(pid)
+	return trackedProcess{Pid: pid, StartTime: startTime}
+}
+
+// isAlive returns true if the tracked process still exists and hasn't been replaced by another process with the same
+// PID. A zero start time means it couldn't be determined on this platform and only the PID is checked.
+func (p trackedProcess) isAlive() bool {
+	if p.Pid <= 0 || !processExists(p.Pid) {
+		return false
+	}
+	if p.StartTime == 0 {
+		return true
+	}
+	startTime, err := processStartTime(p.Pid)
+	return err == nil && startTime == p.StartTime
+}
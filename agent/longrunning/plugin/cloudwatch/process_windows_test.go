@@ -0,0 +1,53 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackedProcessIsAliveForLivePid(t *testing.T) {
+	process := newTrackedProcess(os.Getpid())
+
+	assert.True(t, process.isAlive())
+}
+
+func TestTrackedProcessIsNotAliveForDeadPid(t *testing.T) {
+	cmd := exec.Command("cmd.exe", "/c", "exit")
+	assert.NoError(t, cmd.Start())
+	process := newTrackedProcess(cmd.Process.Pid)
+	assert.NoError(t, cmd.Wait())
+
+	assert.False(t, process.isAlive())
+	assert.False(t, trackedProcess{}.isAlive())
+}
+
+func TestTrackedProcessIsNotAliveForRecycledPid(t *testing.T) {
+	process := newTrackedProcess(os.Getpid())
+	if process.StartTime == 0 {
+		t.Skip("process start times aren't available on this platform")
+	}
+
+	// the PID is alive, but it belongs to a process that started at another time
+	process.StartTime++
+
+	assert.False(t, process.isAlive())
+}
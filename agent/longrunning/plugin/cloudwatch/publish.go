@@ -0,0 +1,83 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// publishedOutputMarker is printed by cloudwatch.exe whenever it successfully published data
+var publishedOutputMarker = []byte("Successfully published")
+
+// publishStaleThreshold is how long cloudwatch may go without publishing before it's unhealthy
+var publishStaleThreshold = 15 * time.Minute
+
+// publishTracker tracks the time of the latest successful publish. Publishes are recorded by the goroutine copying
+// the output of cloudwatch.exe while the manager reads the time, so it's only accessed atomically.
+type publishTracker struct {
+	// lastPublished is the time of the latest publish in nanoseconds since the epoch, 0 if there wasn't any
+	lastPublished int64
+}
+
+// recordPublished records a successful publish at the given time, a nil tracker discards it
+func (t *publishTracker) recordPublished(at time.Time) {
+	if t == nil {
+		return
+	}
+	atomic.StoreInt64(&t.lastPublished, at.UnixNano())
+}
+
+// LastPublished returns the time of the latest successful publish, the zero time if there wasn't any
+func (t *publishTracker) LastPublished() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	nanos := atomic.LoadInt64(&t.lastPublished)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// checkPublished returns an error if the latest publish is older than threshold. Nothing is checked until the first
+// publish got recorded, since the output of cloudwatch.exe may not report publishes at all.
+func (t *publishTracker) checkPublished(now time.Time, threshold time.Duration) error {
+	lastPublished := t.LastPublished()
+	if lastPublished.IsZero() {
+		return nil
+	}
+	if elapsed := now.Sub(lastPublished); elapsed > threshold {
+		return fmt.Errorf("cloudwatch didn't publish for %v, the latest publish was at %v", elapsed, lastPublished.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// publishWatcher passes the output of cloudwatch.exe through and records a publish whenever the output reports one
+type publishWatcher struct {
+	writer  io.Writer
+	tracker *publishTracker
+}
+
+// Write records a publish if the output contains publishedOutputMarker and writes it to the underlying writer
+func (w *publishWatcher) Write(p []byte) (int, error) {
+	if bytes.Contains(p, publishedOutputMarker) {
+		w.tracker.recordPublished(time.Now())
+	}
+	return w.writer.Write(p)
+}
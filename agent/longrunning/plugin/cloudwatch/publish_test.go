@@ -0,0 +1,77 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishTrackerIsSafeForConcurrentAccess(t *testing.T) {
+	tracker := &publishTracker{}
+	start := time.Now().Round(0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			tracker.recordPublished(start.Add(time.Duration(i) * time.Second))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if lastPublished := tracker.LastPublished(); !lastPublished.IsZero() {
+			assert.True(t, lastPublished.After(start))
+		}
+	}
+	wg.Wait()
+
+	assert.True(t, start.Add(100*time.Second).Equal(tracker.LastPublished()))
+}
+
+func TestPublishTrackerChecksStalePublishes(t *testing.T) {
+	tracker := &publishTracker{}
+	now := time.Now()
+
+	assert.True(t, tracker.LastPublished().IsZero())
+	assert.NoError(t, tracker.checkPublished(now, time.Minute), "nothing is checked before the first publish")
+
+	tracker.recordPublished(now.Add(-30 * time.Second))
+	assert.NoError(t, tracker.checkPublished(now, time.Minute))
+
+	tracker.recordPublished(now.Add(-2 * time.Minute))
+	assert.Error(t, tracker.checkPublished(now, time.Minute))
+
+	var missing *publishTracker
+	missing.recordPublished(now)
+	assert.True(t, missing.LastPublished().IsZero())
+}
+
+func TestPublishWatcherRecordsPublishesFromOutput(t *testing.T) {
+	output := &bytes.Buffer{}
+	tracker := &publishTracker{}
+	watcher := &publishWatcher{writer: output, tracker: tracker}
+
+	watcher.Write([]byte("Starting cloudwatch\n"))
+	assert.True(t, tracker.LastPublished().IsZero())
+
+	watcher.Write([]byte("Successfully published 20 metrics\n"))
+	assert.False(t, tracker.LastPublished().IsZero())
+	assert.Equal(t, "Starting cloudwatch\nSuccessfully published 20 metrics\n", output.String())
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// ReloadConfiguration re-reads the cloudwatch configuration file and hands the validated configuration to
+// reconfigure, which applies it to the running plugin. Nothing is reconfigured while cloudwatch is disabled.
+func ReloadConfiguration(log log.T, reconfigure func(configuration string) error) error {
+	config := Instance()
+	if err := config.Update(log); err != nil {
+		return fmt.Errorf("unable to read cloudwatch configuration: %v", err)
+	}
+	if !config.GetIsEnabled() {
+		log.Infof("Cloudwatch is disabled, skipping configuration reload")
+		return nil
+	}
+
+	configuration, err := config.ParseEngineConfiguration()
+	if err != nil {
+		return fmt.Errorf("unable to parse cloudwatch configuration: %v", err)
+	}
+	if err = ValidateConfiguration(configuration); err != nil {
+		return fmt.Errorf("invalid cloudwatch configuration: %v", err)
+	}
+	if err = reconfigure(configuration); err != nil {
+		return fmt.Errorf("unable to reconfigure cloudwatch: %v", err)
+	}
+	log.Infof("Reloaded cloudwatch configuration")
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchReloadReloadsOnSighup(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	stop := WatchReload(log.NewMockLog(), func() {
+		reloaded <- struct{}{}
+	})
+	defer stop()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "configuration wasn't reloaded on SIGHUP")
+	}
+}
+
+func TestWatchReloadStopsReloading(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	stop := WatchReload(log.NewMockLog(), func() {
+		reloaded <- struct{}{}
+	})
+	stop()
+	stop()
+
+	// keep SIGHUP from terminating the test binary once the watch stopped
+	keep := WatchReload(log.NewMockLog(), func() {})
+	defer keep()
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloaded:
+		assert.Fail(t, "configuration was reloaded after the watch stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
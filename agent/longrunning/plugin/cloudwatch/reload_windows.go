@@ -0,0 +1,75 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// ReloadEventName is the named event that makes the agent reload the cloudwatch configuration once it's set
+	ReloadEventName = "Global\\AmazonSSMAgentCloudWatchReload"
+
+	// reloadEventWaitMilliseconds is how long a wait for the reload event lasts before checking for a stop
+	reloadEventWaitMilliseconds = 1000
+)
+
+// WatchReload calls reload whenever ReloadEventName gets set, until the returned stop function is called
+func WatchReload(log log.T, reload func()) (stop func()) {
+	name, err := windows.UTF16PtrFromString(ReloadEventName)
+	if err != nil {
+		log.Errorf("Unable to watch for cloudwatch configuration reloads: %v", err)
+		return func() {}
+	}
+	// auto reset event, so that every SetEvent triggers exactly one reload
+	event, err := windows.CreateEvent(nil, 0, 0, name)
+	if err != nil {
+		log.Errorf("Unable to create the cloudwatch configuration reload event %s: %v", ReloadEventName, err)
+		return func() {}
+	}
+	quit := make(chan struct{})
+
+	go func() {
+		defer windows.CloseHandle(event)
+		for {
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			result, err := windows.WaitForSingleObject(event, reloadEventWaitMilliseconds)
+			if err != nil {
+				log.Errorf("Stopped watching for cloudwatch configuration reloads: %v", err)
+				return
+			}
+			if result == windows.WAIT_OBJECT_0 {
+				log.Infof("Reload event %s was set, reloading cloudwatch configuration", ReloadEventName)
+				reload()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+		})
+	}
+}
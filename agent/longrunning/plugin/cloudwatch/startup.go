@@ -0,0 +1,109 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+)
+
+const (
+	// startupStderrLimit is the most stderr output of a failed start that is kept
+	startupStderrLimit = 4096
+
+	// startupOutputGracePeriod is how long the output of an exited process gets to drain before it's reported
+	startupOutputGracePeriod = 100 * time.Millisecond
+)
+
+// startupWatchWindow is how long a started process is watched for exiting right away
+var startupWatchWindow = 2 * time.Second
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	lock      sync.Mutex
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write keeps what still fits into the buffer, it never fails so that the process output isn't interrupted
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if remaining := b.limit - b.buffer.Len(); remaining < len(p) {
+		b.buffer.Write(p[:remaining])
+		b.truncated = true
+	} else {
+		b.buffer.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, marked if some of it was discarded
+func (b *limitedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.truncated {
+		return b.buffer.String() + "...(truncated)"
+	}
+	return b.buffer.String()
+}
+
+// startWatched starts the executable and watches it for window. A process that fails to start or exits within the
+// window is reported by an error including its exit code and the stderr it printed, e.g. because of a bad configuration.
+func startWatched(
+	log log.T,
+	executer executers.T,
+	workingDir string,
+	stdoutWriter io.Writer,
+	stderrWriter io.Writer,
+	cancelFlag task.CancelFlag,
+	commandName string,
+	commandArguments []string,
+	window time.Duration) (*os.Process, error) {
+
+	stderr := &limitedBuffer{limit: startupStderrLimit}
+	process, exitCode, err := executer.StartExe(log, workingDir, stdoutWriter, io.MultiWriter(stderrWriter, stderr), cancelFlag, commandName, commandArguments)
+	if err != nil || exitCode != 0 {
+		return process, fmt.Errorf("Errors occurred while starting Cloudwatch exit code %v, error %v, stderr: %s", exitCode, err, stderr.String())
+	}
+
+	exited := make(chan *os.ProcessState, 1)
+	go func() {
+		// waiting also reaps the process once it exits after the window
+		state, _ := process.Wait()
+		exited <- state
+	}()
+
+	select {
+	case state := <-exited:
+		time.Sleep(startupOutputGracePeriod)
+		exitCode = -1
+		if state != nil {
+			exitCode = state.ExitCode()
+		}
+		return process, fmt.Errorf("Cloudwatch exited right after starting with exit code %v, stderr: %s", exitCode, stderr.String())
+	case <-time.After(window):
+		return process, nil
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func startShellWatched(script string, stderr *bytes.Buffer) error {
+	process, err := startWatched(log.NewMockLog(), executers.ShellCommandExecuter{}, "", &bytes.Buffer{}, stderr,
+		task.NewChanneledCancelFlag(), "sh", []string{"-c", script}, time.Second)
+	if err == nil {
+		process.Kill()
+	}
+	return err
+}
+
+func TestStartWatchedRecordsStderrOfExitedProcess(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	err := startShellWatched("echo invalid configuration >&2; exit 3", stderr)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exit code 3")
+	assert.Contains(t, err.Error(), "invalid configuration")
+	assert.Contains(t, stderr.String(), "invalid configuration", "stderr must still reach the plugin output")
+}
+
+func TestStartWatchedTruncatesStderr(t *testing.T) {
+	err := startShellWatched("head -c 10000 /dev/zero | tr '\\0' x >&2; exit 1", &bytes.Buffer{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), strings.Repeat("x", startupStderrLimit)+"...(truncated)")
+	assert.NotContains(t, err.Error(), strings.Repeat("x", startupStderrLimit+1))
+}
+
+func TestStartWatchedAcceptsRunningProcess(t *testing.T) {
+	assert.NoError(t, startShellWatched("sleep 10", &bytes.Buffer{}))
+}
+
+func TestStartWatchedReportsMissingExecutable(t *testing.T) {
+	_, err := startWatched(log.NewMockLog(), executers.ShellCommandExecuter{}, "", &bytes.Buffer{}, &bytes.Buffer{},
+		task.NewChanneledCancelFlag(), "/nonexistent/AWS.CloudWatch", nil, time.Second)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no such file or directory")
+}
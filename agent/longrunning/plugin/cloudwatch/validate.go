@@ -0,0 +1,96 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// metricsOutputComponent is the type of the components publishing metrics to CloudWatch
+	metricsOutputComponent = "CloudWatchOutputComponent"
+	// logsOutputComponent is the type of the components publishing logs to CloudWatch Logs
+	logsOutputComponent = "CloudWatchLogsOutput"
+	// performanceCounterComponent is the type of the components collecting metrics from performance counters
+	performanceCounterComponent = "PerformanceCounterInputComponent"
+)
+
+// componentConfiguration is an input or output component of the cloudwatch engine configuration
+type componentConfiguration struct {
+	Id         string                 `json:"Id"`
+	FullName   string                 `json:"FullName"`
+	Parameters map[string]interface{} `json:"Parameters"`
+}
+
+// engineConfiguration describes the components of cloudwatch and the flows between them
+type engineConfiguration struct {
+	PollInterval string                   `json:"PollInterval"`
+	Components   []componentConfiguration `json:"Components"`
+}
+
+// pluginConfiguration is the configuration the cloudwatch plugin is started with
+type pluginConfiguration struct {
+	EngineConfiguration *engineConfiguration `json:"EngineConfiguration"`
+}
+
+// requiredParameters returns the parameters the component can't work without, based on its type
+func (c componentConfiguration) requiredParameters() []string {
+	switch {
+	case strings.Contains(c.FullName, metricsOutputComponent):
+		return []string{"Region", "NameSpace"}
+	case strings.Contains(c.FullName, logsOutputComponent):
+		return []string{"Region", "LogGroup"}
+	case strings.Contains(c.FullName, performanceCounterComponent):
+		return []string{"CategoryName", "CounterName", "MetricName"}
+	}
+	return nil
+}
+
+// ValidateConfiguration parses the configuration of the cloudwatch plugin and checks that the components
+// have the fields cloudwatch requires, e.g. the region and log group of the outputs and the metric definitions
+func ValidateConfiguration(config string) error {
+	if strings.TrimSpace(config) == "" {
+		return errors.New("configuration is empty")
+	}
+
+	var parsed pluginConfiguration
+	if err := json.Unmarshal([]byte(config), &parsed); err != nil {
+		return fmt.Errorf("configuration isn't valid json: %v", err)
+	}
+	if parsed.EngineConfiguration == nil {
+		return errors.New("configuration is missing EngineConfiguration")
+	}
+	if len(parsed.EngineConfiguration.Components) == 0 {
+		return errors.New("configuration doesn't define any Components")
+	}
+
+	for i, component := range parsed.EngineConfiguration.Components {
+		if component.Id == "" {
+			return fmt.Errorf("component %d is missing Id", i)
+		}
+		if component.FullName == "" {
+			return fmt.Errorf("component %s is missing FullName", component.Id)
+		}
+		for _, name := range component.requiredParameters() {
+			if value, _ := component.Parameters[name].(string); strings.TrimSpace(value) == "" {
+				return fmt.Errorf("component %s is missing parameter %s", component.Id, name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cloudwatch implements cloudwatch plugin and its configuration
+package cloudwatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validConfiguration = `{"EngineConfiguration": {"PollInterval": "00:00:15", "Components": [
+	{"Id": "ApplicationEventLog", "FullName": "AWS.EC2.Windows.CloudWatch.EventLog.EventLogInputComponent,AWS.EC2.Windows.CloudWatch",
+		"Parameters": {"LogName": "Application", "Levels": "4"}},
+	{"Id": "PerformanceCounter", "FullName": "AWS.EC2.Windows.CloudWatch.PerformanceCounterComponent.PerformanceCounterInputComponent,AWS.EC2.Windows.CloudWatch",
+		"Parameters": {"CategoryName": "Memory", "CounterName": "Available MBytes", "InstanceName": "", "MetricName": "Memory", "Unit": "Megabytes"}},
+	{"Id": "CloudWatchLogs", "FullName": "AWS.EC2.Windows.CloudWatch.CloudWatchLogsOutput,AWS.EC2.Windows.CloudWatch",
+		"Parameters": {"Region": "us-east-1", "LogGroup": "Test-Group", "LogStream": "{instance_id}"}},
+	{"Id": "CloudWatch", "FullName": "AWS.EC2.Windows.CloudWatch.CloudWatch.CloudWatchOutputComponent,AWS.EC2.Windows.CloudWatch",
+		"Parameters": {"Region": "us-east-1", "NameSpace": "Windows/Default"}}],
+	"Flows": {"Flows": ["(ApplicationEventLog),CloudWatchLogs", "PerformanceCounter,CloudWatch"]}}}`
+
+func TestValidateConfigurationAcceptsValidConfiguration(t *testing.T) {
+	assert.NoError(t, ValidateConfiguration(validConfiguration))
+}
+
+func TestValidateConfigurationRejectsInvalidJson(t *testing.T) {
+	for _, config := range []string{"", "  ", `{"EngineConfiguration":`, `"EngineConfiguration"`} {
+		assert.Error(t, ValidateConfiguration(config), config)
+	}
+}
+
+func TestValidateConfigurationRejectsMissingFields(t *testing.T) {
+	testCases := map[string]string{
+		`{}`: "missing EngineConfiguration",
+		`{"EngineConfiguration": {"Components": []}}`:                                           "doesn't define any Components",
+		`{"EngineConfiguration": {"Components": [{"FullName": "AWS.EC2.Windows.CloudWatch"}]}}`: "component 0 is missing Id",
+		`{"EngineConfiguration": {"Components": [{"Id": "CloudWatch"}]}}`:                       "component CloudWatch is missing FullName",
+		`{"EngineConfiguration": {"Components": [{"Id": "CloudWatchLogs", "FullName": "AWS.EC2.Windows.CloudWatch.CloudWatchLogsOutput",
+			"Parameters": {"Region": "us-east-1"}}]}}`: "component CloudWatchLogs is missing parameter LogGroup",
+		`{"EngineConfiguration": {"Components": [{"Id": "CloudWatch", "FullName": "AWS.EC2.Windows.CloudWatch.CloudWatch.CloudWatchOutputComponent",
+			"Parameters": {"Region": "", "NameSpace": "Windows/Default"}}]}}`: "component CloudWatch is missing parameter Region",
+		`{"EngineConfiguration": {"Components": [{"Id": "PerformanceCounter", "FullName": "AWS.EC2.Windows.CloudWatch.PerformanceCounterComponent.PerformanceCounterInputComponent",
+			"Parameters": {"CategoryName": "Memory", "CounterName": "Available MBytes"}}]}}`: "component PerformanceCounter is missing parameter MetricName",
+	}
+
+	for config, message := range testCases {
+		err := ValidateConfiguration(config)
+		if assert.Error(t, err, config) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -31,6 +31,8 @@ import (
 type PluginState struct {
 	LastConfigurationModifiedTime time.Time
 	IsEnabled                     bool
+	// LazyActive is true once a lazy plugin got activated
+	LazyActive bool
 }
 
 //PluginInfo reflects information about long running plugins
@@ -39,8 +41,47 @@ type PluginInfo struct {
 	Name          string
 	Configuration string
 	State         PluginState
+	// Critical plugins make the manager report itself as unhealthy while they aren't running
+	Critical bool
+	// Priority orders restarts of plugins when restarts are rate limited, higher priority plugins restart first
+	Priority int
+	// Tags are labels of the plugin set by operators, e.g. to group plugins - the manager keeps them but doesn't
+	// interpret them
+	Tags map[string]string `json:",omitempty"`
+	// ExpectedBinarySHA256 is the expected sha256 of the plugin executable, checked before each start when set
+	ExpectedBinarySHA256 string
+	// ConfigVersion is the version of the configuration schema - the version a registered plugin expects,
+	// or the version a persisted configuration was written with
+	ConfigVersion int
+	// Lazy plugins aren't started by the manager until they get activated by a document or Activate
+	Lazy bool
+	// RestartAttempts is the number of restarts by the manager since the plugin last ran stable, it's only
+	// filled in by the manager when it reports its running plugins and never persisted
+	RestartAttempts int `json:"-"`
+	// LastRestartAttempt is the time of the latest restart by the manager, see RestartAttempts
+	LastRestartAttempt time.Time `json:"-"`
+	// Starting is true while a start of the plugin is in progress, see RestartAttempts
+	Starting bool `json:"-"`
+	// PreStopTimeout bounds the pre-stop hook of the plugin, 0 means the manager default
+	PreStopTimeout time.Duration
+	// PreStopPolicy decides whether a failing pre-stop hook blocks the stop of the plugin
+	PreStopPolicy PreStopPolicy
+	// OrchestrationDir is the working directory of the plugin for its files and logs, it's persisted so that the
+	// plugin keeps using it across restarts
+	OrchestrationDir string
 }
 
+// PreStopPolicy defines how the manager handles a pre-stop hook that fails or times out
+type PreStopPolicy string
+
+const (
+	// PreStopPolicyProceed stops the plugin even if its pre-stop hook failed, this is the default
+	PreStopPolicyProceed PreStopPolicy = "Proceed"
+
+	// PreStopPolicyBlock keeps the plugin running if its pre-stop hook failed
+	PreStopPolicyBlock PreStopPolicy = "Block"
+)
+
 // Plugin reflects a long running plugin
 type Plugin struct {
 	Info    PluginInfo
@@ -54,6 +95,61 @@ type LongRunningPlugin interface {
 	Stop(context context.T, cancelFlag task.CancelFlag) error
 }
 
+// ExecutableLocator is implemented by long running plugins that launch an executable,
+// it allows the manager to verify the executable before starting the plugin
+type ExecutableLocator interface {
+	ExecutablePath() string
+}
+
+// HealthChecker is implemented by long running plugins that can tell whether they are actually working beyond their
+// process being alive, e.g. whether they are still shipping data. The manager restarts a running plugin that fails its
+// health check. Plugins that don't implement it are healthy as long as IsRunning returns true.
+type HealthChecker interface {
+	HealthCheck(context context.T) error
+}
+
+// PublishReporter is implemented by long running plugins that publish data, it reports the time of the latest
+// successful publish so that the health report can show whether data is actually flowing. The zero time means
+// that no publish was observed yet.
+type PublishReporter interface {
+	LastPublished() time.Time
+}
+
+// Reconfigurer is implemented by long running plugins that can apply a new configuration while they are running,
+// without being stopped and started again
+type Reconfigurer interface {
+	Reconfigure(context context.T, configuration string, cancelFlag task.CancelFlag) error
+}
+
+// PreStopper is implemented by long running plugins that need to run a hook before they get stopped,
+// e.g. to flush buffered data or checkpoint their state
+type PreStopper interface {
+	PreStop(context context.T, cancelFlag task.CancelFlag) error
+}
+
+// ResourceUsage is the resource consumption of a running long running plugin
+type ResourceUsage struct {
+	CPUPercent float64
+	RSSBytes   uint64
+}
+
+// ResourceReporter is implemented by long running plugins that report their resource consumption
+type ResourceReporter interface {
+	ResourceUsage(context context.T) (ResourceUsage, error)
+}
+
+// ConfigMigrator is implemented by long running plugins whose configuration schema changed across versions,
+// it allows the manager to migrate persisted configurations written with an older schema
+type ConfigMigrator interface {
+	MigrateConfig(old string, fromVersion int) (string, error)
+}
+
+// ConfigValidator is implemented by long running plugins that can check a configuration without starting,
+// it allows the manager to validate persisted configurations up front
+type ConfigValidator interface {
+	ValidateConfig(configuration string) error
+}
+
 //PluginSettings reflects settings that can be applied to long running plugins like aws:cloudWatch
 type PluginSettings struct {
 	StartType string
@@ -71,8 +167,8 @@ func RegisteredPlugins(context context.T) map[string]Plugin {
 	log := context.Log()
 	log.Debug("Registering long-running plugins")
 
-	for key, value := range loadPlatformDependentPlugins(context) {
-		log.Debugf("Adding platform-specific long-running plugin for %v", key)
+	for key, value := range loadFactoryPlugins(context) {
+		log.Debugf("Adding registered long-running plugin for %v", key)
 		longrunningplugins[key] = value
 	}
 
@@ -85,10 +181,22 @@ func RegisteredPlugins(context context.T) map[string]Plugin {
 		longrunningplugins[key] = value
 	}
 
+	removeDisabledPlugins(context, longrunningplugins)
+
 	context.Log().Debugf("Registered %v long-running plugins", len(longrunningplugins))
 	return longrunningplugins
 }
 
+// removeDisabledPlugins removes the plugins disabled by the agent configuration
+func removeDisabledPlugins(context context.T, longrunningplugins map[string]Plugin) {
+	for _, name := range context.AppConfig().Agent.DisabledPlugins {
+		if _, exists := longrunningplugins[name]; exists {
+			delete(longrunningplugins, name)
+			context.Log().Infof("Long-running plugin %v is disabled by the agent configuration", name)
+		}
+	}
+}
+
 // loadPlatformIndependentPlugins loads all long running plugins that don't have platform specific implementations
 func loadPlatformIndependentPlugins(context context.T) map[string]Plugin {
 	//long running plugins that can be started/stopped/configured by long running plugin manager
@@ -134,6 +242,7 @@ func loadDaemonPlugins(context context.T) map[string]Plugin {
 						Name:          input.Name,
 						Configuration: input.Command,
 						State:         PluginState{IsEnabled: true},
+						Lazy:          input.Lazy,
 					},
 					Handler: &rundaemon.Plugin{
 						ExeLocation: input.PackageLocation,
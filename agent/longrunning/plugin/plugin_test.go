@@ -0,0 +1,74 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains all essential structs/interfaces for long running plugins
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// contextWithDisabledPlugins returns a mocked context whose agent configuration disables the given plugins
+func contextWithDisabledPlugins(disabled ...string) context.T {
+	config := appconfig.SsmagentConfig{}
+	config.Agent.DisabledPlugins = disabled
+	ctx := new(context.Mock)
+	ctx.On("Log").Return(log.NewMockLog())
+	ctx.On("AppConfig").Return(config)
+	ctx.On("With", mock.AnythingOfType("string")).Return(ctx)
+	return ctx
+}
+
+func TestPluginsAreEnabledByDefault(t *testing.T) {
+	plugins := map[string]Plugin{appconfig.PluginNameCloudWatch: {}, "aws:daemon": {}}
+
+	removeDisabledPlugins(context.NewMockDefault(), plugins)
+
+	assert.Len(t, plugins, 2)
+}
+
+func TestDisabledPluginsAreRemoved(t *testing.T) {
+	plugins := map[string]Plugin{appconfig.PluginNameCloudWatch: {}, "aws:daemon": {}}
+
+	removeDisabledPlugins(contextWithDisabledPlugins(appconfig.PluginNameCloudWatch, "aws:unknown"), plugins)
+
+	assert.Equal(t, map[string]Plugin{"aws:daemon": {}}, plugins)
+}
+
+func TestReportedRestartStateIsNotPersisted(t *testing.T) {
+	info := PluginInfo{
+		Name:               "plugin",
+		Configuration:      "config",
+		RestartAttempts:    3,
+		LastRestartAttempt: time.Now(),
+		Starting:           true,
+	}
+
+	data, err := json.Marshal(info)
+	assert.NoError(t, err)
+	var persisted map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &persisted))
+
+	assert.Equal(t, "config", persisted["Configuration"])
+	assert.NotContains(t, persisted, "RestartAttempts")
+	assert.NotContains(t, persisted, "LastRestartAttempt")
+	assert.NotContains(t, persisted, "Starting")
+}
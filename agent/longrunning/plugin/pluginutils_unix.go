@@ -16,15 +16,9 @@
 package plugin
 
 import (
-	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
 
-// loadPlatformDepedentPlugins loads all registered long running plugins in memory
-func loadPlatformDependentPlugins(context context.T) map[string]Plugin {
-	return make(map[string]Plugin)
-}
-
 // IsLongRunningPluginSupportedForCurrentPlatform always returns false because currently, there are no long-running plugins
 // supported on Linux
 func IsLongRunningPluginSupportedForCurrentPlatform(log log.T, pluginName string) (bool, string) {
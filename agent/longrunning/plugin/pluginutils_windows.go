@@ -20,41 +20,10 @@ import (
 	"fmt"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
-	"github.com/aws/amazon-ssm-agent/agent/context"
-	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
-	"github.com/aws/amazon-ssm-agent/agent/longrunning/plugin/cloudwatch"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 )
 
-// loadPlatformDepedentPlugins loads all registered long running plugins in memory
-func loadPlatformDependentPlugins(context context.T) map[string]Plugin {
-	log := context.Log()
-	//long running plugins that can be started/stopped/configured by long running plugin manager
-	longrunningplugins := make(map[string]Plugin)
-
-	//registering cloudwatch plugin
-	var cw Plugin
-	var cwInfo PluginInfo
-
-	//initializing cloudwatch info
-	cwInfo.Name = appconfig.PluginNameCloudWatch
-	cwInfo.Configuration = ""
-	cwInfo.State = PluginState{}
-
-	if handler, err := cloudwatch.NewPlugin(iohandler.DefaultOutputConfig()); err == nil {
-		cw.Info = cwInfo
-		cw.Handler = handler
-
-		//add the registered plugin in the map
-		longrunningplugins[appconfig.PluginNameCloudWatch] = cw
-	} else {
-		log.Errorf("failed to create long-running plugin %s %v", appconfig.PluginNameCloudWatch, err)
-	}
-
-	return longrunningplugins
-}
-
 // IsLongRunningPluginSupportedForCurrentPlatform returns true if current platform supports the plugin with given name.
 func IsLongRunningPluginSupportedForCurrentPlatform(log log.T, pluginName string) (bool, string) {
 	platformName, _ := platform.PlatformName(log)
@@ -0,0 +1,141 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains all essential structs/interfaces for long running plugins
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+)
+
+// Factory creates a long running plugin with the given output configuration
+type Factory func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error)
+
+var factoriesLock sync.Mutex
+
+// factories are the registered long running plugin factories by plugin name
+var factories = map[string]Factory{}
+
+// RegisterLongRunningPlugin registers the factory of a long running plugin, plugins are expected to register
+// themselves from init(). Registering the same name twice panics.
+func RegisterLongRunningPlugin(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	if factory == nil {
+		panic(fmt.Sprintf("nil factory registered for long-running plugin %v", name))
+	}
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("long-running plugin %v registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// InstanceSeparator separates the name of a registered long running plugin from the identifier of one of its
+// instances, e.g. aws:cloudWatch:system is the instance system of aws:cloudWatch
+const InstanceSeparator = ":"
+
+// InstanceName returns the name of the given instance of a registered long running plugin, the empty instance is the
+// registered plugin itself
+func InstanceName(name, instance string) string {
+	if instance == "" {
+		return name
+	}
+	return name + InstanceSeparator + instance
+}
+
+// SplitInstanceName splits the name of a plugin instance into the name of the registered long running plugin and the
+// identifier of the instance. Names that aren't instances of a plugin registered with RegisterLongRunningPlugin are
+// returned as they are with an empty instance.
+func SplitInstanceName(name string) (base, instance string) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	for registered := range factories {
+		prefix := registered + InstanceSeparator
+		//the longest registered name wins in case registered names are prefixes of each other
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) && len(registered) > len(base) {
+			base, instance = registered, name[len(prefix):]
+		}
+	}
+	if base == "" {
+		return name, ""
+	}
+	return base, instance
+}
+
+// NewInstance creates a separate instance of a long running plugin registered with RegisterLongRunningPlugin, so that
+// it can run next to the registered plugin with its own configuration. The name is the name of the instance as
+// returned by InstanceName.
+func NewInstance(context context.T, name string) (p Plugin, err error) {
+	base, instance := SplitInstanceName(name)
+	if instance == "" {
+		return p, fmt.Errorf("%v isn't an instance of a registered long-running plugin", name)
+	}
+	factoriesLock.Lock()
+	factory := factories[base]
+	factoriesLock.Unlock()
+
+	if p, err = createPlugin(context, base, factory); err != nil {
+		return p, fmt.Errorf("failed to create long-running plugin %v: %v", name, err)
+	}
+	p.Info.Name = name
+	return p, nil
+}
+
+// loadFactoryPlugins creates the long running plugins registered with RegisterLongRunningPlugin
+func loadFactoryPlugins(context context.T) map[string]Plugin {
+	log := context.Log()
+	longrunningplugins := make(map[string]Plugin)
+
+	factoriesLock.Lock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	registered := make(map[string]Factory, len(factories))
+	for name, factory := range factories {
+		registered[name] = factory
+	}
+	factoriesLock.Unlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		p, err := createPlugin(context, name, registered[name])
+		if err != nil {
+			log.Errorf("failed to create long-running plugin %s %v", name, err)
+			continue
+		}
+		p.Info.Name = name
+		longrunningplugins[name] = p
+	}
+	return longrunningplugins
+}
+
+// createPlugin creates a long running plugin with the given factory and the plugin config of the given name, a
+// panicking factory is reported as an error so that it doesn't prevent the remaining plugins from loading
+func createPlugin(context context.T, name string, factory Factory) (p Plugin, err error) {
+	defer func() {
+		if msg := recover(); msg != nil {
+			context.Log().Errorf("%s: %s", msg, debug.Stack())
+			err = fmt.Errorf("factory panicked - %v", msg)
+		}
+	}()
+	return factory(context, pluginutil.PluginConfigFor(name))
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin contains all essential structs/interfaces for long running plugins
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/stretchr/testify/assert"
+)
+
+// swapFactories replaces the registered factories for the duration of a test, the returned function restores them
+func swapFactories() func() {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	original := factories
+	factories = map[string]Factory{}
+	return func() {
+		factoriesLock.Lock()
+		defer factoriesLock.Unlock()
+		factories = original
+	}
+}
+
+func TestRegisteredFactoriesCreatePlugins(t *testing.T) {
+	defer swapFactories()()
+	var received iohandler.PluginConfig
+	RegisterLongRunningPlugin("custom", func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		received = pluginConfig
+		return Plugin{Info: PluginInfo{Configuration: "config"}}, nil
+	})
+	RegisterLongRunningPlugin("broken", func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		return Plugin{}, errors.New("unable to create plugin")
+	})
+
+	plugins := loadFactoryPlugins(context.NewMockDefault())
+
+	assert.Len(t, plugins, 1)
+	assert.Equal(t, "custom", plugins["custom"].Info.Name)
+	assert.Equal(t, "config", plugins["custom"].Info.Configuration)
+	assert.Equal(t, iohandler.DefaultOutputConfig(), received)
+}
+
+func TestPanickingFactoryDoesNotPreventLoading(t *testing.T) {
+	defer swapFactories()()
+	RegisterLongRunningPlugin("panicking", func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		panic("unable to create plugin")
+	})
+	RegisterLongRunningPlugin("custom", func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		return Plugin{}, nil
+	})
+
+	plugins := loadFactoryPlugins(context.NewMockDefault())
+
+	assert.Len(t, plugins, 1)
+	assert.Contains(t, plugins, "custom")
+}
+
+func TestRegisteringPluginTwicePanics(t *testing.T) {
+	defer swapFactories()()
+	factory := func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		return Plugin{}, nil
+	}
+	RegisterLongRunningPlugin("custom", factory)
+
+	assert.Panics(t, func() { RegisterLongRunningPlugin("custom", factory) })
+	assert.Panics(t, func() { RegisterLongRunningPlugin("other", nil) })
+}
+
+func TestInstanceNames(t *testing.T) {
+	defer swapFactories()()
+	factory := func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		return Plugin{}, nil
+	}
+	RegisterLongRunningPlugin("aws:custom", factory)
+
+	assert.Equal(t, "aws:custom", InstanceName("aws:custom", ""))
+	assert.Equal(t, "aws:custom:system", InstanceName("aws:custom", "system"))
+
+	testCases := []struct {
+		name, base, instance string
+	}{
+		{"aws:custom:system", "aws:custom", "system"},
+		{"aws:custom:logs:app", "aws:custom", "logs:app"},
+		{"aws:custom", "aws:custom", ""},
+		{"aws:custom:", "aws:custom:", ""},
+		{"aws:other:system", "aws:other:system", ""},
+	}
+	for _, tc := range testCases {
+		base, instance := SplitInstanceName(tc.name)
+		assert.Equal(t, tc.base, base, tc.name)
+		assert.Equal(t, tc.instance, instance, tc.name)
+	}
+}
+
+func TestNewInstanceCreatesSeparatePlugins(t *testing.T) {
+	defer swapFactories()()
+	created := 0
+	RegisterLongRunningPlugin("aws:custom", func(context context.T, pluginConfig iohandler.PluginConfig) (Plugin, error) {
+		created++
+		return Plugin{Info: PluginInfo{Critical: true}}, nil
+	})
+
+	system, err := NewInstance(context.NewMockDefault(), "aws:custom:system")
+	assert.NoError(t, err)
+	logs, err := NewInstance(context.NewMockDefault(), "aws:custom:logs")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, created)
+	assert.Equal(t, "aws:custom:system", system.Info.Name)
+	assert.Equal(t, "aws:custom:logs", logs.Info.Name)
+	assert.True(t, system.Info.Critical)
+
+	_, err = NewInstance(context.NewMockDefault(), "aws:custom")
+	assert.Error(t, err)
+	_, err = NewInstance(context.NewMockDefault(), "aws:other:system")
+	assert.Error(t, err)
+}
@@ -29,6 +29,8 @@ type ConfigureDaemonPluginInput struct {
 	Action          string `json:"action"`
 	PackageLocation string `json:"packagelocation"`
 	Command         string `json:"command"`
+	// Lazy daemons are only started once they get activated
+	Lazy bool `json:"lazy"`
 }
 
 // ValidateDaemonInput validates the input given to configure daemon
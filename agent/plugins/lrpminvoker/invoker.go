@@ -43,6 +43,12 @@ type InvokerInput struct {
 	Properties interface{} `json:"properties"`
 }
 
+// InvokerOutput is the output lrpminvoker hands off to lrpm
+type InvokerOutput struct {
+	Properties             string `json:"properties"`
+	OrchestrationDirectory string `json:"orchestrationDirectory"`
+}
+
 //todo: add interfaces & dependencies to simplify testing for all calls from lrpminvoker calls to lrpm
 
 // NewPlugin returns an instance of lrpminvoker for a given long running plugin name
@@ -89,7 +95,8 @@ func (p *Plugin) Execute(context context.T, config contracts.Configuration, canc
 		output.MarkAsCancelled()
 	} else {
 		property := p.prepareForStart(log, config, cancelFlag, output)
-		output.SetOutput(property)
+		//lrpm starts the plugin under the orchestration directory of this invocation
+		output.SetOutput(InvokerOutput{Properties: property, OrchestrationDirectory: config.OrchestrationDirectory})
 		output.AppendInfo(setting.StartType)
 	}
 
@@ -0,0 +1,47 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package lrpminvoker contains implementation of lrpm-invoker plugin. (lrpm - long running plugin manager)
+package lrpminvoker
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteHandsOffOrchestrationDirectory(t *testing.T) {
+	ctx := context.NewMockDefault()
+	p, _ := NewPlugin(appconfig.PluginNameCloudWatch)
+	config := contracts.Configuration{
+		Settings:               map[string]interface{}{"StartType": "Enabled"},
+		Properties:             `{"EngineConfiguration": {}}`,
+		OrchestrationDirectory: "orchestration/document/aws:cloudWatch",
+		PluginID:               "aws:cloudWatch",
+	}
+	output := iohandler.NewDefaultIOHandler(ctx.Log(), contracts.IOConfiguration{})
+
+	p.Execute(ctx, config, task.NewChanneledCancelFlag(), output)
+
+	assert.Equal(t, contracts.ResultStatusSuccess, output.GetStatus())
+	assert.Equal(t, InvokerOutput{
+		Properties:             `{"EngineConfiguration": {}}`,
+		OrchestrationDirectory: "orchestration/document/aws:cloudWatch",
+	}, output.GetOutput())
+	assert.Contains(t, output.GetStdout(), "Enabled")
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package lrpminvoker contains implementation of lrpm-invoker plugin. (lrpm - long running plugin manager)
+package lrpminvoker
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+)
+
+// Exit codes of invocations lrpm couldn't take over
+const (
+	// ResultCodeManagerFailed is the exit code of invocations lrpm failed to take over
+	ResultCodeManagerFailed = 1
+
+	// ResultCodePluginNotRegistered is the exit code of invocations of plugins lrpm doesn't know about
+	ResultCodePluginNotRegistered = 2
+
+	// ResultCodeManagerNotReady is the exit code of invocations made before lrpm got initialized, it matches
+	// EX_TEMPFAIL as retrying the invocation later can succeed
+	ResultCodeManagerNotReady = 75
+)
+
+// temporary is implemented by errors of lrpm which go away once lrpm is initialized
+type temporary interface {
+	Temporary() bool
+}
+
+// notRegistered is implemented by errors of lrpm about plugins it doesn't know about
+type notRegistered interface {
+	NotRegistered() bool
+}
+
+// ManagerErrorResult fails the result of an invocation lrpm couldn't take over because of the given error
+func ManagerErrorResult(err error, res *contracts.PluginResult) {
+	code, msg := managerErrorCode(err)
+	res.Output = msg
+	res.StandardOutput = ""
+	res.StandardError = msg
+	res.Code = code
+	res.Status = contracts.ResultStatusFailed
+}
+
+// managerErrorCode returns the exit code and the message an error of lrpm gets reported with
+func managerErrorCode(err error) (code int, msg string) {
+	if e, ok := err.(temporary); ok && e.Temporary() {
+		return ResultCodeManagerNotReady, fmt.Sprintf("%v - the agent is still starting, retry the command once it's running", err)
+	}
+	if e, ok := err.(notRegistered); ok && e.NotRegistered() {
+		return ResultCodePluginNotRegistered, fmt.Sprintf("%v - the plugin isn't supported by this agent", err)
+	}
+	return ResultCodeManagerFailed, fmt.Sprintf("Encountered error while handing off to lrpm: %v", err)
+}
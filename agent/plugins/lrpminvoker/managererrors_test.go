@@ -0,0 +1,68 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package lrpminvoker contains implementation of lrpm-invoker plugin. (lrpm - long running plugin manager)
+package lrpminvoker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+// notInitializedError mimics the error of lrpm before it got initialized
+type notInitializedError struct{}
+
+func (notInitializedError) Error() string   { return "lrpm isn't initialized yet" }
+func (notInitializedError) Temporary() bool { return true }
+
+// unknownPluginError mimics the error of lrpm about plugins it doesn't know about
+type unknownPluginError struct{}
+
+func (unknownPluginError) Error() string       { return "Plugin aws:unknown is not registered by agent" }
+func (unknownPluginError) NotRegistered() bool { return true }
+
+func TestManagerErrorResultForNotInitializedManager(t *testing.T) {
+	res := &contracts.PluginResult{StandardOutput: "Enabled"}
+
+	ManagerErrorResult(notInitializedError{}, res)
+
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, ResultCodeManagerNotReady, res.Code)
+	assert.Contains(t, res.StandardError, "lrpm isn't initialized yet")
+	assert.Contains(t, res.StandardError, "retry")
+	assert.Equal(t, res.StandardError, res.Output)
+	assert.Empty(t, res.StandardOutput)
+}
+
+func TestManagerErrorResultForUnknownPlugin(t *testing.T) {
+	res := &contracts.PluginResult{}
+
+	ManagerErrorResult(unknownPluginError{}, res)
+
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, ResultCodePluginNotRegistered, res.Code)
+	assert.Contains(t, res.StandardError, "aws:unknown is not registered")
+}
+
+func TestManagerErrorResultForOtherErrors(t *testing.T) {
+	res := &contracts.PluginResult{}
+
+	ManagerErrorResult(errors.New("lrpm initialization failed"), res)
+
+	assert.Equal(t, contracts.ResultStatusFailed, res.Status)
+	assert.Equal(t, ResultCodeManagerFailed, res.Code)
+	assert.Contains(t, res.StandardError, "lrpm initialization failed")
+}
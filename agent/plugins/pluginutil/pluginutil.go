@@ -17,6 +17,8 @@ package pluginutil
 import (
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -31,12 +33,70 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
 
+// Assign method to global variables to allow unittest to override
+var getAppConfig = appconfig.Config
+
 const (
 	defaultExecutionTimeoutInSeconds = 3600
 	maxExecutionTimeoutInSeconds     = 172800
 	minExecutionTimeoutInSeconds     = 5
 )
 
+// PluginConfigFor returns the default plugin config overlaid with the overrides of the given plugin in the appconfig
+func PluginConfigFor(name string) iohandler.PluginConfig {
+	pluginConfig := iohandler.DefaultOutputConfig()
+	config, err := getAppConfig(false)
+	if err != nil {
+		return pluginConfig
+	}
+	override, found := config.Agent.PluginConfigOverrides[name]
+	if !found {
+		return pluginConfig
+	}
+
+	if override.MaxStdoutLength > 0 {
+		pluginConfig.MaxStdoutLength = override.MaxStdoutLength
+	}
+	if override.MaxStderrLength > 0 {
+		pluginConfig.MaxStderrLength = override.MaxStderrLength
+	}
+	if override.OutputTruncatedSuffix != "" {
+		pluginConfig.OutputTruncatedSuffix = override.OutputTruncatedSuffix
+	}
+	return pluginConfig
+}
+
+// PersistOutput writes the stdout and the stderr to files in the given directory that only the agent can access and
+// returns their paths. Nothing is persisted if the directory is empty.
+func PersistOutput(dir string, stdout string, stderr string) (stdoutPath string, stderrPath string, err error) {
+	if dir == "" {
+		return "", "", nil
+	}
+	if err = fileutil.MakeDirsWithExecuteAccess(dir); err != nil {
+		return "", "", err
+	}
+
+	pluginConfig := iohandler.DefaultOutputConfig()
+	stdoutPath = filepath.Join(dir, pluginConfig.StdoutFileName)
+	if err = writeOutputFile(stdoutPath, stdout); err != nil {
+		return "", "", err
+	}
+	stderrPath = filepath.Join(dir, pluginConfig.StderrFileName)
+	if err = writeOutputFile(stderrPath, stderr); err != nil {
+		return "", "", err
+	}
+	return stdoutPath, stderrPath, nil
+}
+
+// writeOutputFile writes the output to a file that only the agent can access, an existing file is replaced
+func writeOutputFile(path string, output string) (err error) {
+	if _, err = fileutil.WriteIntoFileWithPermissions(path, output, appconfig.ReadWriteAccess); err != nil {
+		return err
+	}
+	// the permissions only apply to new files
+	return os.Chmod(path, appconfig.ReadWriteAccess)
+}
+
 // StringPrefix returns the beginning part of a string, truncated to the given limit.
 func StringPrefix(input string, maxLength int, truncatedSuffix string) string {
 	// no need to truncate
@@ -16,9 +16,16 @@ package pluginutil
 
 import (
 	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -190,3 +197,93 @@ func TestCleanupJSONField(t *testing.T) {
 		assert.Equal(t, output, result)
 	}
 }
+
+// stubAppConfig makes PluginConfigFor read the given appconfig
+func stubAppConfig(config appconfig.SsmagentConfig, err error) func() {
+	original := getAppConfig
+	getAppConfig = func(reload bool) (appconfig.SsmagentConfig, error) {
+		return config, err
+	}
+	return func() { getAppConfig = original }
+}
+
+func TestPluginConfigForReturnsDefaults(t *testing.T) {
+	defer stubAppConfig(appconfig.DefaultConfig(), nil)()
+
+	assert.Equal(t, iohandler.DefaultOutputConfig(), PluginConfigFor(appconfig.PluginNameAwsRunShellScript))
+}
+
+func TestPluginConfigForDefaultsWithoutAppConfig(t *testing.T) {
+	defer stubAppConfig(appconfig.SsmagentConfig{}, errors.New("unreadable appconfig"))()
+
+	assert.Equal(t, iohandler.DefaultOutputConfig(), PluginConfigFor(appconfig.PluginNameAwsRunShellScript))
+}
+
+func TestPluginConfigForOverlaysOverrides(t *testing.T) {
+	config := appconfig.DefaultConfig()
+	config.Agent.PluginConfigOverrides = map[string]appconfig.PluginConfigOverride{
+		appconfig.PluginNameAwsRunShellScript: {MaxStdoutLength: 48000},
+		appconfig.PluginNameCloudWatch:        {MaxStderrLength: 100, OutputTruncatedSuffix: "..."},
+	}
+	defer stubAppConfig(config, nil)()
+
+	expected := iohandler.DefaultOutputConfig()
+	expected.MaxStdoutLength = 48000
+	assert.Equal(t, expected, PluginConfigFor(appconfig.PluginNameAwsRunShellScript))
+
+	expected = iohandler.DefaultOutputConfig()
+	expected.MaxStderrLength = 100
+	expected.OutputTruncatedSuffix = "..."
+	assert.Equal(t, expected, PluginConfigFor(appconfig.PluginNameCloudWatch))
+
+	// other plugins keep the defaults
+	assert.Equal(t, iohandler.DefaultOutputConfig(), PluginConfigFor(appconfig.PluginNameAwsRunPowerShellScript))
+}
+
+func TestPersistOutput(t *testing.T) {
+	root, err := ioutil.TempDir("", "pluginutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "orchestration", "awsrunShellScript")
+
+	stdoutPath, stderrPath, err := PersistOutput(dir, "standard output", "standard error")
+
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "stdout"), stdoutPath)
+	assert.Equal(t, filepath.Join(dir, "stderr"), stderrPath)
+	for path, content := range map[string]string{stdoutPath: "standard output", stderrPath: "standard error"} {
+		written, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(written))
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			assert.NoError(t, err)
+			assert.Equal(t, os.FileMode(appconfig.ReadWriteAccess), info.Mode().Perm())
+		}
+	}
+}
+
+func TestPersistOutputReplacesExistingFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pluginutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stdout"), []byte("previous output"), 0644))
+
+	stdoutPath, _, err := PersistOutput(dir, "output", "")
+
+	assert.NoError(t, err)
+	written, _ := ioutil.ReadFile(stdoutPath)
+	assert.Equal(t, "output", string(written))
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(stdoutPath)
+		assert.Equal(t, os.FileMode(appconfig.ReadWriteAccess), info.Mode().Perm())
+	}
+}
+
+func TestPersistOutputSkipsEmptyDir(t *testing.T) {
+	stdoutPath, stderrPath, err := PersistOutput("", "standard output", "standard error")
+
+	assert.NoError(t, err)
+	assert.Empty(t, stdoutPath)
+	assert.Empty(t, stderrPath)
+}
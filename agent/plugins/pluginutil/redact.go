@@ -0,0 +1,83 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pluginutil
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedText replaces the secrets in redacted output
+const RedactedText = "[REDACTED]"
+
+// DefaultRedactionPatterns match common secrets in plugin output. A pattern with a submatch only redacts its first
+// submatch, e.g. the value of a "password=" pair, otherwise the whole match is redacted.
+var DefaultRedactionPatterns = []*regexp.Regexp{
+	// AWS access key ids
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// AWS secret access keys
+	regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`),
+	// bearer tokens
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+	// password, secret and token pairs
+	regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token)["']?\s*[=:]\s*("[^"]*"|'[^']*'|[^\s&;,]+)`),
+}
+
+// redaction is the range of a string to redact
+type redaction struct {
+	start, end int
+}
+
+// Redact replaces the parts of the string matched by the patterns with RedactedText, overlapping matches get
+// redacted once. The string is returned as is if nothing matches.
+func Redact(s string, patterns []*regexp.Regexp) string {
+	var redactions []redaction
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllStringSubmatchIndex(s, -1) {
+			start, end := match[0], match[1]
+			if len(match) > 2 && match[2] >= 0 {
+				start, end = match[2], match[3]
+			}
+			if start < end {
+				redactions = append(redactions, redaction{start, end})
+			}
+		}
+	}
+	if len(redactions) == 0 {
+		return s
+	}
+
+	sort.Slice(redactions, func(i, j int) bool {
+		return redactions[i].start < redactions[j].start
+	})
+
+	var builder strings.Builder
+	builder.Grow(len(s))
+	written := 0
+	for i := 0; i < len(redactions); {
+		start, end := redactions[i].start, redactions[i].end
+		// merge the redactions overlapping this one
+		for i++; i < len(redactions) && redactions[i].start <= end; i++ {
+			if redactions[i].end > end {
+				end = redactions[i].end
+			}
+		}
+		builder.WriteString(s[written:start])
+		builder.WriteString(RedactedText)
+		written = end
+	}
+	builder.WriteString(s[written:])
+	return builder.String()
+}
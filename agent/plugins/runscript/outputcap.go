@@ -0,0 +1,88 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runscript
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// truncationMarker is written at the cut point of output exceeding its cap
+const truncationMarker = "\n[output truncated, %d bytes omitted]\n"
+
+// cappedWriter passes the first max bytes written to it to the underlying writer and counts the omitted ones
+type cappedWriter struct {
+	writer  io.Writer
+	max     int
+	written int
+	omitted int
+}
+
+// newCappedWriter returns a writer capping the output at max bytes, the output isn't capped if max is 0
+func newCappedWriter(writer io.Writer, max int) *cappedWriter {
+	return &cappedWriter{writer: writer, max: max}
+}
+
+// Write passes the part of the chunk within the cap to the underlying writer. It always reports the whole chunk as
+// written so that the commands don't fail writing their output.
+func (w *cappedWriter) Write(chunk []byte) (int, error) {
+	within := len(chunk)
+	if w.max > 0 && w.written+within > w.max {
+		within = w.max - w.written
+	}
+	w.omitted += len(chunk) - within
+	if within == 0 {
+		return len(chunk), nil
+	}
+	w.written += within
+	_, err := w.writer.Write(chunk[:within])
+	return len(chunk), err
+}
+
+// Close writes the truncation marker if output was omitted
+func (w *cappedWriter) Close() error {
+	if w.omitted == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w.writer, truncationMarker, w.omitted)
+	return err
+}
+
+// parseMaxOutputBytes returns the cap of the output in bytes, 0 if the output isn't capped
+func parseMaxOutputBytes(name string, input interface{}) (int, error) {
+	var max int
+	switch value := input.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if value == "" {
+			return 0, nil
+		}
+		var err error
+		if max, err = strconv.Atoi(value); err != nil {
+			return 0, fmt.Errorf("invalid %v %v, %v", name, value, err)
+		}
+	case int:
+		max = value
+	case float64:
+		max = int(value)
+	default:
+		return 0, fmt.Errorf("invalid %v %v", name, input)
+	}
+	if max < 0 {
+		return 0, fmt.Errorf("invalid %v %v, it must not be negative", name, input)
+	}
+	return max, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runscript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/executers"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCappedWriterTruncatesAtCutPoint(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newCappedWriter(&buffer, 10)
+
+	for _, chunk := range []string{"0123", "456789ab", "cdef"} {
+		n, err := writer.Write([]byte(chunk))
+		assert.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, "0123456789\n[output truncated, 6 bytes omitted]\n", buffer.String())
+}
+
+func TestCappedWriterKeepsOutputWithinCap(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newCappedWriter(&buffer, 10)
+
+	writer.Write([]byte("0123456789"))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, "0123456789", buffer.String())
+}
+
+func TestCappedWriterWithoutCap(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newCappedWriter(&buffer, 0)
+
+	writer.Write([]byte(strings.Repeat("x", 100)))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, strings.Repeat("x", 100), buffer.String())
+}
+
+func TestParseMaxOutputBytes(t *testing.T) {
+	for input, expected := range map[interface{}]int{nil: 0, "": 0, "1024": 1024, 2048: 2048, float64(4096): 4096} {
+		max, err := parseMaxOutputBytes("MaxStdoutBytes", input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, max)
+	}
+	for _, input := range []interface{}{"many", -1, "-1", true} {
+		_, err := parseMaxOutputBytes("MaxStdoutBytes", input)
+		assert.Error(t, err, fmt.Sprint(input))
+	}
+}
+
+// newCapturingWriter returns a document writer and a function closing it and returning what got written to it
+func newCapturingWriter() (multiwriter.DocumentIOMultiWriter, func() string) {
+	writer := multiwriter.NewDocumentIOMultiWriter()
+	reader, pipeWriter := io.Pipe()
+	writer.AddWriter(pipeWriter)
+	var buffer bytes.Buffer
+	go func() {
+		defer writer.GetWaitGroup().Done()
+		io.Copy(&buffer, reader)
+	}()
+	return writer, func() string {
+		writer.Close()
+		return buffer.String()
+	}
+}
+
+// TestRunScriptsCapsOutput tests that stdout and stderr are capped independently with a truncation marker.
+func TestRunScriptsCapsOutput(t *testing.T) {
+	input := generateTestCaseOk("0", make(map[string]string)).Input
+	input.MaxStdoutBytes = "5"
+	input.MaxStderrBytes = 8
+	stdout, capturedStdout := newCapturingWriter()
+	stderr, capturedStderr := newCapturingWriter()
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecute", mock.Anything, input.WorkingDirectory, mock.Anything, mock.Anything, mockCancelFlag, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(2).(io.Writer).Write([]byte("standard output"))
+			args.Get(3).(io.Writer).Write([]byte("standard error"))
+		}).Return(0, nil)
+		mockIOHandler.On("GetStdoutWriter").Return(stdout)
+		mockIOHandler.On("GetStderrWriter").Return(stderr)
+		mockIOHandler.On("SetExitCode", 0).Return()
+		mockIOHandler.On("SetStatus", contracts.ResultStatusSuccess).Return()
+
+		p.runCommands(logger, pluginID, input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+
+	assert.Equal(t, "stand\n[output truncated, 10 bytes omitted]\n", capturedStdout())
+	assert.Equal(t, "standard\n[output truncated, 6 bytes omitted]\n", capturedStderr())
+}
+
+// TestRunScriptsFailsForInvalidOutputCap tests that commands don't run with an invalid output cap.
+func TestRunScriptsFailsForInvalidOutputCap(t *testing.T) {
+	input := generateTestCaseOk("0", make(map[string]string)).Input
+	input.MaxStderrBytes = "-1"
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockIOHandler.On("MarkAsFailed", fmt.Errorf("invalid MaxStderrBytes -1, it must not be negative")).Return()
+
+		p.runCommands(logger, pluginID, input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
@@ -16,8 +16,10 @@ package runscript
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
-
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -35,6 +37,13 @@ const (
 	downloadsDir = "downloads" //Directory under the orchestration directory where the downloaded resource resides
 )
 
+// stageScriptForUser makes the script accessible to the user it runs as.
+// Assign method to global variable to allow unittest to override
+var stageScriptForUser = executers.StageScriptForUser
+
+// environmentVariableName matches the valid names of the environment variables of the commands
+var environmentVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Plugin is the type for the runscript plugin.
 type Plugin struct {
 	// ExecuteCommand is an object that can execute commands.
@@ -45,6 +54,35 @@ type Plugin struct {
 	ShellCommand   string
 	ShellArguments []string
 	ByteOrderMark  fileutil.ByteOrderMark
+	// OutputStream receives the output of the commands in chunks while they run, the output of the plugin result
+	// is still assembled and truncated when they finish
+	OutputStream OutputStream
+}
+
+// OutputStream receives a chunk of the standard output or the standard error of the commands, it must not retain
+// the chunk
+type OutputStream func(stream StreamName, chunk []byte)
+
+// StreamName names the output stream of the commands
+type StreamName string
+
+const (
+	// Stdout is the standard output of the commands
+	Stdout StreamName = "stdout"
+	// Stderr is the standard error of the commands
+	Stderr StreamName = "stderr"
+)
+
+// streamWriter passes everything written to it to the output stream
+type streamWriter struct {
+	stream       StreamName
+	outputStream OutputStream
+}
+
+// Write passes the chunk to the output stream, it never fails so that the output still reaches the other writers
+func (w streamWriter) Write(chunk []byte) (int, error) {
+	w.outputStream(w.stream, chunk)
+	return len(chunk), nil
 }
 
 // RunScriptPluginInput represents one set of commands executed by the RunScript plugin.
@@ -55,13 +93,21 @@ type RunScriptPluginInput struct {
 	ID               string
 	WorkingDirectory string
 	TimeoutSeconds   interface{}
+	// RunAsUser is the user the commands run as, they run as the user of the agent if it's empty
+	RunAsUser string
+	// MaxStdoutBytes caps the standard output, the output beyond it is replaced by a truncation marker
+	MaxStdoutBytes interface{}
+	// MaxStderrBytes caps the standard error, the output beyond it is replaced by a truncation marker
+	MaxStderrBytes interface{}
 }
 
 // Execute runs multiple sets of commands and returns their outputs.
 // res.Output will contain a slice of RunScriptPluginOutput.
 func (p *Plugin) Execute(context context.T, config contracts.Configuration, cancelFlag task.CancelFlag, output iohandler.IOHandler) {
 	log := context.Log()
-	log.Infof("%v started with configuration %v", p.Name, config)
+	// the configuration isn't logged at info level since its environment variables may contain secrets
+	log.Infof("%v started for plugin %v", p.Name, config.PluginID)
+	log.Debugf("%v started with configuration %v", p.Name, config)
 	log.Debugf("DefaultWorkingDirectory %v", config.DefaultWorkingDirectory)
 
 	if cancelFlag.ShutDown() {
@@ -91,20 +137,29 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 	var err error
 	var workingDir string
 
-	if filepath.IsAbs(pluginInput.WorkingDirectory) {
-		workingDir = pluginInput.WorkingDirectory
-	} else {
-		orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
-		// The Document path is expected to have the name of the document
-		workingDir = filepath.Join(orchestrationDir, downloadsDir, pluginInput.WorkingDirectory)
-		if !fileutil.Exists(workingDir) {
-			workingDir = defaultWorkingDirectory
-		}
+	if err = validateEnvironment(pluginInput.Environment); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	var maxStdoutBytes, maxStderrBytes int
+	if maxStdoutBytes, err = parseMaxOutputBytes("MaxStdoutBytes", pluginInput.MaxStdoutBytes); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+	if maxStderrBytes, err = parseMaxOutputBytes("MaxStderrBytes", pluginInput.MaxStderrBytes); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	if workingDir, err = resolveWorkingDirectory(pluginID, pluginInput.WorkingDirectory, orchestrationDirectory, defaultWorkingDirectory); err != nil {
+		output.MarkAsFailed(err)
+		return
 	}
 
 	// TODO:MF: This subdirectory is only needed because we could be running multiple sets of properties for the same plugin - otherwise the orchestration directory would already be unique
 	orchestrationDir := fileutil.BuildPath(orchestrationDirectory, pluginInput.ID)
-	log.Debugf("Running commands %v with environment variables %v in workingDirectory %v; orchestrationDir %v ", pluginInput.RunCommand, pluginInput.Environment, workingDir, orchestrationDir)
+	log.Debugf("Running commands %v with environment variables %v in workingDirectory %v; orchestrationDir %v ", pluginInput.RunCommand, environmentVariableNames(pluginInput.Environment), workingDir, orchestrationDir)
 
 	// create orchestration dir if needed
 	if err = fileutil.MakeDirsWithExecuteAccess(orchestrationDir); err != nil {
@@ -114,7 +169,7 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 
 	// Create script file path
 	scriptPath := filepath.Join(orchestrationDir, p.ScriptName)
-	log.Debugf("Writing commands %v to file %v", pluginInput, scriptPath)
+	log.Debugf("Writing commands %v to file %v", pluginInput.RunCommand, scriptPath)
 
 	// Create script file
 	if err = pluginutil.CreateScriptFile(log, scriptPath, pluginInput.RunCommand, p.ByteOrderMark); err != nil {
@@ -122,6 +177,17 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 		return
 	}
 
+	// The orchestration directory is only accessible to the agent, the script of another user is run from a copy
+	if pluginInput.RunAsUser != "" {
+		stagedPath, cleanup, err := stageScriptForUser(scriptPath, pluginInput.RunAsUser)
+		if err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to prepare the script for user %v. %v", pluginInput.RunAsUser, err))
+			return
+		}
+		defer cleanup()
+		scriptPath = stagedPath
+	}
+
 	// Set execution time
 	executionTimeout := pluginutil.ValidateExecutionTimeout(log, pluginInput.TimeoutSeconds)
 
@@ -129,8 +195,33 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 	commandName := p.ShellCommand
 	commandArguments := append(p.ShellArguments, scriptPath)
 
+	// Stream the output while the commands run if requested
+	var stdoutWriter, stderrWriter io.Writer = output.GetStdoutWriter(), output.GetStderrWriter()
+	if p.OutputStream != nil {
+		stdoutWriter = io.MultiWriter(streamWriter{Stdout, p.OutputStream}, stdoutWriter)
+		stderrWriter = io.MultiWriter(streamWriter{Stderr, p.OutputStream}, stderrWriter)
+	}
+
+	// Cap the output if requested
+	if maxStdoutBytes > 0 {
+		cappedStdout := newCappedWriter(stdoutWriter, maxStdoutBytes)
+		defer cappedStdout.Close()
+		stdoutWriter = cappedStdout
+	}
+	if maxStderrBytes > 0 {
+		cappedStderr := newCappedWriter(stderrWriter, maxStderrBytes)
+		defer cappedStderr.Close()
+		stderrWriter = cappedStderr
+	}
+
 	// Execute Command
-	exitCode, err := p.CommandExecuter.NewExecute(log, workingDir, output.GetStdoutWriter(), output.GetStderrWriter(), cancelFlag, executionTimeout, commandName, commandArguments, pluginInput.Environment)
+	var exitCode int
+	if pluginInput.RunAsUser != "" {
+		log.Debugf("Running commands as user %v", pluginInput.RunAsUser)
+		exitCode, err = p.CommandExecuter.NewExecuteAsUser(log, pluginInput.RunAsUser, workingDir, stdoutWriter, stderrWriter, cancelFlag, executionTimeout, commandName, commandArguments, pluginInput.Environment)
+	} else {
+		exitCode, err = p.CommandExecuter.NewExecute(log, workingDir, stdoutWriter, stderrWriter, cancelFlag, executionTimeout, commandName, commandArguments, pluginInput.Environment)
+	}
 
 	// Set output status
 	output.SetExitCode(exitCode)
@@ -145,3 +236,50 @@ func (p *Plugin) runCommands(log log.T, pluginID string, pluginInput RunScriptPl
 		}
 	}
 }
+
+// resolveWorkingDirectory returns the directory the commands run in. A relative working directory is resolved
+// against the downloads of the document, a working directory that is set has to be an existing directory.
+func resolveWorkingDirectory(pluginID string, workingDirectory string, orchestrationDirectory string, defaultWorkingDirectory string) (string, error) {
+	workingDir := workingDirectory
+	if !filepath.IsAbs(workingDirectory) {
+		orchestrationDir := strings.TrimSuffix(orchestrationDirectory, pluginID)
+		// The Document path is expected to have the name of the document
+		workingDir = filepath.Join(orchestrationDir, downloadsDir, workingDirectory)
+	}
+
+	if workingDirectory == "" {
+		if !fileutil.Exists(workingDir) {
+			return defaultWorkingDirectory, nil
+		}
+		return workingDir, nil
+	}
+
+	if !fileutil.Exists(workingDir) {
+		return "", fmt.Errorf("working directory %v doesn't exist", workingDir)
+	}
+	if !fileutil.IsDirectory(workingDir) {
+		return "", fmt.Errorf("working directory %v is not a directory", workingDir)
+	}
+	return workingDir, nil
+}
+
+// validateEnvironment returns an error if the name of an environment variable isn't valid
+func validateEnvironment(environment map[string]string) error {
+	for _, name := range environmentVariableNames(environment) {
+		if !environmentVariableName.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	return nil
+}
+
+// environmentVariableNames returns the sorted names of the environment variables, their values aren't logged since
+// they may contain secrets
+func environmentVariableNames(environment map[string]string) []string {
+	names := make([]string, 0, len(environment))
+	for name := range environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -15,6 +15,9 @@ package runscript
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/aws/amazon-ssm-agent/agent/context"
@@ -71,7 +74,7 @@ func generateTestCaseOk(id string, envVars map[string]string) TestCase {
 	input := RunScriptPluginInput{
 		RunCommand:       []string{"echo " + id},
 		ID:               id + ".aws:runScript",
-		WorkingDirectory: "/Dir" + id,
+		WorkingDirectory: os.TempDir(),
 		TimeoutSeconds:   "1",
 	}
 	if len(envVars) != 0 {
@@ -388,3 +391,93 @@ func setCancelFlagExpectations(mockCancelFlag *task.MockCancelFlag, times int) {
 	mockCancelFlag.On("Canceled").Return(false).Times(times)
 	mockCancelFlag.On("ShutDown").Return(false).Times(times)
 }
+
+func TestResolveWorkingDirectory(t *testing.T) {
+	root, err := ioutil.TempDir("", "runscript")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	orchestrationDir := filepath.Join(root, pluginID)
+	scriptsDir := filepath.Join(root, downloadsDir, "scripts")
+	assert.NoError(t, os.MkdirAll(scriptsDir, 0700))
+	file := filepath.Join(root, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte{}, 0600))
+
+	// absolute directory
+	workingDir, err := resolveWorkingDirectory(pluginID, scriptsDir, orchestrationDir, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, scriptsDir, workingDir)
+
+	// directory relative to the downloads of the document
+	workingDir, err = resolveWorkingDirectory(pluginID, "scripts", orchestrationDir, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, scriptsDir, workingDir)
+
+	// no working directory runs in the downloads of the document or in the default working directory
+	workingDir, err = resolveWorkingDirectory(pluginID, "", orchestrationDir, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, downloadsDir), workingDir)
+	workingDir, err = resolveWorkingDirectory(pluginID, "", filepath.Join(root, "missing", pluginID), "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "default", workingDir)
+
+	_, err = resolveWorkingDirectory(pluginID, filepath.Join(root, "missing"), orchestrationDir, "default")
+	assert.EqualError(t, err, fmt.Sprintf("working directory %v doesn't exist", filepath.Join(root, "missing")))
+	_, err = resolveWorkingDirectory(pluginID, "missing", orchestrationDir, "default")
+	assert.EqualError(t, err, fmt.Sprintf("working directory %v doesn't exist", filepath.Join(root, downloadsDir, "missing")))
+	_, err = resolveWorkingDirectory(pluginID, file, orchestrationDir, "default")
+	assert.EqualError(t, err, fmt.Sprintf("working directory %v is not a directory", file))
+}
+
+// TestRunScriptsFailsForMissingWorkingDirectory tests that commands don't run in a working directory that doesn't exist.
+func TestRunScriptsFailsForMissingWorkingDirectory(t *testing.T) {
+	input := generateTestCaseOk("0", make(map[string]string)).Input
+	input.WorkingDirectory = filepath.Join(os.TempDir(), "runscript-missing-working-directory")
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockIOHandler.On("MarkAsFailed", fmt.Errorf("working directory %v doesn't exist", input.WorkingDirectory)).Return()
+
+		p.runCommands(logger, pluginID, input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+// TestRunScriptsFailsForInvalidEnvironmentVariableName tests that commands don't run with an invalid environment.
+func TestRunScriptsFailsForInvalidEnvironmentVariableName(t *testing.T) {
+	input := generateTestCaseOk("0", map[string]string{"BUILD_ID": "42", "NOT=VALID": "secret"}).Input
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockIOHandler.On("MarkAsFailed", fmt.Errorf("invalid environment variable name %q", "NOT=VALID")).Return()
+
+		p.runCommands(logger, pluginID, input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	assert.NoError(t, validateEnvironment(nil))
+	assert.NoError(t, validateEnvironment(map[string]string{"BUILD_ID": "42", "_private": "", "Path2": "/bin"}))
+	for _, name := range []string{"", "1ST", "WITH SPACE", "WITH-DASH", "WITH=EQUALS"} {
+		assert.Error(t, validateEnvironment(map[string]string{name: "value"}), name)
+	}
+	assert.Equal(t, []string{"A", "B"}, environmentVariableNames(map[string]string{"B": "secret", "A": "secret"}))
+}
+
+// TestRunScriptsAsUser tests that commands with a user to run as get executed as that user.
+func TestRunScriptsAsUser(t *testing.T) {
+	testCase := generateTestCaseOk("0", make(map[string]string))
+	testCase.Input.RunAsUser = "service"
+	originalStage := stageScriptForUser
+	stageScriptForUser = func(scriptPath string, runAsUser string) (string, func(), error) {
+		return scriptPath, func() {}, nil
+	}
+	defer func() { stageScriptForUser = originalStage }()
+	runScriptTester := func(p *Plugin, mockCancelFlag *task.MockCancelFlag, mockExecuter *executers.MockCommandExecuter, mockIOHandler *iohandlermocks.MockIOHandler) {
+		mockExecuter.On("NewExecuteAsUser", mock.Anything, "service", testCase.Input.WorkingDirectory, testCase.Output.StdoutWriter, testCase.Output.StderrWriter, mockCancelFlag, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+			testCase.Output.ExitCode, testCase.ExecuterError)
+		setIOHandlerExpectations(mockIOHandler, testCase)
+
+		p.runCommands(logger, pluginID, testCase.Input, orchestrationDirectory, defaultWorkingDirectory, mockCancelFlag, mockIOHandler)
+	}
+
+	testExecution(t, runScriptTester)
+}
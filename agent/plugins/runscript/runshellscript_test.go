@@ -0,0 +1,126 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package runscript
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	iohandlermocks "github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/mock"
+	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler/multiwriter"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDrainedWriter returns a document writer whose output gets discarded
+func newDrainedWriter() multiwriter.DocumentIOMultiWriter {
+	writer := multiwriter.NewDocumentIOMultiWriter()
+	reader, pipeWriter := io.Pipe()
+	writer.AddWriter(pipeWriter)
+	go io.Copy(ioutil.Discard, reader)
+	return writer
+}
+
+func TestRunShellScriptStreamsOutputWhileRunning(t *testing.T) {
+	orchestrationDir, err := ioutil.TempDir("", "runscript")
+	assert.NoError(t, err)
+	defer os.RemoveAll(orchestrationDir)
+	p, _ := NewRunShellPlugin(logger)
+	chunks := make(chan string, 10)
+	p.OutputStream = func(stream StreamName, chunk []byte) {
+		chunks <- string(stream) + ":" + string(chunk)
+	}
+	output := new(iohandlermocks.MockIOHandler)
+	output.On("GetStdoutWriter").Return(newDrainedWriter())
+	output.On("GetStderrWriter").Return(newDrainedWriter())
+	output.On("SetExitCode", 0).Return()
+	output.On("SetStatus", contracts.ResultStatusSuccess).Return()
+	cancelFlag := task.NewChanneledCancelFlag()
+	input := RunScriptPluginInput{
+		RunCommand:     []string{"echo first", "sleep 2", "echo second"},
+		ID:             "0.aws:runShellScript",
+		TimeoutSeconds: "10",
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.runCommands(logger, pluginID, input, orchestrationDir, "", cancelFlag, output)
+	}()
+
+	select {
+	case chunk := <-chunks:
+		assert.Equal(t, "stdout:first\n", chunk)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "the output wasn't streamed while the script was running")
+	}
+	select {
+	case <-done:
+		assert.Fail(t, "the script exited before its output was streamed")
+	default:
+	}
+
+	<-done
+	var streamed []string
+	for len(chunks) > 0 {
+		streamed = append(streamed, <-chunks)
+	}
+	assert.Equal(t, "stdout:second\n", strings.Join(streamed, ""))
+	output.AssertExpectations(t)
+}
+
+func TestRunShellScriptAsAnotherUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("running commands as another user requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("user nobody doesn't exist", err)
+	}
+	// the orchestration directory is only accessible to the agent, like the one of documents
+	orchestrationDir, err := ioutil.TempDir("", "runscript")
+	assert.NoError(t, err)
+	defer os.RemoveAll(orchestrationDir)
+	p, _ := NewRunShellPlugin(logger)
+	var stdout []string
+	p.OutputStream = func(stream StreamName, chunk []byte) {
+		if stream == Stdout {
+			stdout = append(stdout, string(chunk))
+		}
+	}
+	output := new(iohandlermocks.MockIOHandler)
+	output.On("GetStdoutWriter").Return(newDrainedWriter())
+	output.On("GetStderrWriter").Return(newDrainedWriter())
+	output.On("SetExitCode", 0).Return()
+	output.On("SetStatus", contracts.ResultStatusSuccess).Return()
+	input := RunScriptPluginInput{
+		RunCommand:     []string{"id -u"},
+		ID:             "0.aws:runShellScript",
+		TimeoutSeconds: "10",
+		RunAsUser:      nobody.Username,
+	}
+
+	p.runCommands(logger, pluginID, input, orchestrationDir, "", task.NewChanneledCancelFlag(), output)
+
+	output.AssertExpectations(t)
+	assert.Equal(t, nobody.Uid+"\n", strings.Join(stdout, ""))
+}
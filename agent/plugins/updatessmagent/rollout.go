@@ -0,0 +1,52 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updatessmagent implements the UpdateSsmAgent plugin.
+package updatessmagent
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+const (
+	// fullRollout is the rollout percentage of updates that don't specify one
+	fullRollout = 100
+
+	// rolloutBuckets is the number of buckets instances are spread over by their instance id
+	rolloutBuckets = 100
+)
+
+// parseRolloutPercentage parses the rollout percentage of the plugin input, an empty value rolls out to every instance
+func parseRolloutPercentage(value string) (percentage int, err error) {
+	if len(value) == 0 {
+		return fullRollout, nil
+	}
+	if percentage, err = strconv.Atoi(value); err != nil || percentage < 0 || percentage > fullRollout {
+		return 0, fmt.Errorf("invalid rollout percentage %v, it must be a number between 0 and %v", value, fullRollout)
+	}
+	return percentage, nil
+}
+
+// rolloutBucket returns the bucket of the given instance, an instance always falls into the same bucket
+func rolloutBucket(instanceID string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(instanceID))
+	return int(hash.Sum32() % rolloutBuckets)
+}
+
+// isInRollout returns true if the instance belongs to the given percentage of the fleet that updates
+func isInRollout(instanceID string, percentage int) bool {
+	return rolloutBucket(instanceID) < percentage
+}
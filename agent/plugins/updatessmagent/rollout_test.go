@@ -0,0 +1,65 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updatessmagent implements the UpdateSsmAgent plugin.
+package updatessmagent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRolloutPercentage(t *testing.T) {
+	percentage, err := parseRolloutPercentage("")
+	assert.NoError(t, err)
+	assert.Equal(t, fullRollout, percentage)
+
+	percentage, err = parseRolloutPercentage("25")
+	assert.NoError(t, err)
+	assert.Equal(t, 25, percentage)
+
+	for _, invalid := range []string{"-1", "101", "half"} {
+		_, err = parseRolloutPercentage(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRolloutSelectionIsStable(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		instanceID := fmt.Sprintf("i-%017d", i)
+		assert.Equal(t, rolloutBucket(instanceID), rolloutBucket(instanceID))
+		assert.Equal(t, isInRollout(instanceID, 30), isInRollout(instanceID, 30))
+		// instances in a rollout stay in it when the percentage grows
+		if isInRollout(instanceID, 30) {
+			assert.True(t, isInRollout(instanceID, 60))
+		}
+		assert.False(t, isInRollout(instanceID, 0))
+		assert.True(t, isInRollout(instanceID, fullRollout))
+	}
+}
+
+func TestRolloutSelectionDistributesAsConfigured(t *testing.T) {
+	instances := 10000
+	for _, percentage := range []int{10, 25, 50, 90} {
+		inRollout := 0
+		for i := 0; i < instances; i++ {
+			if isInRollout(fmt.Sprintf("i-%017x", i), percentage) {
+				inRollout++
+			}
+		}
+		share := float64(inRollout) * 100 / float64(instances)
+		assert.InDelta(t, percentage, share, 3, "rollout of %v%% selected %v%% of the instances", percentage, share)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -42,6 +43,14 @@ import (
 type Plugin struct {
 	// Manifest location
 	ManifestLocation string
+	// MaxAttempts is how often the manifest download and the updater execution are attempted
+	MaxAttempts int
+	// RetryDelayMillis is the delay between attempts, a random jitter gets added to it
+	RetryDelayMillis int
+	// ProxyURL is the proxy the downloads are sent through
+	ProxyURL string
+	// CABundlePath is a PEM encoded bundle of CA certificates the downloads trust in addition to the system ones
+	CABundlePath string
 }
 
 // UpdatePluginInput represents one set of commands executed by the UpdateAgent plugin.
@@ -51,15 +60,40 @@ type UpdatePluginInput struct {
 	AllowDowngrade string `json:"allowDowngrade"`
 	TargetVersion  string `json:"targetVersion"`
 	Source         string `json:"source"`
-	UpdaterName    string `json:"-"`
+	// RolloutPercentage limits the update to a stable share of the fleet, the other instances defer it
+	RolloutPercentage string `json:"rolloutPercentage"`
+	// CheckOnly reports whether an update is available without downloading or installing it
+	CheckOnly   string `json:"checkOnly"`
+	UpdaterName string `json:"-"`
+}
+
+// UpdateCheckOutput is the output of the check only mode
+type UpdateCheckOutput struct {
+	CurrentVersion  string `json:"currentVersion"`
+	LatestVersion   string `json:"latestVersion"`
+	UpdateAvailable bool   `json:"updateAvailable"`
 }
 
 // UpdatePluginConfig is used for initializing update agent plugin with default values
 type UpdatePluginConfig struct {
+	// ManifestLocation is the url of the manifest, Agent.UpdateManifestLocation of the appconfig overrides it
 	ManifestLocation string
+	// MaxAttempts is how often the manifest download and the updater execution are attempted,
+	// Agent.UpdateMaxAttempts of the appconfig overrides it
+	MaxAttempts int
+	// RetryDelayMillis is the delay between attempts, Agent.UpdateRetryDelayMillis of the appconfig overrides it
+	RetryDelayMillis int
+	// ProxyURL is the proxy the downloads are sent through, Agent.UpdateProxyURL of the appconfig sets it
+	ProxyURL string
+	// CABundlePath is a PEM encoded bundle of CA certificates the downloads trust in addition to the system ones,
+	// Agent.UpdateCABundlePath of the appconfig sets it
+	CABundlePath string
 }
 
-type updateManager struct{}
+type updateManager struct {
+	// transport is used by the downloads, nil uses the default transport
+	transport http.RoundTripper
+}
 
 type pluginHelper interface {
 	generateUpdateCmd(log log.T,
@@ -96,17 +130,41 @@ type pluginHelper interface {
 // Assign method to global variables to allow unittest to override
 var getAppConfig = appconfig.Config
 var fileDownload = artifact.Download
+var newTransport = artifact.NewTransport
 var fileUncompress = fileutil.Uncompress
 var updateAgent = runUpdateAgent
 var getLockObj = lockfile.New
+var getInstanceID = platform.InstanceID
+var getDiskSpaceInfo = fileutil.GetDiskSpaceInfo
+var getRegion = platform.Region
 
 // NewPlugin returns a new instance of the plugin.
 func NewPlugin(updatePluginConfig UpdatePluginConfig) (*Plugin, error) {
 	var plugin Plugin
 	plugin.ManifestLocation = updatePluginConfig.ManifestLocation
+	plugin.MaxAttempts = updatePluginConfig.MaxAttempts
+	plugin.RetryDelayMillis = updatePluginConfig.RetryDelayMillis
+	plugin.ProxyURL = updatePluginConfig.ProxyURL
+	plugin.CABundlePath = updatePluginConfig.CABundlePath
 	return &plugin, nil
 }
 
+// maxAttempts returns how often to attempt the manifest download and the updater execution
+func (p *Plugin) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return appconfig.DefaultUpdateMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// retryDelayMillis returns the delay between attempts without the jitter
+func (p *Plugin) retryDelayMillis() int {
+	if p.RetryDelayMillis <= 0 {
+		return appconfig.DefaultUpdateRetryDelayMillis
+	}
+	return p.RetryDelayMillis
+}
+
 // updateAgent downloads the installation packages and update the agent
 func runUpdateAgent(
 	p *Plugin,
@@ -129,11 +187,50 @@ func runUpdateAgent(
 		return
 	}
 
+	checkOnly := false
+	if len(pluginInput.CheckOnly) != 0 {
+		if checkOnly, err = strconv.ParseBool(pluginInput.CheckOnly); err != nil {
+			output.MarkAsFailed(fmt.Errorf("invalid check only value %v, %v", pluginInput.CheckOnly, err))
+			return
+		}
+	}
+
+	rolloutPercentage := 0
+	if rolloutPercentage, err = parseRolloutPercentage(pluginInput.RolloutPercentage); err != nil {
+		output.MarkAsFailed(err)
+		return
+	}
+
+	// A check doesn't update anything, hence it's not subject to the rollout
+	if !checkOnly && rolloutPercentage < fullRollout {
+		instanceID := ""
+		if instanceID, err = getInstanceID(); err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to get the instance id for the rollout: %v", err))
+			return
+		}
+		if !isInRollout(instanceID, rolloutPercentage) {
+			output.AppendInfof("Update of %v deferred, instance %v is not within the %v%% rollout\n",
+				pluginInput.AgentName,
+				instanceID,
+				rolloutPercentage)
+			output.MarkAsSucceeded()
+			return
+		}
+	}
+
 	if context, err = util.CreateInstanceContext(log); err != nil {
 		output.MarkAsFailed(err)
 		return
 	}
 
+	// Refuse the update before downloading anything if it would run out of disk space midway
+	if !checkOnly {
+		if err = checkDiskSpaceHeadroom(log); err != nil {
+			output.MarkAsFailed(err)
+			return
+		}
+	}
+
 	//Use default manifest location is the override is not present
 	if len(pluginInput.Source) == 0 {
 		pluginInput.Source = p.ManifestLocation
@@ -147,18 +244,22 @@ func runUpdateAgent(
 	if len(targetVersion) == 0 {
 		targetVersion = "latest"
 	}
-	output.AppendInfof("Updating %v from %v to %v\n",
-		pluginInput.AgentName,
-		version.Version,
-		targetVersion)
+	if checkOnly {
+		output.AppendInfof("Checking for updates of %v %v\n", pluginInput.AgentName, version.Version)
+	} else {
+		output.AppendInfof("Updating %v from %v to %v\n",
+			pluginInput.AgentName,
+			version.Version,
+			targetVersion)
+	}
 
 	//Download manifest file
 	var manifest *Manifest
 	var downloadErr error
 
-	noOfRetries := 2
-	updateRetryDelayBase := 1000 // 1000 millisecond
-	updateRetryDelay := 500      // 500 millisecond
+	noOfRetries := p.maxAttempts()
+	updateRetryDelayBase := p.retryDelayMillis()
+	updateRetryDelay := 500 // 500 millisecond
 
 	for retryCounter := 1; retryCounter <= noOfRetries; retryCounter++ {
 		manifest, downloadErr = manager.downloadManifest(log, util, &pluginInput, context, output)
@@ -175,6 +276,11 @@ func runUpdateAgent(
 		return
 	}
 
+	if checkOnly {
+		reportAvailableUpdate(log, &pluginInput, context, manifest, output)
+		return
+	}
+
 	//Validate update details
 	noNeedToUpdate := false
 	if noNeedToUpdate, err = manager.validateUpdate(log, &pluginInput, context, manifest, output); noNeedToUpdate {
@@ -259,6 +365,27 @@ func runUpdateAgent(
 	return
 }
 
+// checkDiskSpaceHeadroom returns an error if the available disk space is below the configured headroom for updates.
+// If the disk space can't be loaded the update proceeds, the updater rolls back failed installations.
+func checkDiskSpaceHeadroom(log log.T) error {
+	headroomMb := appconfig.DefaultUpdateDiskSpaceHeadroomMb
+	if config, err := getAppConfig(false); err == nil {
+		headroomMb = config.Agent.UpdateDiskSpaceHeadroomMb
+	}
+
+	diskSpaceInfo, err := getDiskSpaceInfo()
+	if err != nil {
+		log.Warnf("Failed to load disk space info, proceeding with the update - %v", err)
+		return nil
+	}
+
+	availableMb := diskSpaceInfo.AvailBytes / (1024 * 1024)
+	if availableMb < int64(headroomMb) {
+		return fmt.Errorf("insufficient available disk space for the update, %d Mb available but %d Mb required", availableMb, headroomMb)
+	}
+	return nil
+}
+
 //generateUpdateCmd generates cmd for the updater
 func (m *updateManager) generateUpdateCmd(log log.T,
 	manifest *Manifest,
@@ -271,42 +398,41 @@ func (m *updateManager) generateUpdateCmd(log log.T,
 	keyPrefix string,
 	bucketName string) (cmd string, err error) {
 	updaterPath := updateutil.UpdaterFilePath(appconfig.UpdaterArtifactsRoot, pluginInput.UpdaterName, updaterVersion)
-	cmd = updaterPath + " -update"
-	source := ""
-	hash := ""
+	args := updateutil.NewUpdateArgs()
+	args.Update = true
 
 	//Get download url and hash value from for the current version of ssm agent
-	if source, hash, err = manifest.DownloadURLAndHash(
+	args.SourceVersion = version.Version
+	if args.SourceLocation, args.SourceHash, err = manifest.DownloadURLAndHash(
 		context, pluginInput.AgentName, version.Version); err != nil {
 		return
 	}
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.SourceVersionCmd, version.Version)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.SourceLocationCmd, source)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.SourceHashCmd, hash)
 
 	//Get download url and hash value from for the target version of ssm agent
-	if source, hash, err = manifest.DownloadURLAndHash(
+	args.TargetVersion = pluginInput.TargetVersion
+	if args.TargetLocation, args.TargetHash, err = manifest.DownloadURLAndHash(
 		context, pluginInput.AgentName, pluginInput.TargetVersion); err != nil {
 		return
 	}
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.TargetVersionCmd, pluginInput.TargetVersion)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.TargetLocationCmd, source)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.TargetHashCmd, hash)
 
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.PackageNameCmd, pluginInput.AgentName)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.MessageIDCmd, messageID)
+	args.PackageName = pluginInput.AgentName
+	args.MessageID = messageID
 
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.StdoutFileName, stdout)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.StderrFileName, stderr)
+	args.StdoutFileName = stdout
+	args.StderrFileName = stderr
 
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.OutputKeyPrefixCmd, keyPrefix)
-	cmd = updateutil.BuildUpdateCommand(cmd, updateutil.OutputBucketNameCmd, bucketName)
+	args.OutputKeyPrefix = keyPrefix
+	args.OutputBucketName = bucketName
 
 	versionSplit := strings.Split(updaterVersion, ".")
 	majorVersion, _ := strconv.Atoi(versionSplit[0])
 	if majorVersion > 2 {
-		cmd = updateutil.BuildUpdateCommand(cmd, updateutil.ManifestFileUrlCmd, pluginInput.Source)
+		args.ManifestURL = pluginInput.Source
 	}
+	if err = args.Validate(); err != nil {
+		return
+	}
+	cmd = strings.Join(append([]string{updaterPath}, args.ToArgs()...), " ")
 	return
 }
 
@@ -326,6 +452,7 @@ func (m *updateManager) downloadManifest(log log.T,
 	downloadInput := artifact.DownloadInput{
 		SourceURL:            pluginInput.Source,
 		DestinationDirectory: updateDownload,
+		Transport:            m.transport,
 	}
 
 	downloadOutput, downloadErr := fileDownload(log, downloadInput)
@@ -365,6 +492,7 @@ func (m *updateManager) downloadUpdater(log log.T,
 			updateutil.HashType: hash,
 		},
 		DestinationDirectory: updateDownloadFolder,
+		Transport:            m.transport,
 	}
 	downloadOutput, downloadErr := fileDownload(log, downloadInput)
 	if downloadErr != nil ||
@@ -390,6 +518,39 @@ func (m *updateManager) downloadUpdater(log log.T,
 	return version, nil
 }
 
+// reportAvailableUpdate sets the output to the latest version of the manifest supported on this platform and whether
+// it's newer than the current version
+func reportAvailableUpdate(log log.T,
+	pluginInput *UpdatePluginInput,
+	context *updateutil.InstanceContext,
+	manifest *Manifest,
+	out iohandler.IOHandler) {
+	latestVersion, err := manifest.LatestVersion(log, context, pluginInput.AgentName)
+	if err != nil {
+		out.MarkAsFailed(err)
+		return
+	}
+
+	res, err := updateutil.CompareVersion(latestVersion, version.Version)
+	if err != nil {
+		out.MarkAsFailed(err)
+		return
+	}
+
+	checkOutput := UpdateCheckOutput{
+		CurrentVersion:  version.Version,
+		LatestVersion:   latestVersion,
+		UpdateAvailable: res > 0,
+	}
+	if checkOutput.UpdateAvailable {
+		out.AppendInfof("%v %v is available\n", pluginInput.AgentName, latestVersion)
+	} else {
+		out.AppendInfof("%v %v is up to date\n", pluginInput.AgentName, version.Version)
+	}
+	out.SetOutput(checkOutput)
+	out.MarkAsSucceeded()
+}
+
 //validateUpdate validates manifest against update request
 func (m *updateManager) validateUpdate(log log.T,
 	pluginInput *UpdatePluginInput,
@@ -457,10 +618,16 @@ func (p *Plugin) Execute(context context.T, config contracts.Configuration, canc
 	} else if cancelFlag.Canceled() {
 		output.MarkAsCancelled()
 	} else {
+		transport, err := newTransport(p.ProxyURL, p.CABundlePath)
+		if err != nil {
+			output.MarkAsFailed(fmt.Errorf("failed to configure the download transport, %v", err))
+			return
+		}
+		manager.transport = transport
 
 		// First check if lock is locked by anyone
 		lock, _ := getLockObj(appconfig.UpdaterPidLockfile)
-		err := lock.TryLockExpireWithRetry(updateutil.UpdateLockFileMinutes)
+		err = lock.TryLockExpireWithRetry(updateutil.UpdateLockFileMinutes)
 
 		if err != nil {
 			if err == lockfile.ErrBusy {
@@ -522,7 +689,7 @@ func Name() string {
 // GetUpdatePluginConfig returns the default values for the update plugin
 func GetUpdatePluginConfig(context context.T) UpdatePluginConfig {
 	log := context.Log()
-	region, err := platform.Region()
+	region, err := getRegion()
 	if err != nil {
 		log.Errorf("Error retrieving agent region in update plugin config. error: %v\n", err)
 	}
@@ -537,9 +704,25 @@ func GetUpdatePluginConfig(context context.T) UpdatePluginConfig {
 		}
 	}
 
-	return UpdatePluginConfig{
+	updatePluginConfig := UpdatePluginConfig{
 		ManifestLocation: manifestUrl,
+		MaxAttempts:      appconfig.DefaultUpdateMaxAttempts,
+		RetryDelayMillis: appconfig.DefaultUpdateRetryDelayMillis,
+	}
+
+	// layer the overrides of the appconfig on top of the defaults
+	if config, err := getAppConfig(false); err != nil {
+		log.Warnf("Failed to load the appconfig overrides of the update plugin config, using the defaults: %v", err)
+	} else {
+		if config.Agent.UpdateManifestLocation != "" {
+			updatePluginConfig.ManifestLocation = config.Agent.UpdateManifestLocation
+		}
+		updatePluginConfig.MaxAttempts = config.Agent.UpdateMaxAttempts
+		updatePluginConfig.RetryDelayMillis = config.Agent.UpdateRetryDelayMillis
+		updatePluginConfig.ProxyURL = config.Agent.UpdateProxyURL
+		updatePluginConfig.CABundlePath = config.Agent.UpdateCABundlePath
 	}
+	return updatePluginConfig
 }
 
 func retrieveDynamicS3ManifestUrl(region string, service string) string {
@@ -19,8 +19,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/context"
 	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil"
 	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
 	"github.com/aws/amazon-ssm-agent/agent/framework/processor/executer/iohandler"
 	"github.com/aws/amazon-ssm-agent/agent/log"
@@ -339,6 +341,95 @@ func TestUpdateAgent(t *testing.T) {
 	}
 }
 
+func TestUpdateAgent_DeferredOutsideRollout(t *testing.T) {
+	original := getInstanceID
+	getInstanceID = func() (string, error) { return "i-1234567890abcdef0", nil }
+	defer func() { getInstanceID = original }()
+	pluginInput := createStubPluginInput()
+	pluginInput.RolloutPercentage = "0"
+	manager := &fakeUpdateManager{}
+	util := &fakeUtility{}
+	out := iohandler.DefaultIOHandler{}
+
+	updateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, util, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusSuccess, out.GetStatus())
+	assert.Contains(t, out.GetStdout(), "deferred")
+	assert.Equal(t, 0, manager.retryCounter)
+}
+
+func TestUpdateAgent_ProceedsWithinRollout(t *testing.T) {
+	original := getInstanceID
+	getInstanceID = func() (string, error) { return "i-1234567890abcdef0", nil }
+	defer func() { getInstanceID = original }()
+	pluginInput := createStubPluginInput()
+	pluginInput.TargetVersion = ""
+	pluginInput.RolloutPercentage = "100"
+	manager := &fakeUpdateManager{}
+	util := &fakeUtility{}
+	out := iohandler.DefaultIOHandler{}
+
+	updateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, util, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.NotContains(t, out.GetStdout(), "deferred")
+	assert.Equal(t, 1, manager.retryCounter)
+}
+
+func TestUpdateAgent_InvalidRolloutPercentage(t *testing.T) {
+	pluginInput := createStubPluginInput()
+	pluginInput.RolloutPercentage = "200"
+	out := iohandler.DefaultIOHandler{}
+
+	updateAgent(&Plugin{}, contracts.Configuration{}, logger, &fakeUpdateManager{}, &fakeUtility{}, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusFailed, out.GetStatus())
+	assert.Contains(t, out.GetStderr(), "invalid rollout percentage")
+}
+
+func TestUpdateAgent_InsufficientDiskSpace(t *testing.T) {
+	original := getDiskSpaceInfo
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: 10 * 1024 * 1024}, nil
+	}
+	defer func() { getDiskSpaceInfo = original }()
+	pluginInput := createStubPluginInput()
+	manager := &fakeUpdateManager{}
+	out := iohandler.DefaultIOHandler{}
+
+	updateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, &fakeUtility{}, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusFailed, out.GetStatus())
+	assert.Contains(t, out.GetStderr(), "insufficient available disk space for the update, 10 Mb available")
+	// nothing got downloaded
+	assert.Equal(t, 0, manager.retryCounter)
+}
+
+func TestCheckDiskSpaceHeadroom(t *testing.T) {
+	originalDiskSpace, originalConfig := getDiskSpaceInfo, getAppConfig
+	defer func() { getDiskSpaceInfo, getAppConfig = originalDiskSpace, originalConfig }()
+	getAppConfig = func(bool) (appconfig.SsmagentConfig, error) {
+		config := appconfig.DefaultConfig()
+		config.Agent.UpdateDiskSpaceHeadroomMb = 500
+		return config, nil
+	}
+
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: 499 * 1024 * 1024}, nil
+	}
+	assert.Error(t, checkDiskSpaceHeadroom(logger))
+
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{AvailBytes: 500 * 1024 * 1024}, nil
+	}
+	assert.NoError(t, checkDiskSpaceHeadroom(logger))
+
+	// the update proceeds if the disk space is unknown
+	getDiskSpaceInfo = func() (fileutil.DiskSpaceInfo, error) {
+		return fileutil.DiskSpaceInfo{}, fmt.Errorf("failed")
+	}
+	assert.NoError(t, checkDiskSpaceHeadroom(logger))
+}
+
 func TestUpdateAgent_NegativeTestCases(t *testing.T) {
 	pluginInput := createStubPluginInput()
 	pluginInput.TargetVersion = ""
@@ -389,6 +480,62 @@ func TestUpdateAgent_NegativeTestCases(t *testing.T) {
 	}
 }
 
+// stubUpdatePluginConfigSources stubs the region and the appconfig GetUpdatePluginConfig builds the config from
+func stubUpdatePluginConfigSources(region string, config appconfig.SsmagentConfig) func() {
+	originalRegion, originalConfig := getRegion, getAppConfig
+	getRegion = func() (string, error) { return region, nil }
+	getAppConfig = func(bool) (appconfig.SsmagentConfig, error) { return config, nil }
+	return func() {
+		getRegion, getAppConfig = originalRegion, originalConfig
+	}
+}
+
+func TestGetUpdatePluginConfigDefaults(t *testing.T) {
+	defer stubUpdatePluginConfigSources("us-east-1", appconfig.DefaultConfig())()
+
+	config := GetUpdatePluginConfig(context.NewMockDefault())
+
+	expectedManifestLocation := retrieveDynamicS3ManifestUrl("us-east-1", "s3")
+	if expectedManifestLocation == "" {
+		expectedManifestLocation = CommonManifestURL
+	}
+	assert.Equal(t, expectedManifestLocation, config.ManifestLocation)
+	assert.Equal(t, appconfig.DefaultUpdateMaxAttempts, config.MaxAttempts)
+	assert.Equal(t, appconfig.DefaultUpdateRetryDelayMillis, config.RetryDelayMillis)
+}
+
+func TestGetUpdatePluginConfigOverrides(t *testing.T) {
+	agentConfig := appconfig.DefaultConfig()
+	agentConfig.Agent.UpdateManifestLocation = "https://mirror.example.com/{Region}/ssm-agent-manifest.json"
+	agentConfig.Agent.UpdateMaxAttempts = 5
+	agentConfig.Agent.UpdateRetryDelayMillis = 3000
+	agentConfig.Agent.UpdateProxyURL = "http://proxy.example.com:3128"
+	agentConfig.Agent.UpdateCABundlePath = "/etc/pki/proxy-ca.pem"
+	defer stubUpdatePluginConfigSources("us-east-1", agentConfig)()
+
+	config := GetUpdatePluginConfig(context.NewMockDefault())
+	plugin, _ := NewPlugin(config)
+
+	assert.Equal(t, "https://mirror.example.com/{Region}/ssm-agent-manifest.json", plugin.ManifestLocation)
+	assert.Equal(t, 5, plugin.maxAttempts())
+	assert.Equal(t, 3000, plugin.retryDelayMillis())
+	assert.Equal(t, "http://proxy.example.com:3128", plugin.ProxyURL)
+	assert.Equal(t, "/etc/pki/proxy-ca.pem", plugin.CABundlePath)
+}
+
+func TestUpdateAgent_ConfiguredAttempts(t *testing.T) {
+	plugin := &Plugin{MaxAttempts: 3, RetryDelayMillis: 1}
+	manager := &fakeUpdateManager{
+		downloadManifestError: fmt.Errorf("test"),
+	}
+	out := iohandler.DefaultIOHandler{}
+	pluginInput := createStubPluginInput()
+
+	updateAgent(plugin, contracts.Configuration{}, logger, manager, &fakeUtility{}, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, 3, manager.retryCounter)
+}
+
 func TestExecute(t *testing.T) {
 	pluginInput := createStubPluginInput()
 	pluginInput.TargetVersion = ""
@@ -578,6 +725,81 @@ func TestExecuteFailureDuringUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateAgent_CheckOnlyReportsAvailableUpdate(t *testing.T) {
+	pluginInput := createStubPluginInput()
+	context := createStubInstanceContext()
+	manager := &fakeUpdateManager{downloadManifestResult: createStubManifest(pluginInput, context, true, true)}
+	util := &fakeUtility{}
+	pluginInput.TargetVersion = ""
+	pluginInput.CheckOnly = "true"
+	out := iohandler.DefaultIOHandler{}
+
+	runUpdateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, util, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusSuccess, out.GetStatus())
+	assert.Equal(t, UpdateCheckOutput{
+		CurrentVersion:  version.Version,
+		LatestVersion:   "9000.0.0.0",
+		UpdateAvailable: true,
+	}, out.GetOutput())
+	assert.Contains(t, out.GetStdout(), "9000.0.0.0 is available")
+	// nothing got downloaded nor installed
+	assert.Equal(t, 1, manager.retryCounter)
+	assert.Equal(t, 0, util.retryCounter)
+}
+
+func TestUpdateAgent_CheckOnlyReportsUpToDate(t *testing.T) {
+	pluginInput := createStubPluginInput()
+	context := createStubInstanceContext()
+	manager := &fakeUpdateManager{downloadManifestResult: createStubManifest(pluginInput, context, true, false)}
+	util := &fakeUtility{}
+	pluginInput.TargetVersion = ""
+	pluginInput.CheckOnly = "true"
+	out := iohandler.DefaultIOHandler{}
+
+	runUpdateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, util, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusSuccess, out.GetStatus())
+	assert.Equal(t, UpdateCheckOutput{
+		CurrentVersion:  version.Version,
+		LatestVersion:   version.Version,
+		UpdateAvailable: false,
+	}, out.GetOutput())
+	assert.Contains(t, out.GetStdout(), "is up to date")
+	assert.Equal(t, 0, util.retryCounter)
+}
+
+func TestUpdateAgent_CheckOnlyIgnoresRollout(t *testing.T) {
+	original := getInstanceID
+	getInstanceID = func() (string, error) { return "i-1234567890abcdef0", nil }
+	defer func() { getInstanceID = original }()
+	pluginInput := createStubPluginInput()
+	context := createStubInstanceContext()
+	manager := &fakeUpdateManager{downloadManifestResult: createStubManifest(pluginInput, context, true, true)}
+	pluginInput.CheckOnly = "true"
+	pluginInput.RolloutPercentage = "0"
+	out := iohandler.DefaultIOHandler{}
+
+	runUpdateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, &fakeUtility{}, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusSuccess, out.GetStatus())
+	assert.NotContains(t, out.GetStdout(), "deferred")
+	assert.NotNil(t, out.GetOutput())
+}
+
+func TestUpdateAgent_InvalidCheckOnly(t *testing.T) {
+	pluginInput := createStubPluginInput()
+	pluginInput.CheckOnly = "sometimes"
+	manager := &fakeUpdateManager{}
+	out := iohandler.DefaultIOHandler{}
+
+	runUpdateAgent(&Plugin{}, contracts.Configuration{}, logger, manager, &fakeUtility{}, pluginInput, new(task.MockCancelFlag), &out, time.Now())
+
+	assert.Equal(t, contracts.ResultStatusFailed, out.GetStatus())
+	assert.Contains(t, out.GetStderr(), "invalid check only value")
+	assert.Equal(t, 0, manager.retryCounter)
+}
+
 func createStubPluginInput() *UpdatePluginInput {
 	input := UpdatePluginInput{}
 
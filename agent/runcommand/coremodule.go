@@ -37,7 +37,7 @@ import (
 const (
 	documentContent  = "DocumentContent"
 	runtimeConfig    = "runtimeConfig"
-	cloudwatchPlugin = "aws:cloudWatch"
+	cloudwatchPlugin = appconfig.PluginNameCloudWatch
 	properties       = "properties"
 	parameters       = "Parameters"
 	// MDS service will mark document as timeout if it didn't recieve any responce from the agent after 2 hours
@@ -16,6 +16,7 @@ package task
 
 import (
 	"sync"
+	"time"
 )
 
 // State represents the state of a job.
@@ -32,6 +33,23 @@ const (
 	ShutDown State = 3
 )
 
+// CancelReason represents why a job has been canceled or shut down.
+type CancelReason int
+
+const (
+	// CancelReasonNone indicates that no cancellation has been requested, or no reason was given.
+	CancelReasonNone CancelReason = 0
+
+	// CancelReasonUser indicates a cancellation requested by the user.
+	CancelReasonUser CancelReason = 1
+
+	// CancelReasonShutdown indicates a cancellation caused by the agent shutting down.
+	CancelReasonShutdown CancelReason = 2
+
+	// CancelReasonTimeout indicates a cancellation caused by the job running out of time.
+	CancelReasonTimeout CancelReason = 3
+)
+
 // CancelFlag is an object that is passed to any job submitted to a task in order to
 // communicated job cancellation. Job cancellation has to be cooperative.
 type CancelFlag interface {
@@ -40,8 +58,15 @@ type CancelFlag interface {
 	Canceled() bool
 
 	// Set sets the state of this flag and wakes up waiting callers.
+	// The reason is derived from the state, see SetWithReason.
 	Set(state State)
 
+	// SetWithReason sets the state and the reason of this flag and wakes up waiting callers.
+	SetWithReason(state State, reason CancelReason)
+
+	// Reason returns why the flag has been set, CancelReasonNone if it hasn't been canceled.
+	Reason() CancelReason
+
 	// ShutDown returns true if a ShutDown has been requested, false otherwise.
 	// This method should be called periodically in the job.
 	ShutDown() bool
@@ -58,11 +83,18 @@ type CancelFlag interface {
 	// In the go routine, once Wait returns, if the return value indicates that a cancel
 	// request has been received, the go routine wakes up the running job.
 	Wait() (state State)
+
+	// WaitWithTimeout blocks the caller like Wait but returns after the given duration at the latest.
+	// timedOut is true if the duration elapsed before the flag was set, in which case state is the
+	// current flag state.
+	// This allows a job to do periodic work while still observing a cancel request promptly.
+	WaitWithTimeout(d time.Duration) (state State, timedOut bool)
 }
 
 // ChanneledCancelFlag is a default implementation of the task.CancelFlag interface.
 type ChanneledCancelFlag struct {
 	state  State
+	reason CancelReason
 	ch     chan struct{}
 	closed bool
 	m      sync.RWMutex
@@ -95,17 +127,43 @@ func (t *ChanneledCancelFlag) State() State {
 	return t.state
 }
 
+// Reason returns why this flag has been set.
+func (t *ChanneledCancelFlag) Reason() CancelReason {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.reason
+}
+
 // Wait blocks until the flag is set to either Cancel or Completed state. Returns the state.
 func (t *ChanneledCancelFlag) Wait() (state State) {
 	<-t.ch
 	return t.State()
 }
 
-// Set sets the state of this flag and wakes up waiting callers.
+// WaitWithTimeout blocks until the flag is set or the given duration elapses, whichever comes first.
+func (t *ChanneledCancelFlag) WaitWithTimeout(d time.Duration) (state State, timedOut bool) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-t.ch:
+		return t.State(), false
+	case <-timer.C:
+		return t.State(), true
+	}
+}
+
+// Set sets the state of this flag and wakes up waiting callers. Canceled is attributed to the user
+// and ShutDown to the agent shutting down.
 func (t *ChanneledCancelFlag) Set(state State) {
+	t.SetWithReason(state, defaultReason(state))
+}
+
+// SetWithReason sets the state and the reason of this flag and wakes up waiting callers.
+func (t *ChanneledCancelFlag) SetWithReason(state State, reason CancelReason) {
 	t.m.Lock()
 	defer t.m.Unlock()
 	t.state = state
+	t.reason = reason
 
 	// close channel to wake up routines that are waiting
 	if !t.closed {
@@ -114,3 +172,22 @@ func (t *ChanneledCancelFlag) Set(state State) {
 		t.closed = true
 	}
 }
+
+// defaultReason returns the reason of a flag set to the given state without an explicit reason.
+func defaultReason(state State) CancelReason {
+	switch state {
+	case Canceled:
+		return CancelReasonUser
+	case ShutDown:
+		return CancelReasonShutdown
+	default:
+		return CancelReasonNone
+	}
+}
+
+// Sleep pauses the caller for the given duration or until the flag is set, whichever comes first.
+// cancelled is true if a cancel or ShutDown has been requested before or during the sleep.
+func Sleep(cancelFlag CancelFlag, d time.Duration) (cancelled bool) {
+	state, _ := cancelFlag.WaitWithTimeout(d)
+	return state == Canceled || state == ShutDown
+}
@@ -15,6 +15,7 @@ package task
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,3 +68,134 @@ func testWait(t *testing.T, state State) {
 	assert.Equal(t, state, <-ch)
 	assert.Equal(t, flag.Canceled(), state == Canceled)
 }
+
+// TestReason tests that the reason follows the state set on the flag
+func TestReason(t *testing.T) {
+	cancelFlag := NewChanneledCancelFlag()
+	assert.Equal(t, CancelReasonNone, cancelFlag.Reason())
+
+	cancelFlag.Set(Canceled)
+	assert.Equal(t, CancelReasonUser, cancelFlag.Reason())
+
+	cancelFlag.Set(ShutDown)
+	assert.Equal(t, CancelReasonShutdown, cancelFlag.Reason())
+
+	cancelFlag.Set(Completed)
+	assert.Equal(t, CancelReasonNone, cancelFlag.Reason())
+}
+
+// TestSetWithReason tests that each reason is reported without changing the meaning of the state
+func TestSetWithReason(t *testing.T) {
+	reasons := []CancelReason{CancelReasonUser, CancelReasonShutdown, CancelReasonTimeout}
+	for _, reason := range reasons {
+		cancelFlag := NewChanneledCancelFlag()
+		cancelFlag.SetWithReason(Canceled, reason)
+		assert.Equal(t, reason, cancelFlag.Reason())
+		assert.True(t, cancelFlag.Canceled())
+		assert.False(t, cancelFlag.ShutDown())
+	}
+
+	cancelFlag := NewChanneledCancelFlag()
+	cancelFlag.SetWithReason(ShutDown, CancelReasonTimeout)
+	assert.Equal(t, CancelReasonTimeout, cancelFlag.Reason())
+	assert.True(t, cancelFlag.ShutDown())
+	assert.False(t, cancelFlag.Canceled())
+}
+
+// TestWaitWithReason tests that Wait returns once the flag is set with any reason
+func TestWaitWithReason(t *testing.T) {
+	reasons := []CancelReason{CancelReasonUser, CancelReasonShutdown, CancelReasonTimeout}
+	for _, reason := range reasons {
+		flag := NewChanneledCancelFlag()
+		ch := make(chan State)
+		go func() {
+			ch <- flag.Wait()
+		}()
+
+		flag.SetWithReason(Canceled, reason)
+
+		assert.Equal(t, Canceled, <-ch)
+		assert.Equal(t, reason, flag.Reason())
+	}
+}
+
+// TestWaitWithTimeoutElapses tests that WaitWithTimeout returns once the duration elapses on an unset flag
+func TestWaitWithTimeoutElapses(t *testing.T) {
+	flag := NewChanneledCancelFlag()
+
+	state, timedOut := flag.WaitWithTimeout(10 * time.Millisecond)
+
+	assert.True(t, timedOut)
+	assert.Equal(t, State(0), state)
+	assert.False(t, flag.Canceled())
+}
+
+// TestWaitWithTimeoutCanceled tests that WaitWithTimeout returns promptly once the flag is set
+func TestWaitWithTimeoutCanceled(t *testing.T) {
+	flag := NewChanneledCancelFlag()
+	type result struct {
+		state    State
+		timedOut bool
+	}
+	ch := make(chan result)
+	go func() {
+		state, timedOut := flag.WaitWithTimeout(time.Minute)
+		ch <- result{state, timedOut}
+	}()
+
+	flag.Set(Canceled)
+
+	select {
+	case r := <-ch:
+		assert.False(t, r.timedOut)
+		assert.Equal(t, Canceled, r.state)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "WaitWithTimeout didn't return after the flag was set")
+	}
+
+	// a flag that is already set doesn't wait at all
+	state, timedOut := flag.WaitWithTimeout(time.Minute)
+	assert.False(t, timedOut)
+	assert.Equal(t, Canceled, state)
+}
+
+// TestSleepFullDuration tests that Sleep sleeps for the whole duration on an unset flag
+func TestSleepFullDuration(t *testing.T) {
+	flag := NewChanneledCancelFlag()
+
+	start := time.Now()
+	cancelled := Sleep(flag, 20*time.Millisecond)
+
+	assert.False(t, cancelled)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+// TestSleepCancelledEarly tests that Sleep returns promptly once the flag is set during the sleep
+func TestSleepCancelledEarly(t *testing.T) {
+	flag := NewChanneledCancelFlag()
+	ch := make(chan bool)
+	go func() {
+		ch <- Sleep(flag, time.Minute)
+	}()
+
+	flag.Set(ShutDown)
+
+	select {
+	case cancelled := <-ch:
+		assert.True(t, cancelled)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Sleep didn't return after the flag was set")
+	}
+}
+
+// TestSleepAlreadyCancelled tests that Sleep doesn't sleep at all on a canceled flag
+func TestSleepAlreadyCancelled(t *testing.T) {
+	flag := NewChanneledCancelFlag()
+	flag.Set(Canceled)
+
+	start := time.Now()
+	cancelled := Sleep(flag, time.Minute)
+
+	assert.True(t, cancelled)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -14,10 +14,15 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 )
 
+// ErrDuplicateJob is returned when a job is added with the id of a job that already exists, so that callers
+// relying on unique job ids can tell it apart from other failures
+var ErrDuplicateJob = errors.New("job with the same id already exists")
+
 // JobStore is a collection of jobs.
 type JobStore struct {
 	jobs map[string]*JobToken
@@ -32,14 +37,14 @@ func NewJobStore() *JobStore {
 }
 
 // AddJob adds a job to this task.
-// Returns error if the job already exists.
+// Returns an error wrapping ErrDuplicateJob if the job already exists.
 func (t *JobStore) AddJob(jobID string, token *JobToken) error {
 	t.m.Lock()
 	defer t.m.Unlock()
 
 	_, found := t.jobs[jobID]
 	if found {
-		return fmt.Errorf("Job with id %v already exists", jobID)
+		return fmt.Errorf("%w: %v", ErrDuplicateJob, jobID)
 	}
 
 	t.jobs[jobID] = token
@@ -14,6 +14,7 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -72,7 +73,8 @@ func testAddAndGet(t *testing.T, jobs map[string]*JobToken) (tsk *JobStore) {
 		// test add existing job
 		token2 := &JobToken{id: jobID}
 		err = tsk.AddJob(jobID, token2)
-		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrDuplicateJob))
+		assert.Contains(t, err.Error(), jobID)
 	}
 	return
 }
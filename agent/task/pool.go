@@ -14,20 +14,33 @@
 package task
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/times"
 )
 
+// ErrPoolFull is returned by pools with a bounded queue when a job is submitted while the queue is full
+var ErrPoolFull = errors.New("pool queue is full")
+
 // Pool is a pool of jobs.
 type Pool interface {
 	// Submit schedules a job to be executed in the associated worker pool.
-	// Returns an error if a job with the same name already exists.
+	// Returns an error wrapping ErrDuplicateJob if a job with the same name already exists and ErrPoolFull if the queue of a
+	// bounded pool is full.
 	Submit(log log.T, jobID string, job Job) error
 
+	// SubmitWithTimeout schedules a job like Submit, the job's CancelFlag is set to the Canceled state with
+	// CancelReasonTimeout once the job has been running for the given timeout.
+	// It is the responsibility of the job to observe the flag and terminate, a job that doesn't is abandoned
+	// after the cancel wait duration of the pool and its worker is reclaimed for other jobs.
+	SubmitWithTimeout(log log.T, jobID string, job Job, timeout time.Duration) error
+
 	// Cancel cancels the given job. Jobs that have not started yet will never be started.
 	// Jobs that are running will have their CancelFlag set to the Canceled state.
 	// It is the responsibility of the job to terminate within a reasonable time.
@@ -43,8 +56,21 @@ type Pool interface {
 	// workers terminated before the timeout or false if the timeout expired.
 	ShutdownAndWait(timeout time.Duration) (finished bool)
 
+	// ShutdownAndWaitForJobs behaves like ShutdownAndWait and additionally returns the ids of the jobs
+	// that were still running when it returned, these jobs didn't honor the cancellation and got abandoned.
+	ShutdownAndWaitForJobs(timeout time.Duration) (finished bool, unfinished []string)
+
 	// HasJob returns if jobStore has specified job
 	HasJob(jobID string) bool
+
+	// QueueDepth returns the number of submitted jobs that are waiting for a worker.
+	QueueDepth() int
+
+	// ActiveWorkers returns the number of workers that are running a job.
+	ActiveWorkers() int
+
+	// CompletedJobs returns the number of jobs the workers finished running.
+	CompletedJobs() uint64
 }
 
 // pool implements a task pool where all jobs are managed by a root task
@@ -58,6 +84,17 @@ type pool struct {
 	mut            sync.Mutex
 	jobStore       *JobStore
 	cancelDuration time.Duration
+	// bounded pools reject jobs instead of waiting for a free worker when the queue is full
+	bounded bool
+	// running holds the ids of the jobs that haven't returned yet, keyed by the cancel flag of their submission
+	// so that an abandoned job returning late doesn't clear a job resubmitted under the same id
+	running     map[*ChanneledCancelFlag]string
+	runningLock sync.Mutex
+
+	// metrics, they're updated atomically so that reading them doesn't block the workers
+	queued        int64
+	activeWorkers int64
+	completedJobs uint64
 }
 
 // JobToken embeds a job and its associated info
@@ -66,27 +103,55 @@ type JobToken struct {
 	job        Job
 	cancelFlag *ChanneledCancelFlag
 	log        log.T
+	// timeout is how long the job may run before it gets canceled, 0 means no timeout
+	timeout time.Duration
 }
 
 // NewPool creates a new task pool and launches maxParallel workers.
 // The cancelWaitDuration parameter defines how long to wait for a job
 // to complete a cancellation request.
+// Submitting a job waits until a worker is available.
 func NewPool(log log.T, maxParallel int, cancelWaitDuration time.Duration, clock times.Clock) Pool {
+	return newPool(log, maxParallel, 0, false, cancelWaitDuration, clock)
+}
+
+// NewBoundedPool creates a new task pool and launches maxParallel workers.
+// Up to queueSize jobs wait for a free worker, submitting a job while the queue is full
+// fails with ErrPoolFull so that callers can't queue up jobs without limit.
+func NewBoundedPool(log log.T, maxParallel int, queueSize int, cancelWaitDuration time.Duration, clock times.Clock) Pool {
+	return newPool(log, maxParallel, queueSize, true, cancelWaitDuration, clock)
+}
+
+// newPool creates a new task pool whose queue holds queueSize jobs and launches maxParallel workers.
+func newPool(log log.T, maxParallel int, queueSize int, bounded bool, cancelWaitDuration time.Duration, clock times.Clock) *pool {
 	p := &pool{
 		log:            log,
-		jobQueue:       make(chan JobToken),
+		jobQueue:       make(chan JobToken, queueSize),
 		nWorkers:       maxParallel,
 		doneWorker:     make(chan struct{}),
 		clock:          clock,
 		cancelDuration: cancelWaitDuration,
+		bounded:        bounded,
+		running:        make(map[*ChanneledCancelFlag]string),
 	}
 
 	p.jobStore = NewJobStore()
 
 	// defines the job processing function.
 	processor := func(j JobToken) {
+		atomic.AddInt64(&p.activeWorkers, 1)
+		defer func() {
+			atomic.AddInt64(&p.activeWorkers, -1)
+			atomic.AddUint64(&p.completedJobs, 1)
+		}()
 		defer p.jobStore.DeleteJob(j.id)
-		process(j.log, j.job, j.cancelFlag, cancelWaitDuration, p.clock)
+		// the job counts as running until it returns, even if the worker abandons it
+		p.setRunning(j, true)
+		job := func(cancelFlag CancelFlag) {
+			defer p.setRunning(j, false)
+			j.job(cancelFlag)
+		}
+		process(j.log, job, j.cancelFlag, j.timeout, cancelWaitDuration, p.clock)
 	}
 
 	// start the workers
@@ -115,6 +180,13 @@ func (p *pool) Shutdown() {
 // or until the timeout has elapsed, whichever comes first. Returns true if all
 // workers terminated before the timeout or false if the timeout expired.
 func (p *pool) ShutdownAndWait(timeout time.Duration) (finished bool) {
+	finished, _ = p.ShutdownAndWaitForJobs(timeout)
+	return
+}
+
+// ShutdownAndWaitForJobs calls Shutdown then waits like ShutdownAndWait. Returns the ids of the jobs
+// that were still running once the wait is over.
+func (p *pool) ShutdownAndWaitForJobs(timeout time.Duration) (finished bool, unfinished []string) {
 	p.Shutdown()
 
 	timeoutTimer := p.clock.After(timeout)
@@ -126,7 +198,7 @@ func (p *pool) ShutdownAndWait(timeout time.Duration) (finished bool) {
 			workersRunning--
 			if workersRunning == 0 {
 				p.log.Debug("Pool shutdown normally.")
-				return true
+				return true, p.runningJobs()
 			}
 			p.log.Debugf("Pool worker done; %d still running", workersRunning)
 
@@ -136,10 +208,37 @@ func (p *pool) ShutdownAndWait(timeout time.Duration) (finished bool) {
 			p.CancelAll()
 		case <-exitTimer:
 			p.log.Debugf("Pool eventual timeout with %d workers still running ", workersRunning)
-			return false
+			return false, p.runningJobs()
 		}
 	}
-	return true
+	return true, p.runningJobs()
+}
+
+// setRunning records whether the job of the given submission is running.
+func (p *pool) setRunning(j JobToken, running bool) {
+	p.runningLock.Lock()
+	defer p.runningLock.Unlock()
+	if running {
+		p.running[j.cancelFlag] = j.id
+	} else {
+		delete(p.running, j.cancelFlag)
+	}
+}
+
+// runningJobs returns the sorted ids of the jobs that are running.
+func (p *pool) runningJobs() []string {
+	p.runningLock.Lock()
+	defer p.runningLock.Unlock()
+	ids := make(map[string]struct{}, len(p.running))
+	for _, jobID := range p.running {
+		ids[jobID] = struct{}{}
+	}
+	jobIDs := make([]string, 0, len(ids))
+	for jobID := range ids {
+		jobIDs = append(jobIDs, jobID)
+	}
+	sort.Strings(jobIDs)
+	return jobIDs
 }
 
 // start starts the workers of this pool
@@ -148,7 +247,7 @@ func (p *pool) start(jobProcessor func(JobToken)) {
 		workerName := fmt.Sprintf("worker-%d", i)
 		go func() {
 			defer p.workerDone()
-			worker(workerName, p.jobQueue, jobProcessor)
+			worker(workerName, p.jobQueue, p.dequeued, jobProcessor)
 		}()
 	}
 }
@@ -158,9 +257,15 @@ func (p *pool) workerDone() {
 	p.doneWorker <- struct{}{}
 }
 
+// dequeued records that a worker took a job from the queue.
+func (p *pool) dequeued() {
+	atomic.AddInt64(&p.queued, -1)
+}
+
 // worker processes jobs from a channel.
-func worker(workerName string, queue chan JobToken, processor func(JobToken)) {
+func worker(workerName string, queue chan JobToken, dequeued func(), processor func(JobToken)) {
 	for token := range queue {
+		dequeued()
 		if !token.cancelFlag.Canceled() {
 			processor(token)
 		}
@@ -169,20 +274,53 @@ func worker(workerName string, queue chan JobToken, processor func(JobToken)) {
 
 // Submit adds a job to the execution queue of this pool.
 func (p *pool) Submit(log log.T, jobID string, job Job) (err error) {
+	return p.SubmitWithTimeout(log, jobID, job, 0)
+}
+
+// SubmitWithTimeout adds a job to the execution queue of this pool, the job gets canceled once it has been
+// running for the given timeout. A timeout of 0 means the job runs without a deadline.
+func (p *pool) SubmitWithTimeout(log log.T, jobID string, job Job, timeout time.Duration) (err error) {
 	token := JobToken{
 		id:         jobID,
 		job:        job,
 		cancelFlag: NewChanneledCancelFlag(),
 		log:        log,
+		timeout:    timeout,
 	}
 	err = p.jobStore.AddJob(jobID, &token)
 	if err != nil {
 		return
 	}
-	p.jobQueue <- token
+	atomic.AddInt64(&p.queued, 1)
+	if !p.bounded {
+		p.jobQueue <- token
+		return
+	}
+	select {
+	case p.jobQueue <- token:
+	default:
+		atomic.AddInt64(&p.queued, -1)
+		p.jobStore.DeleteJob(jobID)
+		err = ErrPoolFull
+	}
 	return
 }
 
+// QueueDepth returns the number of submitted jobs that are waiting for a worker.
+func (p *pool) QueueDepth() int {
+	return int(atomic.LoadInt64(&p.queued))
+}
+
+// ActiveWorkers returns the number of workers that are running a job.
+func (p *pool) ActiveWorkers() int {
+	return int(atomic.LoadInt64(&p.activeWorkers))
+}
+
+// CompletedJobs returns the number of jobs the workers finished running.
+func (p *pool) CompletedJobs() uint64 {
+	return atomic.LoadUint64(&p.completedJobs)
+}
+
 // HasJob returns if jobStore has specified job
 func (p *pool) HasJob(jobID string) bool {
 	_, found := p.jobStore.GetJob(jobID)
@@ -14,6 +14,7 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -48,6 +49,93 @@ func TestPool(t *testing.T) {
 	}
 }
 
+func TestSubmitSameJobIDTwiceReturnsErrDuplicateJob(t *testing.T) {
+	pool := NewPool(logger, 1, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+	release := make(chan struct{})
+
+	assert.NoError(t, pool.Submit(logger, "job", func(CancelFlag) { <-release }))
+	err := pool.Submit(logger, "job", func(CancelFlag) {})
+	assert.True(t, errors.Is(err, ErrDuplicateJob))
+	assert.EqualError(t, err, ErrDuplicateJob.Error()+": job")
+	close(release)
+	// other job ids are still accepted
+	assert.NoError(t, pool.Submit(logger, "other job", func(CancelFlag) {}))
+}
+
+func TestBoundedPoolRejectsJobsWhenFull(t *testing.T) {
+	pool := NewBoundedPool(logger, 1, 1, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	// the worker is busy with the running job and the queued job fills the queue
+	assert.NoError(t, pool.Submit(logger, "running", func(CancelFlag) {
+		close(started)
+		<-release
+	}))
+	<-started
+	assert.NoError(t, pool.Submit(logger, "queued", func(CancelFlag) {}))
+
+	assert.Equal(t, ErrPoolFull, pool.Submit(logger, "rejected", func(CancelFlag) {}))
+	assert.False(t, pool.HasJob("rejected"))
+}
+
+func TestBoundedPoolAcceptsJobsAfterDraining(t *testing.T) {
+	pool := NewBoundedPool(logger, 1, 1, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+	started, release, queuedDone := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	assert.NoError(t, pool.Submit(logger, "running", func(CancelFlag) {
+		close(started)
+		<-release
+	}))
+	<-started
+	assert.NoError(t, pool.Submit(logger, "queued", func(CancelFlag) { close(queuedDone) }))
+	assert.Equal(t, ErrPoolFull, pool.Submit(logger, "rejected", func(CancelFlag) {}))
+
+	close(release)
+	<-queuedDone
+
+	// the rejected job id can be submitted again once the queue drained
+	done := make(chan struct{})
+	assert.NoError(t, pool.Submit(logger, "rejected", func(CancelFlag) { close(done) }))
+	<-done
+}
+
+func TestPoolMetrics(t *testing.T) {
+	pool := NewBoundedPool(logger, 1, 2, 100*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+	started, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	assert.Equal(t, 0, pool.QueueDepth())
+	assert.Equal(t, 0, pool.ActiveWorkers())
+	assert.Equal(t, uint64(0), pool.CompletedJobs())
+
+	assert.NoError(t, pool.Submit(logger, "running", func(CancelFlag) {
+		close(started)
+		<-release
+	}))
+	<-started
+	assert.NoError(t, pool.Submit(logger, "queued", func(CancelFlag) {}))
+	assert.NoError(t, pool.Submit(logger, "last", func(CancelFlag) { close(done) }))
+	assert.Equal(t, ErrPoolFull, pool.Submit(logger, "rejected", func(CancelFlag) {}))
+
+	assert.Equal(t, 2, pool.QueueDepth())
+	assert.Equal(t, 1, pool.ActiveWorkers())
+	assert.Equal(t, uint64(0), pool.CompletedJobs())
+
+	close(release)
+	<-done
+	// the counters are updated once the last job returned
+	for pool.CompletedJobs() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 0, pool.QueueDepth())
+	assert.Equal(t, 0, pool.ActiveWorkers())
+	assert.Equal(t, uint64(3), pool.CompletedJobs())
+}
+
 func testPool(t *testing.T, nWorkers int, nJobs int, shouldCancel bool) {
 	clock := times.NewMockedClock()
 	waitTimeout := 100 * time.Millisecond
@@ -101,7 +189,7 @@ func exercisePool(t *testing.T, pool Pool, jobID string, shouldCancel bool) {
 
 	// check that job cannot be submitted again
 	err = pool.Submit(logger, jobID, func(CancelFlag) {})
-	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, ErrDuplicateJob))
 
 	// see that job starts
 	assert.True(t, <-jobState)
@@ -119,3 +207,102 @@ func exercisePool(t *testing.T, pool Pool, jobID string, shouldCancel bool) {
 	// see that job completes
 	assert.True(t, <-jobState)
 }
+
+func TestSubmitWithTimeoutCancelsCooperativeJob(t *testing.T) {
+	pool := NewPool(logger, 1, time.Minute, times.DefaultClock)
+	defer pool.Shutdown()
+	reason := make(chan CancelReason, 1)
+
+	assert.NoError(t, pool.SubmitWithTimeout(logger, "cooperative", func(cancelFlag CancelFlag) {
+		cancelFlag.Wait()
+		reason <- cancelFlag.Reason()
+	}, 10*time.Millisecond))
+
+	assert.Equal(t, CancelReasonTimeout, <-reason)
+	// the worker is free for the next job
+	done := make(chan struct{})
+	assert.NoError(t, pool.Submit(logger, "next", func(CancelFlag) { close(done) }))
+	<-done
+}
+
+func TestSubmitWithTimeoutReclaimsWorkerOfStubbornJob(t *testing.T) {
+	pool := NewPool(logger, 1, 10*time.Millisecond, times.DefaultClock)
+	defer pool.Shutdown()
+	release := make(chan struct{})
+	defer close(release)
+
+	// the job ignores its cancel flag
+	assert.NoError(t, pool.SubmitWithTimeout(logger, "stubborn", func(CancelFlag) { <-release }, 10*time.Millisecond))
+
+	// the worker gets abandoned by the stubborn job after the cancel wait and runs the next job
+	done := make(chan struct{})
+	assert.NoError(t, pool.Submit(logger, "next", func(CancelFlag) { close(done) }))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "worker wasn't reclaimed from the stubborn job")
+	}
+	assert.False(t, pool.HasJob("stubborn"))
+}
+
+func TestSubmitWithTimeoutDoesNotCancelJobFinishingInTime(t *testing.T) {
+	pool := NewPool(logger, 1, time.Minute, times.DefaultClock)
+	defer pool.Shutdown()
+	canceled := make(chan bool, 1)
+
+	assert.NoError(t, pool.SubmitWithTimeout(logger, "fast", func(cancelFlag CancelFlag) {
+		canceled <- cancelFlag.Canceled()
+	}, time.Minute))
+
+	assert.False(t, <-canceled)
+}
+
+func TestShutdownAndWaitForJobsReportsJobsOutlivingTimeout(t *testing.T) {
+	pool := NewPool(logger, 2, 10*time.Millisecond, times.DefaultClock)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	// the stubborn job ignores its cancel flag, the quick one finishes before the shutdown
+	assert.NoError(t, pool.Submit(logger, "stubborn", func(CancelFlag) {
+		close(started)
+		<-release
+	}))
+	quickDone := make(chan struct{})
+	assert.NoError(t, pool.Submit(logger, "quick", func(CancelFlag) { close(quickDone) }))
+	<-started
+	<-quickDone
+
+	_, unfinished := pool.ShutdownAndWaitForJobs(10 * time.Millisecond)
+
+	assert.Equal(t, []string{"stubborn"}, unfinished)
+}
+
+func TestShutdownAndWaitForJobsReportsNothingWhenJobsFinish(t *testing.T) {
+	pool := NewPool(logger, 1, 10*time.Millisecond, times.DefaultClock)
+	done := make(chan struct{})
+	assert.NoError(t, pool.Submit(logger, "job", func(CancelFlag) { close(done) }))
+	<-done
+
+	finished, unfinished := pool.ShutdownAndWaitForJobs(time.Second)
+
+	assert.True(t, finished)
+	assert.Empty(t, unfinished)
+}
+
+func TestRunningJobsKeepsJobResubmittedAfterAbandonedOneReturns(t *testing.T) {
+	p := newPool(logger, 1, 0, false, 10*time.Millisecond, times.DefaultClock)
+	defer p.Shutdown()
+	abandoned := JobToken{id: "job", cancelFlag: NewChanneledCancelFlag()}
+	resubmitted := JobToken{id: "job", cancelFlag: NewChanneledCancelFlag()}
+
+	p.setRunning(abandoned, true)
+	p.setRunning(resubmitted, true)
+	assert.Equal(t, []string{"job"}, p.runningJobs())
+
+	// the abandoned job finally returns while the resubmitted one is still running
+	p.setRunning(abandoned, false)
+	assert.Equal(t, []string{"job"}, p.runningJobs())
+
+	p.setRunning(resubmitted, false)
+	assert.Empty(t, p.runningJobs())
+}
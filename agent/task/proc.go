@@ -25,7 +25,8 @@ import (
 // If cancel is requested, this function waits for some time to allow the
 // job to complete. If the job does not complete by the timeout, the go routine
 // of the job is abandoned, and this function returns.
-func process(log log.T, job Job, cancelFlag *ChanneledCancelFlag, cancelWait time.Duration, clock times.Clock) {
+// A job running for longer than a non zero timeout gets canceled the same way.
+func process(log log.T, job Job, cancelFlag *ChanneledCancelFlag, timeout time.Duration, cancelWait time.Duration, clock times.Clock) {
 	// Make a buffered channel to avoid blocking on send. This helps
 	// in case the job fails to cancel on time and we give up on it.
 	// If the job finally ends, it will succeed to send a signal
@@ -36,15 +37,24 @@ func process(log log.T, job Job, cancelFlag *ChanneledCancelFlag, cancelWait tim
 
 	go runJob(log, func() { job(cancelFlag) }, doneChan)
 
-	done := waitEither(doneChan, cancelFlag.ch)
-	if done {
+	var deadline chan struct{}
+	if timeout > 0 {
+		deadline = clock.After(timeout)
+	}
+
+	select {
+	case <-doneChan:
 		// task done, set the flag to wake up waiting routines
 		cancelFlag.Set(Completed)
 		return
+	case <-cancelFlag.ch:
+	case <-deadline:
+		log.Debugf("Job didn't finish within %v, canceling it", timeout)
+		cancelFlag.SetWithReason(Canceled, CancelReasonTimeout)
 	}
 
 	log.Debugf("Execution has been canceled, waiting up to %v to finish", cancelWait)
-	done = waitEither(doneChan, clock.After(cancelWait))
+	done := waitEither(doneChan, clock.After(cancelWait))
 	if done {
 		// job completed within cancel waiting window
 		cancelFlag.Set(Completed)
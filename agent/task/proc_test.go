@@ -148,7 +148,7 @@ func createTestCaseForProcess(t *testing.T) TestCase {
 		job := func(CancelFlag) {
 			testCase.innerFunction()
 		}
-		process(logger, job, testCase.CancelFlag, testCase.CancelWaitMillis, testCase.Clock)
+		process(logger, job, testCase.CancelFlag, 0, testCase.CancelWaitMillis, testCase.Clock)
 	}
 	testCase.startTestMethod(testMethod)
 	return testCase
@@ -33,6 +33,11 @@ func (mockPool *MockedPool) Submit(log log.T, jobID string, job Job) error {
 	return mockPool.Called(log, jobID, job).Error(0)
 }
 
+// SubmitWithTimeout mocks the method with the same name.
+func (mockPool *MockedPool) SubmitWithTimeout(log log.T, jobID string, job Job, timeout time.Duration) error {
+	return mockPool.Called(log, jobID, job, timeout).Error(0)
+}
+
 // Cancel mocks the method with the same name.
 func (mockPool *MockedPool) Cancel(jobID string) bool {
 	return mockPool.Called(jobID).Bool(0)
@@ -49,12 +54,34 @@ func (mockPool *MockedPool) ShutdownAndWait(timeout time.Duration) (finished boo
 	return args.Bool(0)
 }
 
+// ShutdownAndWaitForJobs mocks the method with the same name.
+func (mockPool *MockedPool) ShutdownAndWaitForJobs(timeout time.Duration) (finished bool, unfinished []string) {
+	args := mockPool.Called(timeout)
+	unfinished, _ = args.Get(1).([]string)
+	return args.Bool(0), unfinished
+}
+
 // ShutdownAndWait mocks the method with the same name.
 func (mockPool *MockedPool) HasJob(jobID string) bool {
 	args := mockPool.Called(jobID)
 	return args.Bool(0)
 }
 
+// QueueDepth mocks the method with the same name.
+func (mockPool *MockedPool) QueueDepth() int {
+	return mockPool.Called().Int(0)
+}
+
+// ActiveWorkers mocks the method with the same name.
+func (mockPool *MockedPool) ActiveWorkers() int {
+	return mockPool.Called().Int(0)
+}
+
+// CompletedJobs mocks the method with the same name.
+func (mockPool *MockedPool) CompletedJobs() uint64 {
+	return mockPool.Called().Get(0).(uint64)
+}
+
 // MockCancelFlag mocks a cancel flag.
 type MockCancelFlag struct {
 	mock.Mock
@@ -81,10 +108,26 @@ func (flag *MockCancelFlag) Wait() (state State) {
 	return flag.Called().Get(0).(State)
 }
 
+// WaitWithTimeout mocks the method with the same name.
+func (flag *MockCancelFlag) WaitWithTimeout(d time.Duration) (state State, timedOut bool) {
+	ret := flag.Called(d)
+	return ret.Get(0).(State), ret.Bool(1)
+}
+
 func (flag *MockCancelFlag) Set(state State) {
 	flag.Called(state)
 }
 
+// SetWithReason mocks the method with the same name.
+func (flag *MockCancelFlag) SetWithReason(state State, reason CancelReason) {
+	flag.Called(state, reason)
+}
+
+// Reason mocks the method with the same name.
+func (flag *MockCancelFlag) Reason() CancelReason {
+	return flag.Called().Get(0).(CancelReason)
+}
+
 func (flag *MockCancelFlag) State() State {
 	return flag.Called().Get(0).(State)
 }
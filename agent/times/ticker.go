@@ -0,0 +1,100 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package times
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers ticks at intervals measured by a Clock.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on, it's closed once the ticker is stopped.
+	// Like with time.Ticker, ticks are dropped for receivers that can't keep up.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker, it can be called more than once.
+	Stop()
+}
+
+// NewTicker returns a ticker delivering the time of the given clock every interval of that clock.
+// The interval must be greater than zero, like with time.NewTicker.
+func NewTicker(clock Clock, interval time.Duration) Ticker {
+	if interval <= 0 {
+		panic("non-positive interval for times.NewTicker")
+	}
+	t := &clockTicker{c: make(chan time.Time, 1), stop: make(chan struct{})}
+	if _, isDefault := clock.(*defaultClock); isDefault {
+		go t.runOnTicker(interval)
+	} else {
+		go t.runOnClock(clock, interval)
+	}
+	return t
+}
+
+// clockTicker implements Ticker, its channel is closed by the goroutine delivering the ticks once it returns.
+type clockTicker struct {
+	c    chan time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+// C returns the channel the ticks are delivered on.
+func (t *clockTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop turns off the ticker.
+func (t *clockTicker) Stop() {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+}
+
+// runOnTicker delivers the ticks of a time.Ticker, which unlike After of the default clock doesn't leave a goroutine
+// behind once stopped.
+func (t *clockTicker) runOnTicker(interval time.Duration) {
+	defer close(t.c)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			t.deliver(now)
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// runOnClock delivers a tick whenever the given clock reports an interval elapsed.
+func (t *clockTicker) runOnClock(clock Clock, interval time.Duration) {
+	defer close(t.c)
+	for {
+		select {
+		case <-clock.After(interval):
+			t.deliver(clock.Now())
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// deliver sends the tick unless the previous one wasn't received yet.
+func (t *clockTicker) deliver(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package times
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveTick returns the next tick of the ticker, ok is false if none arrived in time or the ticker got stopped
+func receiveTick(ticker Ticker) (tick time.Time, ok bool) {
+	select {
+	case tick, ok = <-ticker.C():
+		return tick, ok
+	case <-time.After(5 * time.Second):
+		return time.Time{}, false
+	}
+}
+
+func TestTickerFiresWhenMockedClockAdvances(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewMockedClock()
+	clock.On("After", 15*time.Minute).Return(clock.AfterChannel)
+	clock.On("Now").Return(now)
+	ticker := NewTicker(clock, 15*time.Minute)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		assert.Fail(t, "ticker fired before the clock advanced")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	for i := 0; i < 2; i++ {
+		clock.AfterChannel <- struct{}{}
+		tick, ok := receiveTick(ticker)
+		assert.True(t, ok)
+		assert.Equal(t, now, tick)
+	}
+}
+
+func TestTickerClosesChannelOnceStopped(t *testing.T) {
+	clock := NewMockedClock()
+	clock.On("After", time.Minute).Return(clock.AfterChannel)
+	ticker := NewTicker(clock, time.Minute)
+
+	ticker.Stop()
+	ticker.Stop()
+
+	_, ok := receiveTick(ticker)
+	assert.False(t, ok)
+}
+
+func TestTickerOnDefaultClock(t *testing.T) {
+	ticker := NewTicker(DefaultClock, time.Millisecond)
+
+	_, ok := receiveTick(ticker)
+	assert.True(t, ok)
+
+	ticker.Stop()
+	for range ticker.C() {
+	}
+}
+
+func TestTickerRejectsNonPositiveInterval(t *testing.T) {
+	assert.Panics(t, func() { NewTicker(DefaultClock, 0) })
+}
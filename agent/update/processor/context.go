@@ -90,6 +90,7 @@ type UpdateDetail struct {
 	TargetVersion      string                 `json:"TargetVersion"`
 	TargetLocation     string                 `json:"TargetLocation"`
 	TargetHash         string                 `json:"TargetHash"`
+	HashType           string                 `json:"HashType"`
 	PackageName        string                 `json:"PackageName"`
 	StartDateTime      time.Time              `json:"StartDateTime"`
 	EndDateTime        time.Time              `json:"EndDateTime"`
@@ -99,6 +100,8 @@ type UpdateDetail struct {
 	ManifestPath       string                 `json:"ManifestPath"`
 	ManifestUrl        string                 `json:"ManifestUrl"`
 	SelfUpdate         bool                   `json:"SelfUpdate"`
+	DisableRollback    bool                   `json:"DisableRollback"`
+	Force              bool                   `json:"Force"`
 }
 
 // UpdateContext holds the book keeping details for Update context
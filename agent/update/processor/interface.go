@@ -41,6 +41,7 @@ type uninstall func(mgr *updateManager, log log.T, version string, context *Upda
 type install func(mgr *updateManager, log log.T, version string, context *UpdateContext) (exitCode updateutil.UpdateScriptExitCode, err error)
 type download func(mgr *updateManager, log log.T, downloadInput artifact.DownloadInput, context *UpdateContext, version string) (err error)
 type clean func(mgr *updateManager, log log.T, context *UpdateContext) (err error)
+type probeVersion func(log log.T) (version string, err error)
 
 type updateManager struct {
 	util      updateutil.T
@@ -54,7 +55,9 @@ type updateManager struct {
 	install   install
 	download  download
 	clean     clean
-	subStatus string // Values currently being used - downgrade, InstallRollback, VerificationRollback. It is good to place it here as UpdateContext is being saved on the filesystem
+	// probeVersion returns the version the installed agent reports
+	probeVersion probeVersion
+	subStatus    string // Values currently being used - downgrade, InstallRollback, VerificationRollback. It is good to place it here as UpdateContext is being saved on the filesystem
 }
 
 // Updater contains logic for performing agent update
@@ -18,6 +18,8 @@ package processor
 import (
 	"fmt"
 	"math/rand"
+	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
@@ -53,6 +55,8 @@ func NewUpdater() *Updater {
 			install:   installAgent,
 			download:  downloadAndUnzipArtifact,
 			clean:     cleanUninstalledVersions,
+			// probe the installed binary to verify the update
+			probeVersion: probeInstalledVersion,
 		},
 	}
 
@@ -149,6 +153,24 @@ func validateInactiveVersion(log log.T, detail *UpdateDetail) (err error) {
 	return nil
 }
 
+// isAlreadyAtTargetVersion compares the parsed source and target versions, falling back to
+// comparing the raw strings when either of them cannot be parsed. Missing trailing components
+// count as 0, so 3.0.1 is the same version as 3.0.1.0
+func isAlreadyAtTargetVersion(source string, target string) bool {
+	source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+	for strings.Count(source, ".") < strings.Count(target, ".") {
+		source += ".0"
+	}
+	for strings.Count(target, ".") < strings.Count(source, ".") {
+		target += ".0"
+	}
+	compareResult, err := updateutil.VersionCompare(source, target)
+	if err != nil {
+		return source == target
+	}
+	return compareResult == 0
+}
+
 // getMinimumVSupportedVersions returns a map of minimum supported version and it's platform
 func getMinimumVSupportedVersions() (versions *map[string]string) {
 	once.Do(func() {
@@ -171,6 +193,11 @@ func prepareInstallationPackages(mgr *updateManager, log log.T, context *UpdateC
 
 	updateDownload := ""
 
+	// Nothing to do when the agent is already at the target version
+	if !context.Current.Force && isAlreadyAtTargetVersion(context.Current.SourceVersion, context.Current.TargetVersion) {
+		return mgr.alreadyAtTarget(context, log)
+	}
+
 	if instanceContext, err = mgr.util.CreateInstanceContext(log); err != nil {
 		return mgr.failed(context, log, updateutil.ErrorEnvironmentIssue, err.Error(), false)
 	}
@@ -203,10 +230,8 @@ func prepareInstallationPackages(mgr *updateManager, log log.T, context *UpdateC
 
 	// Download source
 	downloadInput := artifact.DownloadInput{
-		SourceURL: context.Current.SourceLocation,
-		SourceChecksums: map[string]string{
-			updateutil.HashType: context.Current.SourceHash,
-		},
+		SourceURL:            context.Current.SourceLocation,
+		SourceChecksums:      checksums(context.Current, context.Current.SourceHash),
 		DestinationDirectory: updateDownload,
 	}
 	for retryCounter := 1; retryCounter <= updateOperationsRetryCount; retryCounter++ {
@@ -223,10 +248,8 @@ func prepareInstallationPackages(mgr *updateManager, log log.T, context *UpdateC
 
 	// Download target
 	downloadInput = artifact.DownloadInput{
-		SourceURL: context.Current.TargetLocation,
-		SourceChecksums: map[string]string{
-			updateutil.HashType: context.Current.TargetHash,
-		},
+		SourceURL:            context.Current.TargetLocation,
+		SourceChecksums:      checksums(context.Current, context.Current.TargetHash),
 		DestinationDirectory: updateDownload,
 	}
 
@@ -292,6 +315,10 @@ func proceedUpdate(mgr *updateManager, log log.T, context *UpdateContext) (err e
 		if exitCode == updateutil.ExitCodeUnsupportedPlatform {
 			return mgr.failed(context, log, updateutil.ErrorUnsupportedServiceManager, message, true)
 		}
+		if context.Current.DisableRollback {
+			context.Current.AppendInfo(log, "Rollback is disabled, keeping %v %v", context.Current.PackageName, context.Current.TargetVersion)
+			return mgr.failed(context, log, updateutil.ErrorInstallFailed, message, false)
+		}
 
 		context.Current.AppendInfo(
 			log,
@@ -340,6 +367,10 @@ func verifyInstallation(mgr *updateManager, log log.T, context *UpdateContext, i
 				"failed to start the agent")
 
 			context.Current.AppendError(log, message)
+			if context.Current.DisableRollback {
+				context.Current.AppendInfo(log, "Rollback is disabled, keeping %v %v", context.Current.PackageName, context.Current.TargetVersion)
+				return mgr.failed(context, log, updateutil.ErrorCannotStartService, message, false)
+			}
 			context.Current.AppendInfo(
 				log,
 				"Initiating rollback %v to %v",
@@ -364,6 +395,9 @@ func verifyInstallation(mgr *updateManager, log log.T, context *UpdateContext, i
 
 	log.Infof("%v is running", context.Current.PackageName)
 	if !isRollback {
+		if err = verifyInstalledVersion(mgr, log, context); err != nil {
+			return mgr.failed(context, log, updateutil.ErrorInstalledVersionMismatch, err.Error(), false)
+		}
 		return mgr.succeeded(context, log)
 	}
 
@@ -372,6 +406,35 @@ func verifyInstallation(mgr *updateManager, log log.T, context *UpdateContext, i
 	return mgr.failed(context, log, updateutil.ErrorUpdateFailRollbackSuccess, message, false)
 }
 
+// verifyInstalledVersion returns an error if the installed agent reports another version than the target version.
+// If the version can't be probed the installation is assumed to be fine, the agent is running at this point.
+func verifyInstalledVersion(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
+	installedVersion, err := mgr.probeVersion(log)
+	if err != nil {
+		log.Warnf("Failed to probe the installed version of %v, skipping the version check: %v", context.Current.PackageName, err)
+		return nil
+	}
+	if !isAlreadyAtTargetVersion(installedVersion, context.Current.TargetVersion) {
+		return fmt.Errorf("failed to update %v to %v, the installed agent reports version %v",
+			context.Current.PackageName,
+			context.Current.TargetVersion,
+			installedVersion)
+	}
+	context.Current.AppendInfo(log, "Verified that %v reports version %v", context.Current.PackageName, installedVersion)
+	return nil
+}
+
+// probeInstalledVersion runs the installed agent binary to get the version it reports
+func probeInstalledVersion(log log.T) (version string, err error) {
+	output, err := exec.Command(appconfig.DefaultSSMAgentBinaryPath, "-version").Output()
+	if err != nil {
+		return "", err
+	}
+	// the version is the last line, the agent may have logged to the console before
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
 // rollbackInstallation rollback installation to the source version
 func rollbackInstallation(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
 	if exitCode, err := mgr.uninstall(mgr, log, context.Current.TargetVersion, context); err != nil {
@@ -560,6 +623,15 @@ func cleanUninstalledVersions(mgr *updateManager, log log.T, context *UpdateCont
 	return nil
 }
 
+// checksums returns the checksums to verify a download with, the hash is sha256 unless the update requested otherwise
+func checksums(detail *UpdateDetail, hash string) map[string]string {
+	hashType := detail.HashType
+	if hashType == "" {
+		hashType = updateutil.HashType
+	}
+	return map[string]string{hashType: hash}
+}
+
 // downloadAndUnzipArtifact downloads installation package and unzips it
 func downloadAndUnzipArtifact(
 	mgr *updateManager,
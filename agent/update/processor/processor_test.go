@@ -154,6 +154,99 @@ func TestPrepareInstallationPackages(t *testing.T) {
 	assert.True(t, isUpdateCalled)
 }
 
+func TestPrepareInstallationPackagesVerifiesRequestedHashType(t *testing.T) {
+	// setup
+	updater := createDefaultUpdaterStub()
+	context := createUpdateContext(Initialized)
+	context.Current.SourceHash = "sourceHash"
+	context.Current.TargetHash = "targetHash"
+	context.Current.HashType = "md5"
+	var checksums []map[string]string
+
+	updater.mgr.download = func(mgr *updateManager, log log.T, downloadInput artifact.DownloadInput, context *UpdateContext, version string) (err error) {
+		checksums = append(checksums, downloadInput.SourceChecksums)
+		return nil
+	}
+	updater.mgr.update = func(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
+		return nil
+	}
+	versioncheck = func(log log.T, manifestFilePath string, version string) bool {
+		return true
+	}
+	// action
+	err := prepareInstallationPackages(updater.mgr, logger, context)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"md5": "sourceHash"}, {"md5": "targetHash"}}, checksums)
+}
+
+func TestPrepareInstallationPackagesReportsAlreadyAtTargetVersion(t *testing.T) {
+	// setup
+	updater := createDefaultUpdaterStub()
+	context := createUpdateContext(Initialized)
+	context.Current.PackageName = "amazon-ssm-agent"
+	context.Current.TargetVersion = context.Current.SourceVersion
+	isDownloadCalled := false
+
+	updater.mgr.download = func(mgr *updateManager, log log.T, downloadInput artifact.DownloadInput, context *UpdateContext, version string) (err error) {
+		isDownloadCalled = true
+		return nil
+	}
+
+	// action
+	err := prepareInstallationPackages(updater.mgr, logger, context)
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, isDownloadCalled)
+	assert.Equal(t, context.Histories[0].State, Completed)
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusSuccess)
+	assert.True(t, strings.Contains(updater.mgr.ctxMgr.(*contextMgrStub).tempStdOut, "amazon-ssm-agent is already at version 5.0.0.0, update skipped"))
+}
+
+func TestPrepareInstallationPackagesForcesAlreadyAtTargetVersion(t *testing.T) {
+	// setup
+	updater := createDefaultUpdaterStub()
+	context := createUpdateContext(Initialized)
+	context.Current.TargetVersion = context.Current.SourceVersion
+	context.Current.Force = true
+	isUpdateCalled := false
+
+	updater.mgr.download = func(mgr *updateManager, log log.T, downloadInput artifact.DownloadInput, context *UpdateContext, version string) (err error) {
+		return nil
+	}
+	updater.mgr.update = func(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
+		isUpdateCalled = true
+		return nil
+	}
+	versioncheck = func(log log.T, manifestFilePath string, version string) bool {
+		return true
+	}
+
+	// action
+	err := prepareInstallationPackages(updater.mgr, logger, context)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, context.Current.State, Staged)
+	assert.True(t, isUpdateCalled)
+}
+
+func TestIsAlreadyAtTargetVersion(t *testing.T) {
+	assert.True(t, isAlreadyAtTargetVersion("2.3.50.0", "2.3.50.0"))
+	assert.True(t, isAlreadyAtTargetVersion(" 2.3.50.0", "2.3.50.0"))
+	assert.False(t, isAlreadyAtTargetVersion("2.3.50.0", "2.3.51.0"))
+	assert.False(t, isAlreadyAtTargetVersion("invalid", "2.3.50.0"))
+	assert.True(t, isAlreadyAtTargetVersion("3.0.1", "3.0.1.0"))
+	assert.True(t, isAlreadyAtTargetVersion("3.0.1.0", "3.0.1"))
+	assert.False(t, isAlreadyAtTargetVersion("3.0.1", "3.0.1.1"))
+}
+
+func TestChecksumsDefaultToSha256(t *testing.T) {
+	assert.Equal(t, map[string]string{"sha256": "hash"}, checksums(&UpdateDetail{}, "hash"))
+}
+
 func TestPreparePackagesFailCreateInstanceContext(t *testing.T) {
 	// setup
 	control := &stubControl{failCreateInstanceContext: true}
@@ -573,6 +666,60 @@ func TestVerifyInstallation(t *testing.T) {
 	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusSuccess)
 }
 
+func TestVerifyInstallationConfirmsInstalledVersion(t *testing.T) {
+	// setup
+	control := &stubControl{serviceIsRunning: true}
+	updater := createUpdaterStubs(control)
+	context := createUpdateContext(Installed)
+	control.installedVersion = context.Current.TargetVersion
+
+	// action
+	err := verifyInstallation(updater.mgr, logger, context, false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, context.Histories[0].State, Completed)
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusSuccess)
+	assert.Contains(t, updater.mgr.ctxMgr.(*contextMgrStub).tempStdOut, "reports version "+context.Histories[0].TargetVersion)
+}
+
+func TestVerifyInstallationConfirmsEquivalentInstalledVersion(t *testing.T) {
+	// setup
+	control := &stubControl{serviceIsRunning: true, installedVersion: "6.0"}
+	updater := createUpdaterStubs(control)
+	context := createUpdateContext(Installed)
+
+	// action
+	err := verifyInstallation(updater.mgr, logger, context, false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, context.Histories[0].State, Completed)
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusSuccess)
+}
+
+func TestVerifyInstallationFailsWhenInstalledVersionMismatches(t *testing.T) {
+	// setup
+	control := &stubControl{serviceIsRunning: true, installedVersion: "1.0.0.0"}
+	updater := createUpdaterStubs(control)
+	context := createUpdateContext(Installed)
+	isRollbackCalled := false
+	updater.mgr.rollback = func(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
+		isRollbackCalled = true
+		return nil
+	}
+
+	// action
+	err := verifyInstallation(updater.mgr, logger, context, false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, isRollbackCalled)
+	assert.Equal(t, context.Histories[0].State, Completed)
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusFailed)
+	assert.Contains(t, updater.mgr.ctxMgr.(*contextMgrStub).tempStdOut, "the installed agent reports version 1.0.0.0")
+}
+
 func TestVerifyInstallationFailedGetInstanceContext(t *testing.T) {
 	// setup
 	control := &stubControl{failCreateInstanceContext: true}
@@ -612,6 +759,61 @@ func TestVerifyInstallationCannotStartAgent(t *testing.T) {
 	assert.Equal(t, context.Current.State, Rollback)
 }
 
+func TestVerifyInstallationRestoresSourceVersion(t *testing.T) {
+	// setup
+	control := &stubControl{serviceIsRunning: false}
+	updater := createUpdaterStubs(control)
+	updater.mgr.rollback = rollbackInstallation
+	updater.mgr.verify = verifyInstallation
+	context := createUpdateContext(Installed)
+	var installed, uninstalled []string
+
+	updater.mgr.uninstall = func(mgr *updateManager, log log.T, version string, context *UpdateContext) (exitCode updateutil.UpdateScriptExitCode, err error) {
+		uninstalled = append(uninstalled, version)
+		return exitCode, nil
+	}
+	updater.mgr.install = func(mgr *updateManager, log log.T, version string, context *UpdateContext) (exitCode updateutil.UpdateScriptExitCode, err error) {
+		installed = append(installed, version)
+		// the source version starts fine again
+		control.serviceIsRunning = true
+		return exitCode, nil
+	}
+
+	// action
+	err := verifyInstallation(updater.mgr, logger, context, false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{context.Histories[0].TargetVersion}, uninstalled)
+	assert.Equal(t, []string{context.Histories[0].SourceVersion}, installed)
+	assert.Equal(t, context.Histories[0].SourceVersion, control.getWaitForServiceVersion())
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusFailed)
+	assert.True(t, strings.Contains(updater.mgr.ctxMgr.(*contextMgrStub).tempStdOut, "rolledback"))
+}
+
+func TestVerifyInstallationCannotStartAgentWithRollbackDisabled(t *testing.T) {
+	// setup
+	control := &stubControl{serviceIsRunning: false}
+	updater := createUpdaterStubs(control)
+	context := createUpdateContext(Installed)
+	context.Current.DisableRollback = true
+	isRollbackCalled := false
+
+	updater.mgr.rollback = func(mgr *updateManager, log log.T, context *UpdateContext) (err error) {
+		isRollbackCalled = true
+		return nil
+	}
+
+	// action
+	err := verifyInstallation(updater.mgr, logger, context, false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, isRollbackCalled)
+	assert.Equal(t, context.Histories[0].State, Completed)
+	assert.Equal(t, context.Histories[0].Result, contracts.ResultStatusFailed)
+}
+
 func TestVerifyRollback(t *testing.T) {
 	// setup
 	control := &stubControl{serviceIsRunning: true}
@@ -853,6 +1055,12 @@ func createUpdaterStubs(control *stubControl) *Updater {
 	updater.mgr.svc = &serviceStub{}
 	updater.mgr.util = &utilityStub{controller: control}
 	updater.mgr.ctxMgr = &contextMgrStub{}
+	updater.mgr.probeVersion = func(log log.T) (string, error) {
+		if control.installedVersion == "" {
+			return "", fmt.Errorf("no installed version")
+		}
+		return control.installedVersion, nil
+	}
 
 	return updater
 }
@@ -863,6 +1071,7 @@ type stubControl struct {
 	serviceIsRunning               bool
 	failExeCommand                 bool
 	waitForServiceVersion          string
+	installedVersion               string
 }
 
 func (s *stubControl) getWaitForServiceVersion() string {
@@ -126,6 +126,24 @@ func (u *updateManager) inactive(context *UpdateContext, log logPkg.T, errorWarn
 	return u.finalizeUpdateAndSendReply(log, context, errorWarnCode)
 }
 
+// alreadyAtTarget completes the update without touching the installation when the agent is already at the target version
+func (u *updateManager) alreadyAtTarget(context *UpdateContext, log logPkg.T) (err error) {
+	update := context.Current
+	update.State = Completed
+	update.Result = contracts.ResultStatusSuccess
+	update.AppendInfo(
+		log,
+		"%v is already at version %v, update skipped",
+		update.PackageName,
+		update.TargetVersion)
+	errorWarnCode := u.subStatus + updateutil.WarnAlreadyAtTargetVersion
+	log.WriteEvent(
+		logPkg.AgentUpdateResultMessage,
+		update.SourceVersion,
+		PrepareHealthStatus(update, errorWarnCode, update.TargetVersion))
+	return u.finalizeUpdateAndSendReply(log, context, errorWarnCode)
+}
+
 // finalizeUpdateAndSendReply completes the update and sends reply to message service, also uploads to S3 (if any)
 func (u *updateManager) finalizeUpdateAndSendReply(log logPkg.T, context *UpdateContext, errorCode string) (err error) {
 	update := context.Current
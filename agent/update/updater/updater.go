@@ -52,6 +52,7 @@ var (
 	targetVersion   *string
 	targetLocation  *string
 	targetHash      *string
+	hashType        *string
 	packageName     *string
 	messageID       *string
 	stdout          *string
@@ -61,6 +62,9 @@ var (
 	manifestURL     *string
 	manifestPath    *string
 	selfUpdate      *bool
+	validateVersion *string
+	rollback        *bool
+	force           *bool
 )
 
 func init() {
@@ -79,6 +83,7 @@ func init() {
 	targetVersion = flag.String(updateutil.TargetVersionCmd, "", "target Agent Version")
 	targetLocation = flag.String(updateutil.TargetLocationCmd, "", "target Agent installer source")
 	targetHash = flag.String(updateutil.TargetHashCmd, "", "target Agent installer hash")
+	hashType = flag.String(updateutil.HashTypeCmd, updateutil.HashType, "hash algorithm of the Agent installer hashes")
 	packageName = flag.String(updateutil.PackageNameCmd, "", "target Agent Version")
 	messageID = flag.String(updateutil.MessageIDCmd, "", "target Agent Version")
 	stdout = flag.String(updateutil.StdoutFileName, "", "standard output file path")
@@ -91,6 +96,9 @@ func init() {
 	manifestPath = &manifestLocation
 
 	selfUpdate = flag.Bool(updateutil.SelfUpdateCmd, false, "SelfUpdate command")
+	rollback = flag.Bool(updateutil.RollbackOnFailureCmd, true, "restore the current Agent Version when the update fails")
+	validateVersion = flag.String(updateutil.ValidateVersionCmd, "", "version range the target Agent Version has to fall within")
+	force = flag.Bool(updateutil.ForceCmd, false, "update even when the target Agent Version is already installed")
 
 }
 
@@ -145,11 +153,6 @@ func main() {
 			*sourceVersion,
 			updateutil.GenerateSelUpdateSuccessEvent(string(updateutil.Stage))) // UpdateSucceeded_SelfUpdate_Stage
 
-		if *targetVersion == *sourceVersion {
-			log.Infof("Current version %v is not deprecated, skipping self update", *sourceVersion)
-			return
-		}
-
 		*stdout = defaultStdoutFileName
 		*stderr = defaultStderrFileName
 		*packageName = defaultSSMAgentName
@@ -198,6 +201,7 @@ func main() {
 		TargetVersion:      *targetVersion,
 		TargetLocation:     *targetLocation,
 		TargetHash:         *targetHash,
+		HashType:           *hashType,
 		StdoutFileName:     *stdout,
 		StderrFileName:     *stderr,
 		OutputS3KeyPrefix:  *outputKeyPrefix,
@@ -209,6 +213,8 @@ func main() {
 		ManifestUrl:        *manifestURL,
 		ManifestPath:       *manifestPath,
 		SelfUpdate:         *selfUpdate,
+		DisableRollback:    !*rollback,
+		Force:              *force,
 	}
 
 	if err = resolveUpdateDetail(detail); err != nil {
@@ -228,6 +234,12 @@ func main() {
 	// Recover updater if panic occurs and fail the updater
 	defer recoverUpdaterFromPanic(context)
 
+	// Make sure the target version is acceptable before touching the installation
+	if err = validateTargetVersion(*targetVersion, *validateVersion); err != nil {
+		updater.Failed(context, log, updateutil.ErrorInvalidTargetVersion, err.Error(), true)
+		return
+	}
+
 	// Start or resume update
 	if err = updater.StartOrResumeUpdate(log, context); err != nil { // We do not send any error above this to ICS/MGS except panic message
 		// Rolled back, but service cannot start, Update failed.
@@ -256,6 +268,21 @@ func resolveUpdateDetail(detail *processor.UpdateDetail) error {
 	return nil
 }
 
+// validateTargetVersion checks that the target version falls within the given version range, if any
+func validateTargetVersion(targetVersion string, versionRange string) error {
+	if len(versionRange) == 0 {
+		return nil
+	}
+	inRange, err := updateutil.IsVersionInRange(targetVersion, versionRange)
+	if err != nil {
+		return fmt.Errorf("failed to validate target version %v, %v", targetVersion, err)
+	}
+	if !inRange {
+		return fmt.Errorf("target version %v is not within the allowed version range %v", targetVersion, versionRange)
+	}
+	return nil
+}
+
 // recoverUpdaterFromPanic recovers updater if panic occurs and fails the updater
 func recoverUpdaterFromPanic(context *processor.UpdateContext) {
 	// recover in case the updater panics
@@ -39,9 +39,14 @@ func regionFailedStub() (string, error) {
 type stubUpdater struct {
 	returnUpdateError  bool
 	returnCleanupError bool
+	initialized        bool
+	updated            bool
+	detail             *processor.UpdateDetail
+	failedCode         updateutil.ErrorCode
 }
 
 func (u *stubUpdater) StartOrResumeUpdate(log logger.T, context *processor.UpdateContext) (err error) {
+	u.updated = true
 	if u.returnUpdateError {
 		return fmt.Errorf("Fail update")
 	}
@@ -49,6 +54,8 @@ func (u *stubUpdater) StartOrResumeUpdate(log logger.T, context *processor.Updat
 }
 
 func (u *stubUpdater) InitializeUpdate(log logger.T, detail *processor.UpdateDetail) (context *processor.UpdateContext, err error) {
+	u.initialized = true
+	u.detail = detail
 	context = &processor.UpdateContext{}
 	context.Current = &processor.UpdateDetail{}
 	context.Current.StandardOut = "output message"
@@ -69,6 +76,7 @@ func (u *stubUpdater) Failed(
 	code updateutil.ErrorCode,
 	errMessage string,
 	noRollbackMessage bool) (err error) {
+	u.failedCode = code
 	return nil
 }
 
@@ -156,3 +164,80 @@ func TestCleanupFailed(t *testing.T) {
 	main()
 
 }
+
+func TestUpdaterLeavesAlreadyAtTargetVersionToTheProcessor(t *testing.T) {
+	// setup
+	log = logger.NewMockLog()
+	region = regionStub
+	stub := &stubUpdater{}
+	updater = stub
+
+	os.Args = []string{"updater", "-update", "-source.version", "5.0.0.0", "-source.location", "http://source",
+		"-target.version", "5.0.0.0", "-target.location", "http://target"}
+
+	// action
+	main()
+
+	// assert
+	assert.True(t, stub.initialized)
+	assert.False(t, stub.detail.Force)
+}
+
+func TestUpdaterForcesUpdateWhenAlreadyAtTargetVersion(t *testing.T) {
+	// setup
+	log = logger.NewMockLog()
+	region = regionStub
+	stub := &stubUpdater{}
+	updater = stub
+
+	os.Args = []string{"updater", "-update", "-force", "-source.version", "5.0.0.0", "-source.location", "http://source",
+		"-target.version", "5.0.0.0", "-target.location", "http://target"}
+
+	// action
+	main()
+
+	// assert
+	assert.True(t, stub.initialized)
+	assert.True(t, stub.detail.Force)
+
+	*force = false
+}
+
+func TestUpdaterFailsTargetVersionOutOfRange(t *testing.T) {
+	// setup
+	log = logger.NewMockLog()
+	region = regionStub
+	stub := &stubUpdater{}
+	updater = stub
+
+	os.Args = append(updateCommand, "-"+updateutil.ValidateVersionCmd, ">=3.1.0,<3.2.0")
+
+	// action
+	main()
+
+	// assert
+	assert.True(t, stub.initialized)
+	assert.False(t, stub.updated)
+	assert.Equal(t, updateutil.ErrorInvalidTargetVersion, stub.failedCode)
+
+	*validateVersion = ""
+}
+
+func TestUpdaterUpdatesTargetVersionInRange(t *testing.T) {
+	// setup
+	log = logger.NewMockLog()
+	region = regionStub
+	stub := &stubUpdater{}
+	updater = stub
+
+	os.Args = append(updateCommand, "-"+updateutil.ValidateVersionCmd, "5.0.x")
+
+	// action
+	main()
+
+	// assert
+	assert.True(t, stub.updated)
+	assert.Equal(t, updateutil.ErrorCode(""), stub.failedCode)
+
+	*validateVersion = ""
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updateutil contains updater specific utilities.
+package updateutil
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/aws/amazon-ssm-agent/agent/appconfig"
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+)
+
+// manifestPublicKeyPath returns the path of the public key the manifest signature is verified with.
+// Assign method to global variable to allow unittest to override
+var manifestPublicKeyPath = ManifestPublicKeyPath
+
+// ManifestPublicKeyPath returns the path of the public key bundled with the agent to verify manifest signatures
+func ManifestPublicKeyPath() string {
+	return filepath.Join(appconfig.DefaultProgramFolder, ManifestPublicKeyFileName)
+}
+
+// verifyDownloadedManifest verifies the downloaded manifest against the signature published next to it, a manifest
+// that can't be verified is never used
+func verifyDownloadedManifest(log log.T, updateDownloadFolder, manifestUrl, manifestFilePath string) (err error) {
+	signatureInput := artifact.DownloadInput{
+		SourceURL:            manifestUrl + ManifestSignatureSuffix,
+		DestinationDirectory: updateDownloadFolder,
+	}
+	signatureOutput, err := downloadArtifact(log, signatureInput)
+	if err != nil || signatureOutput.LocalFilePath == "" {
+		return fmt.Errorf("failed to download manifest signature, %v, %v", signatureInput.SourceURL, err)
+	}
+	if err = VerifyManifestSignature(manifestFilePath, signatureOutput.LocalFilePath, manifestPublicKeyPath()); err != nil {
+		return err
+	}
+	log.Infof("Verified the manifest signature")
+	return nil
+}
+
+// VerifyManifestSignature verifies the manifest against its detached RSA SHA-256 signature with the PEM encoded
+// public key. It fails if the signature or the key can't be read, so that an unsigned manifest is never trusted.
+func VerifyManifestSignature(manifestFilePath, signatureFilePath, publicKeyFilePath string) (err error) {
+	var manifest, signature []byte
+	var publicKey *rsa.PublicKey
+
+	if publicKey, err = loadManifestPublicKey(publicKeyFilePath); err != nil {
+		return err
+	}
+	if manifest, err = ioutil.ReadFile(manifestFilePath); err != nil {
+		return fmt.Errorf("failed to read manifest %v, %v", manifestFilePath, err)
+	}
+	if signature, err = ioutil.ReadFile(signatureFilePath); err != nil {
+		return fmt.Errorf("failed to read manifest signature %v, %v", signatureFilePath, err)
+	}
+
+	digest := sha256.Sum256(manifest)
+	if err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("manifest signature %v is invalid, %v", signatureFilePath, err)
+	}
+	return nil
+}
+
+// loadManifestPublicKey reads the PEM encoded RSA public key the manifest signature is verified with
+func loadManifestPublicKey(publicKeyFilePath string) (*rsa.PublicKey, error) {
+	content, err := ioutil.ReadFile(publicKeyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest public key %v, %v", publicKeyFilePath, err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("manifest public key %v is not PEM encoded", publicKeyFilePath)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest public key %v, %v", publicKeyFilePath, err)
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("manifest public key %v is not an RSA key", publicKeyFilePath)
+	}
+	return publicKey, nil
+}
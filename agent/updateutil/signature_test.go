@@ -0,0 +1,165 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package updateutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/fileutil/artifact"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `{"SchemaVersion":"1.0","URIFormat":"https://s3.{Region}.amazonaws.com/amazon-ssm-{Region}/{PackageName}/{PackageVersion}/{FileName}"}`
+
+// signedManifest writes a manifest, its signature and the public key to verify it with to a temporary directory
+func signedManifest(t *testing.T) (dir, manifestPath, signaturePath, publicKeyPath string) {
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.NoError(t, err)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte(testManifest))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+
+	manifestPath = filepath.Join(dir, "ssm-agent-manifest.json")
+	signaturePath = manifestPath + ManifestSignatureSuffix
+	publicKeyPath = filepath.Join(dir, ManifestPublicKeyFileName)
+	assert.NoError(t, ioutil.WriteFile(manifestPath, []byte(testManifest), 0600))
+	assert.NoError(t, ioutil.WriteFile(signaturePath, signature, 0600))
+	assert.NoError(t, ioutil.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0600))
+	return
+}
+
+func TestVerifyManifestSignature(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, VerifyManifestSignature(manifestPath, signaturePath, publicKeyPath))
+}
+
+func TestVerifyManifestSignatureFailsForTamperedManifest(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	tampered := []byte(testManifest)
+	tampered[len(tampered)-3] = 'X'
+	assert.NoError(t, ioutil.WriteFile(manifestPath, tampered, 0600))
+
+	err := VerifyManifestSignature(manifestPath, signaturePath, publicKeyPath)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid")
+}
+
+func TestVerifyManifestSignatureFailsForMissingSignature(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Remove(signaturePath))
+
+	assert.Error(t, VerifyManifestSignature(manifestPath, signaturePath, publicKeyPath))
+}
+
+func TestVerifyManifestSignatureFailsForMissingPublicKey(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Remove(publicKeyPath))
+
+	assert.Error(t, VerifyManifestSignature(manifestPath, signaturePath, publicKeyPath))
+}
+
+func TestVerifyManifestSignatureFailsForMalformedPublicKey(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(publicKeyPath, []byte("not a key"), 0600))
+
+	assert.Error(t, VerifyManifestSignature(manifestPath, signaturePath, publicKeyPath))
+}
+
+// stubManifestDownload serves the manifest and its signature from the given local files and verifies them with the
+// given public key, the returned function restores the stubbed dependencies
+func stubManifestDownload(manifestPath, signaturePath, publicKeyPath string) func() {
+	originalDownload, originalKeyPath := downloadArtifact, manifestPublicKeyPath
+	downloadArtifact = func(log log.T, input artifact.DownloadInput) (artifact.DownloadOutput, error) {
+		localPath := manifestPath
+		if strings.HasSuffix(input.SourceURL, ManifestSignatureSuffix) {
+			localPath = signaturePath
+		}
+		if _, err := os.Stat(localPath); err != nil {
+			return artifact.DownloadOutput{}, err
+		}
+		return artifact.DownloadOutput{LocalFilePath: localPath, IsUpdated: true, IsHashMatched: true}, nil
+	}
+	manifestPublicKeyPath = func() string { return publicKeyPath }
+	return func() {
+		downloadArtifact, manifestPublicKeyPath = originalDownload, originalKeyPath
+	}
+}
+
+func TestDownloadManifestFileVerifiesSignature(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	defer stubManifestDownload(manifestPath, signaturePath, publicKeyPath)()
+	util := &Utility{}
+
+	output, url, err := util.DownloadManifestFile(log.NewMockLog(), dir, "https://example.com/ssm-agent-manifest.json", "us-east-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, manifestPath, output.LocalFilePath)
+	assert.Equal(t, "https://example.com/ssm-agent-manifest.json", url)
+}
+
+func TestDownloadManifestFileFailsForMissingSignature(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	defer stubManifestDownload(manifestPath, signaturePath, publicKeyPath)()
+	util := &Utility{}
+	assert.NoError(t, os.Remove(signaturePath))
+
+	_, _, err := util.DownloadManifestFile(log.NewMockLog(), dir, "https://example.com/ssm-agent-manifest.json", "us-east-1")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest signature")
+}
+
+func TestDownloadManifestFileFailsForMissingPublicKey(t *testing.T) {
+	dir, manifestPath, signaturePath, publicKeyPath := signedManifest(t)
+	defer os.RemoveAll(dir)
+	defer stubManifestDownload(manifestPath, signaturePath, publicKeyPath)()
+	util := &Utility{}
+	assert.NoError(t, os.Remove(publicKeyPath))
+
+	_, _, err := util.DownloadManifestFile(log.NewMockLog(), dir, "https://example.com/ssm-agent-manifest.json", "us-east-1")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest public key")
+}
+
+func TestPackagedManifestPublicKeyIsValid(t *testing.T) {
+	_, err := loadManifestPublicKey(filepath.Join("..", "..", "packaging", "keys", ManifestPublicKeyFileName))
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package updateutil contains updater specific utilities.
+package updateutil
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"strconv"
+)
+
+// UpdateArgs holds the command line arguments of the agent updater
+type UpdateArgs struct {
+	Update            bool
+	SelfUpdate        bool
+	SourceVersion     string
+	SourceLocation    string
+	SourceHash        string
+	TargetVersion     string
+	TargetLocation    string
+	TargetHash        string
+	HashType          string
+	PackageName       string
+	MessageID         string
+	StdoutFileName    string
+	StderrFileName    string
+	OutputKeyPrefix   string
+	OutputBucketName  string
+	ManifestURL       string
+	ValidateVersion   string
+	RollbackOnFailure bool
+	Force             bool
+}
+
+// NewUpdateArgs returns the updater arguments with their default values
+func NewUpdateArgs() UpdateArgs {
+	return UpdateArgs{
+		HashType:          HashType,
+		RollbackOnFailure: true,
+	}
+}
+
+// ParseUpdateArgs parses the updater command line, without the program name, and validates
+// that the arguments required by the requested update are present
+func ParseUpdateArgs(args []string) (UpdateArgs, error) {
+	parsed := NewUpdateArgs()
+
+	flags := flag.NewFlagSet("updater", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.BoolVar(&parsed.Update, UpdateCmd, parsed.Update, "")
+	flags.BoolVar(&parsed.SelfUpdate, SelfUpdateCmd, parsed.SelfUpdate, "")
+	flags.StringVar(&parsed.SourceVersion, SourceVersionCmd, parsed.SourceVersion, "")
+	flags.StringVar(&parsed.SourceLocation, SourceLocationCmd, parsed.SourceLocation, "")
+	flags.StringVar(&parsed.SourceHash, SourceHashCmd, parsed.SourceHash, "")
+	flags.StringVar(&parsed.TargetVersion, TargetVersionCmd, parsed.TargetVersion, "")
+	flags.StringVar(&parsed.TargetLocation, TargetLocationCmd, parsed.TargetLocation, "")
+	flags.StringVar(&parsed.TargetHash, TargetHashCmd, parsed.TargetHash, "")
+	flags.StringVar(&parsed.HashType, HashTypeCmd, parsed.HashType, "")
+	flags.StringVar(&parsed.PackageName, PackageNameCmd, parsed.PackageName, "")
+	flags.StringVar(&parsed.MessageID, MessageIDCmd, parsed.MessageID, "")
+	flags.StringVar(&parsed.StdoutFileName, StdoutFileName, parsed.StdoutFileName, "")
+	flags.StringVar(&parsed.StderrFileName, StderrFileName, parsed.StderrFileName, "")
+	flags.StringVar(&parsed.OutputKeyPrefix, OutputKeyPrefixCmd, parsed.OutputKeyPrefix, "")
+	flags.StringVar(&parsed.OutputBucketName, OutputBucketNameCmd, parsed.OutputBucketName, "")
+	flags.StringVar(&parsed.ManifestURL, ManifestFileUrlCmd, parsed.ManifestURL, "")
+	flags.StringVar(&parsed.ValidateVersion, ValidateVersionCmd, parsed.ValidateVersion, "")
+	flags.BoolVar(&parsed.RollbackOnFailure, RollbackOnFailureCmd, parsed.RollbackOnFailure, "")
+	flags.BoolVar(&parsed.Force, ForceCmd, parsed.Force, "")
+
+	if err := flags.Parse(args); err != nil {
+		return parsed, err
+	}
+	return parsed, parsed.Validate()
+}
+
+// Validate makes sure the arguments required by the requested update are present,
+// self update resolves the versions and locations from the manifest
+func (args UpdateArgs) Validate() error {
+	if !args.Update && !args.SelfUpdate {
+		return errors.New("incorrect usage (use -" + UpdateCmd + ")")
+	}
+	if args.SelfUpdate {
+		if args.ManifestURL == "" {
+			return errors.New("no manifest url for self update")
+		}
+		return nil
+	}
+	if args.SourceVersion == "" || args.SourceLocation == "" {
+		return errors.New("no current version or package source")
+	}
+	if args.TargetVersion == "" || args.TargetLocation == "" {
+		return errors.New("no target version or package source")
+	}
+	return nil
+}
+
+// ToArgs builds the updater command line, without the program name, omitting empty and default values
+func (args UpdateArgs) ToArgs() []string {
+	var result []string
+	addBool := func(name string, value bool, defaultValue bool) {
+		if value == defaultValue {
+			return
+		}
+		if value {
+			result = append(result, "-"+name)
+		} else {
+			result = append(result, "-"+name+"="+strconv.FormatBool(value))
+		}
+	}
+	addString := func(name string, value string) {
+		if value != "" {
+			result = append(result, "-"+name, value)
+		}
+	}
+
+	addBool(UpdateCmd, args.Update, false)
+	addBool(SelfUpdateCmd, args.SelfUpdate, false)
+	addString(SourceVersionCmd, args.SourceVersion)
+	addString(SourceLocationCmd, args.SourceLocation)
+	addString(SourceHashCmd, args.SourceHash)
+	addString(TargetVersionCmd, args.TargetVersion)
+	addString(TargetLocationCmd, args.TargetLocation)
+	addString(TargetHashCmd, args.TargetHash)
+	if args.HashType != HashType {
+		addString(HashTypeCmd, args.HashType)
+	}
+	addString(PackageNameCmd, args.PackageName)
+	addString(MessageIDCmd, args.MessageID)
+	addString(StdoutFileName, args.StdoutFileName)
+	addString(StderrFileName, args.StderrFileName)
+	addString(OutputKeyPrefixCmd, args.OutputKeyPrefix)
+	addString(OutputBucketNameCmd, args.OutputBucketName)
+	addString(ManifestFileUrlCmd, args.ManifestURL)
+	addString(ValidateVersionCmd, args.ValidateVersion)
+	addBool(RollbackOnFailureCmd, args.RollbackOnFailure, true)
+	addBool(ForceCmd, args.Force, false)
+	return result
+}
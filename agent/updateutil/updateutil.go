@@ -151,6 +151,12 @@ const (
 
 	// CommonManifestURL is the Manifest URL for regular regions
 	CommonManifestURL = "https://s3.{Region}.amazonaws.com" + ManifestPath
+
+	// ManifestSignatureSuffix is appended to the manifest url to get the url of the manifest signature
+	ManifestSignatureSuffix = ".sig"
+
+	// ManifestPublicKeyFileName is the name of the public key the manifest signature is verified with
+	ManifestPublicKeyFileName = "ssm-agent-manifest-public-key.pem"
 )
 
 // error status codes returned from the update scripts
@@ -276,6 +282,9 @@ const (
 	// ErrorLoadingAgentVersion represents failed for loading agent version
 	ErrorLoadingAgentVersion ErrorCode = "ErrorLoadingAgentVersion"
 
+	// ErrorInstalledVersionMismatch represents the installed agent reporting another version than the target version
+	ErrorInstalledVersionMismatch ErrorCode = "ErrorInstalledVersionMismatch"
+
 	SelfUpdatePrefix = "SelfUpdate_"
 
 	// we have same below fields in processor package without underscore
@@ -293,6 +302,9 @@ const (
 	// WarnInactiveVersion represents the warning message when inactive version is used for update
 	WarnInactiveVersion string = "InactiveAgentVersion"
 
+	// WarnAlreadyAtTargetVersion represents the warning message when the agent is already at the target version
+	WarnAlreadyAtTargetVersion string = "AlreadyAtTargetVersion"
+
 	// WarnUpdaterLockFail represents warning message that the lock could not be acquired because of system issues
 	WarnUpdaterLockFail string = "WarnUpdaterLockFail"
 )
@@ -369,6 +381,7 @@ var openFile = os.OpenFile
 var execCommand = exec.Command
 var cmdStart = (*exec.Cmd).Start
 var cmdOutput = (*exec.Cmd).Output
+var downloadArtifact = artifact.Download
 var isUsingSystemD map[string]string
 var once sync.Once
 
@@ -1220,7 +1233,7 @@ func (util *Utility) DownloadManifestFile(log log.T, updateDownloadFolder string
 		DestinationDirectory: updateDownloadFolder,
 	}
 
-	downloadOutput, err = artifact.Download(log, downloadInput)
+	downloadOutput, err = downloadArtifact(log, downloadInput)
 	if err != nil ||
 		downloadOutput.IsHashMatched == false ||
 		downloadOutput.LocalFilePath == "" {
@@ -1234,6 +1247,11 @@ func (util *Utility) DownloadManifestFile(log log.T, updateDownloadFolder string
 	log.Infof("Local file path : %v", downloadOutput.LocalFilePath)
 	log.Infof("Is updated: %v", downloadOutput.IsUpdated)
 	log.Infof("Is hash matched %v", downloadOutput.IsHashMatched)
+
+	if err = verifyDownloadedManifest(log, updateDownloadFolder, manifestUrl, downloadOutput.LocalFilePath); err != nil {
+		return nil, "", err
+	}
+
 	return &downloadOutput, manifestUrl, nil
 }
 
@@ -106,6 +106,57 @@ func TestVersionStringCompareWithError(t *testing.T) {
 	}
 }
 
+// TestIsVersionInRange tests version range matching
+func TestIsVersionInRange(t *testing.T) {
+	testCases := []struct {
+		version    string
+		expression string
+		result     bool
+	}{
+		{"3.1.0.0", "3.1.x", true},
+		{"3.1.1004.0", "3.1.x", true},
+		{"3.2.0.0", "3.1.x", false},
+		{"3.0.1390.0", "3.1.x", false},
+		{"3.1.1004.0", "3.x", true},
+		{"3.1.1004.0", ">=3.1.0,<3.2.0", true},
+		{"3.2.0", ">=3.1.0,<3.2.0", false},
+		{"3.0.9", ">=3.1.0,<3.2.0", false},
+		{"3.1.0", ">=3.1.0, <3.2.0", true},
+		{"3.1.0", ">3.1.0", false},
+		{"3.1.0", "<=3.1.0", true},
+		{"3.1.0", "=3.1.0", true},
+		{"3.1.0", "3.1.0", true},
+		{"3.1.1", "3.1.0", false},
+	}
+
+	for _, test := range testCases {
+		inRange, err := IsVersionInRange(test.version, test.expression)
+		assert.NoError(t, err)
+		assert.Equal(t, test.result, inRange, "%v in %v", test.version, test.expression)
+	}
+}
+
+// TestIsVersionInRangeWithError tests malformed version ranges are rejected
+func TestIsVersionInRangeWithError(t *testing.T) {
+	testCases := []struct {
+		version    string
+		expression string
+	}{
+		{"3.1.0", ""},
+		{"3.1.0", ">=3.1.0,"},
+		{"3.1.0", "~3.1"},
+		{"3.1.0", ">=3.1.x"},
+		{"3.1.0", "3.x.1"},
+		{"3.1.0", ">=latest"},
+		{"Invalid version", "3.1.x"},
+	}
+
+	for _, test := range testCases {
+		_, err := IsVersionInRange(test.version, test.expression)
+		assert.Error(t, err, test.expression)
+	}
+}
+
 func TestCreateInstanceContext(t *testing.T) {
 	testCases := []testInstanceContext{
 		{"us-east-1", PlatformAmazonLinux, nil, "2015.9", nil, PlatformLinux, PlatformLinux, false},
@@ -762,3 +813,77 @@ func (p *testProcess) Processes() ([]executor.OsProcess, error) {
 	allProcess = append(allProcess, process)
 	return allProcess, nil
 }
+
+func TestUpdateArgsRoundTrip(t *testing.T) {
+	testCases := []UpdateArgs{
+		{
+			Update:            true,
+			SourceVersion:     "2.3.50.0",
+			SourceLocation:    "https://source/amazon-ssm-agent.tar.gz",
+			SourceHash:        "sourcehash",
+			TargetVersion:     "3.0.0.0",
+			TargetLocation:    "https://target/amazon-ssm-agent.tar.gz",
+			TargetHash:        "targethash",
+			HashType:          HashType,
+			PackageName:       "amazon-ssm-agent",
+			MessageID:         "aws.ssm.message-id",
+			StdoutFileName:    "stdout",
+			StderrFileName:    "stderr",
+			OutputKeyPrefix:   "prefix",
+			OutputBucketName:  "bucket",
+			ManifestURL:       "https://manifest/ssm-agent-manifest.json",
+			ValidateVersion:   ">=3.0",
+			RollbackOnFailure: true,
+		},
+		{
+			Update:            true,
+			SourceVersion:     "3.0.0.0",
+			SourceLocation:    "https://source",
+			TargetVersion:     "3.0.0.0",
+			TargetLocation:    "https://target",
+			HashType:          "md5",
+			RollbackOnFailure: false,
+			Force:             true,
+		},
+		{
+			Update:            true,
+			SelfUpdate:        true,
+			SourceVersion:     "3.0.0.0",
+			HashType:          HashType,
+			ManifestURL:       "https://manifest",
+			RollbackOnFailure: true,
+		},
+	}
+
+	for _, args := range testCases {
+		parsed, err := ParseUpdateArgs(args.ToArgs())
+		assert.NoError(t, err)
+		assert.Equal(t, args, parsed)
+	}
+}
+
+func TestParseUpdateArgsDefaults(t *testing.T) {
+	parsed, err := ParseUpdateArgs([]string{"-" + UpdateCmd,
+		"-" + SourceVersionCmd, "1.0.0.0", "-" + SourceLocationCmd, "https://source",
+		"-" + TargetVersionCmd, "2.0.0.0", "-" + TargetLocationCmd, "https://target"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, HashType, parsed.HashType)
+	assert.True(t, parsed.RollbackOnFailure)
+	assert.False(t, parsed.Force)
+}
+
+func TestParseUpdateArgsMissingRequired(t *testing.T) {
+	testCases := [][]string{
+		{"-" + SourceVersionCmd, "1.0.0.0"},
+		{"-" + UpdateCmd, "-" + TargetVersionCmd, "2.0.0.0", "-" + TargetLocationCmd, "https://target"},
+		{"-" + UpdateCmd, "-" + SourceVersionCmd, "1.0.0.0", "-" + SourceLocationCmd, "https://source"},
+		{"-" + UpdateCmd, "-" + SelfUpdateCmd},
+		{"-" + UpdateCmd, "-unknown"},
+	}
+
+	for _, args := range testCases {
+		_, err := ParseUpdateArgs(args)
+		assert.Error(t, err, "%v", args)
+	}
+}
@@ -41,6 +41,9 @@ const (
 	// TargetVersionCmd represents the command argument for target version
 	TargetVersionCmd = "target-version"
 
+	// HashTypeCmd represents the command argument for the hash algorithm of the source and target hash values
+	HashTypeCmd = "hash-type"
+
 	// TargetLocationCmd represents the command argument for target location
 	TargetLocationCmd = "target-location"
 
@@ -70,6 +73,15 @@ const (
 
 	// SelfUpdateCmd represents the command is generated by self update component
 	SelfUpdateCmd = "selfupdate"
+
+	// RollbackOnFailureCmd represents the command argument for restoring the source version when the update fails
+	RollbackOnFailureCmd = "rollback-on-failure"
+
+	// ValidateVersionCmd represents the command argument for the version range the target version has to fall within
+	ValidateVersionCmd = "validate-version"
+
+	// ForceCmd represents the command argument for updating even when the target version is already installed
+	ForceCmd = "force"
 )
 
 const (
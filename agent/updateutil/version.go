@@ -17,6 +17,7 @@ package updateutil
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -71,3 +72,111 @@ func versionOrdinal(version string) (string, error) {
 	}
 	return string(vo), nil
 }
+
+// versionRangeBound is a single comparison a version has to satisfy to fall within a version range
+type versionRangeBound struct {
+	operator string
+	version  string
+}
+
+// VersionRange is a set of bounds a version has to satisfy, e.g. ">=3.1.0,<3.2.0" or "3.1.x"
+type VersionRange struct {
+	bounds []versionRangeBound
+}
+
+// versionRangeOperators lists the supported operators, longest first so that ">=" isn't taken for ">"
+var versionRangeOperators = []string{">=", "<=", ">", "<", "="}
+
+var (
+	exactVersionPattern    = regexp.MustCompile(`^\d+(\.\d+)*$`)
+	wildcardVersionPattern = regexp.MustCompile(`^(\d+(\.\d+)*)\.[xX*]$`)
+)
+
+// ParseVersionRange parses a comma separated list of bounds, all of which a version has to satisfy.
+// A bound is a version prefixed by one of >=, <=, >, < or =, a version without operator for an exact match,
+// or a version ending in x, e.g. 3.1.x, that matches every version starting with 3.1.
+func ParseVersionRange(expression string) (versionRange *VersionRange, err error) {
+	versionRange = &VersionRange{}
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("Invalid version range %q, range is empty", expression)
+	}
+	for _, part := range strings.Split(expression, ",") {
+		var bounds []versionRangeBound
+		if bounds, err = parseVersionRangeBounds(strings.TrimSpace(part)); err != nil {
+			return nil, fmt.Errorf("Invalid version range %q, %v", expression, err)
+		}
+		versionRange.bounds = append(versionRange.bounds, bounds...)
+	}
+	return versionRange, nil
+}
+
+// parseVersionRangeBounds parses a single bound of a version range, wildcards result in a lower and an upper bound
+func parseVersionRangeBounds(part string) ([]versionRangeBound, error) {
+	if matches := wildcardVersionPattern.FindStringSubmatch(part); matches != nil {
+		components := strings.Split(matches[1], ".")
+		last, err := strconv.Atoi(components[len(components)-1])
+		if err != nil {
+			return nil, fmt.Errorf("bound %q is not a valid version", part)
+		}
+		components[len(components)-1] = strconv.Itoa(last + 1)
+		return []versionRangeBound{
+			{operator: ">=", version: matches[1]},
+			{operator: "<", version: strings.Join(components, ".")},
+		}, nil
+	}
+
+	operator := "="
+	for _, candidate := range versionRangeOperators {
+		if strings.HasPrefix(part, candidate) {
+			operator = candidate
+			part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+			break
+		}
+	}
+	if !exactVersionPattern.MatchString(part) {
+		return nil, fmt.Errorf("bound %q is not a valid version", part)
+	}
+	return []versionRangeBound{{operator: operator, version: part}}, nil
+}
+
+// Contains returns true if the version satisfies all bounds of the range
+func (versionRange *VersionRange) Contains(version string) (bool, error) {
+	if !exactVersionPattern.MatchString(strings.TrimSpace(version)) {
+		return false, fmt.Errorf("Invalid version string %v", version)
+	}
+	for _, bound := range versionRange.bounds {
+		result, err := VersionCompare(version, bound.version)
+		if err != nil {
+			return false, err
+		}
+		if !bound.satisfiedBy(result) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// satisfiedBy returns true if the result of comparing a version with the bound satisfies the bound
+func (bound versionRangeBound) satisfiedBy(result int) bool {
+	switch bound.operator {
+	case ">=":
+		return result >= 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case "<":
+		return result < 0
+	default:
+		return result == 0
+	}
+}
+
+// IsVersionInRange returns true if the version falls within the version range expression
+func IsVersionInRange(version string, expression string) (bool, error) {
+	versionRange, err := ParseVersionRange(expression)
+	if err != nil {
+		return false, err
+	}
+	return versionRange.Contains(version)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/managedInstances/registration"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/ssm/anonauth"
+	"github.com/aws/amazon-ssm-agent/agent/version"
 )
 
 // parseFlags displays flags and handles them
@@ -56,11 +57,17 @@ func parseFlags(log logger.T) {
 	// force flag
 	flag.BoolVar(&force, "y", false, "")
 
+	// version flag, used by the updater to verify the installed version
+	flag.BoolVar(&printVersion, versionFlag, false, "")
+
 	flag.Parse()
 
 	if flag.NFlag() > 0 {
 		exitCode := 1
-		if register {
+		if printVersion {
+			fmt.Println(version.Version)
+			exitCode = 0
+		} else if register {
 			exitCode = processRegistration(log)
 		} else if fpFlag {
 			exitCode = processFingerprint(log)
@@ -82,6 +89,7 @@ func flagUsage() {
 	fmt.Fprintln(os.Stderr, "\t\t-region\tSSM region       \t(REQUIRED)")
 	fmt.Fprintln(os.Stderr, "\n\t\t-clear\tClears the previously saved SSM registration")
 	fmt.Fprintln(os.Stderr, "\n\t-y\tAnswer yes for all questions")
+	fmt.Fprintln(os.Stderr, "\n\t-version\tPrints the version of the agent")
 }
 
 // processRegistration handles flags related to the registration category
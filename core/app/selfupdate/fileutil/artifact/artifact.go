@@ -44,6 +44,23 @@ type DownloadInput struct {
 	SourceURL            string
 	DestinationDirectory string
 	SourceChecksums      map[string]string
+	Progress             fileutil.ProgressFunc
+}
+
+// downloadError is a failed download that may succeed when it's attempted again
+type downloadError struct {
+	err error
+}
+
+func (e *downloadError) Error() string {
+	return e.err.Error()
+}
+
+// IsRetryable returns true if the download failed with an error that may not occur again, e.g. a network error or
+// a server error. Errors such as a missing file or a hash mismatch are not retryable.
+func IsRetryable(err error) bool {
+	_, ok := err.(*downloadError)
+	return ok
 }
 
 type Artifact struct {
@@ -56,6 +73,7 @@ type IArtifact interface {
 	Download(input DownloadInput) (output DownloadOutput, err error)
 	VerifyHash(input DownloadInput, output DownloadOutput) (bool, error)
 	Uncompress(src, dest string) error
+	UncompressFormat(format, src, dest string, progress fileutil.ProgressFunc) error
 }
 
 func NewSelfUpdateArtifact(log log.T, appConfig appconfig.SsmagentConfig) *Artifact {
@@ -118,7 +136,7 @@ func (artifact *Artifact) Download(input DownloadInput) (output DownloadOutput,
 	var tempOutput DownloadOutput
 
 	artifact.log.Debugf("Try to download from http/https")
-	tempOutput, err = artifact.httpDownload(input.SourceURL, output.LocalFilePath)
+	tempOutput, err = artifact.httpDownload(input.SourceURL, output.LocalFilePath, input.Progress)
 	output = tempOutput
 
 	if err != nil {
@@ -133,8 +151,8 @@ func (artifact *Artifact) Download(input DownloadInput) (output DownloadOutput,
 	return
 }
 
-// httpDownload attempts to download a file via http/s call
-func (artifact *Artifact) httpDownload(fileURL string, destFile string) (output DownloadOutput, err error) {
+// httpDownload attempts to download a file via http/s call, progress is notified as the body is read
+func (artifact *Artifact) httpDownload(fileURL string, destFile string, progress fileutil.ProgressFunc) (output DownloadOutput, err error) {
 	artifact.log.Debugf("attempting to download as http/https download %v", destFile)
 	eTagFile := destFile + ".etag"
 	var check http.Client
@@ -166,6 +184,7 @@ func (artifact *Artifact) httpDownload(fileURL string, destFile string) (output
 		artifact.log.Debug("failed to download from http/https, ", err)
 		artifact.fileutil.DeleteFile(destFile)
 		artifact.fileutil.DeleteFile(eTagFile)
+		err = &downloadError{err: err}
 		return
 	}
 
@@ -179,6 +198,9 @@ func (artifact *Artifact) httpDownload(fileURL string, destFile string) (output
 		artifact.fileutil.DeleteFile(destFile)
 		artifact.fileutil.DeleteFile(eTagFile)
 		err = fmt.Errorf("http request failed. status:%v statuscode:%v", resp.Status, resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			err = &downloadError{err: err}
+		}
 		return
 	}
 	defer resp.Body.Close()
@@ -191,12 +213,14 @@ func (artifact *Artifact) httpDownload(fileURL string, destFile string) (output
 			return
 		}
 	}
-	_, err = artifact.fileCopy(destFile, resp.Body)
+	_, err = artifact.fileCopy(destFile, fileutil.NewProgressReader(resp.Body, fileutil.ProgressStageDownload, resp.ContentLength, progress))
 	if err == nil {
 		output.LocalFilePath = destFile
 		output.IsUpdated = true
 	} else {
 		artifact.log.Errorf("failed to write destFile %v, %v ", destFile, err)
+		// the connection may have dropped while reading the body
+		err = &downloadError{err: err}
 	}
 	return
 }
@@ -318,3 +342,7 @@ func (artifact *Artifact) md5HashValue(filePath string) (hash string, err error)
 func (artifact *Artifact) Uncompress(src, dest string) error {
 	return artifact.fileutil.Uncompress(artifact.log, src, dest)
 }
+
+func (artifact *Artifact) UncompressFormat(format, src, dest string, progress fileutil.ProgressFunc) error {
+	return artifact.fileutil.UncompressFormat(artifact.log, format, src, dest, progress)
+}
@@ -15,8 +15,10 @@
 package fileutil
 
 import (
+	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -27,6 +29,14 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
 
+const (
+	// CompressFormatZip represents zip compressed packages
+	CompressFormatZip = "zip"
+
+	// CompressFormatTarGz represents gzip compressed tar packages
+	CompressFormatTarGz = "tar.gz"
+)
+
 type Fileutil struct {
 	log log.T
 	fs  IosFS
@@ -134,9 +144,89 @@ func (futl *Fileutil) isUnderDir(childPath, parentDirPath string) bool {
 	return strings.HasPrefix(filepath.Clean(childPath)+string(filepath.Separator), filepath.Clean(parentDirPath)+string(filepath.Separator))
 }
 
+// UncompressFormat extracts the installation package using the archive reader matching the given compress format,
+// progress is notified as the package is extracted
+func (futl *Fileutil) UncompressFormat(log log.T, format, src, dest string, progress ProgressFunc) error {
+	if progress == nil {
+		progress = NoProgress
+	}
+	switch strings.ToLower(format) {
+	case CompressFormatZip:
+		return futl.unzip(src, dest, progress)
+	case CompressFormatTarGz, "tgz":
+		return futl.Untar(log, src, dest, progress)
+	default:
+		return fmt.Errorf("unsupported compress format %v for %v", format, src)
+	}
+}
+
+// Untar extracts the gzip compressed tar installation package,
+// progress is notified with the compressed bytes read out of the package size
+func (futl *Fileutil) Untar(log log.T, src, dest string, progress ProgressFunc) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	size := int64(-1)
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+
+	gr, err := gzip.NewReader(NewProgressReader(file, ProgressStageExtract, size, progress))
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	os.MkdirAll(dest, appconfig.ReadWriteExecuteAccess)
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		itemPath := dest + string(os.PathSeparator) + hdr.Name
+		if !futl.isUnderDir(itemPath, dest) {
+			return fmt.Errorf("%v attepts to place files outside %v subtree", file.Name(), dest)
+		}
+		if hdr.FileInfo().IsDir() {
+			os.MkdirAll(itemPath, hdr.FileInfo().Mode())
+		} else {
+			mode := hdr.FileInfo().Mode()
+			log.Debugf("Uncompressing file %v with %v mode", itemPath, mode.Perm().String())
+			fw, err := os.OpenFile(itemPath, appconfig.FileFlagsCreateOrTruncate, mode)
+			if err != nil {
+				return err
+			}
+			defer fw.Close()
+
+			_, err = io.Copy(fw, tr)
+			if err != nil {
+				return err
+			}
+
+			if err = os.Chmod(itemPath, mode); err != nil {
+				return err
+			}
+			log.Debugf("Uncompressed file mode is %v", futl.GetFileMode(itemPath).Perm().String())
+		}
+	}
+	return nil
+}
+
 // Unzip unzips the installation package (using platform agnostic zip functionality)
 // For platform specific implementation that uses tar.gz on Linux, use Uncompress
 func (futl *Fileutil) Unzip(src, dest string) error {
+	return futl.unzip(src, dest, NoProgress)
+}
+
+// unzip extracts the zip package, progress is notified with the entries extracted out of the entry count
+func (futl *Fileutil) unzip(src, dest string, progress ProgressFunc) error {
 	r, err := zip.OpenReader(src)
 	if err != nil {
 		return err
@@ -186,11 +276,12 @@ func (futl *Fileutil) Unzip(src, dest string) error {
 		}
 		return nil
 	}
-	for _, f := range r.File {
+	for i, f := range r.File {
 		err := extractAndWriteFile(f)
 		if err != nil {
 			return err
 		}
+		progress(ProgressStageExtract, int64(i+1), int64(len(r.File)))
 	}
 
 	return nil
@@ -135,6 +135,12 @@ func (suite *FileUtilTestSuite) TestUnderDir() {
 	assert.True(suite.T(), suite.fileutil.isUnderDir(`~/../../foo`, `../foo`))
 }
 
+func (suite *FileUtilTestSuite) TestUncompressFormatUnsupported() {
+	err := suite.fileutil.UncompressFormat(suite.log, "rar", filepath.Join("testdata", "test.txt"), suite.T().TempDir(), nil)
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "unsupported compress format rar")
+}
+
 //Execute the test suite
 func TestFileUtilTestSuite(t *testing.T) {
 	suite.Run(t, new(FileUtilTestSuite))
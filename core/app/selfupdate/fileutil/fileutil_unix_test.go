@@ -0,0 +1,92 @@
+// Copyright 2020 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin freebsd linux netbsd openbsd
+
+package fileutil
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0750, Size: int64(len(content))}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+}
+
+func TestUncompressTarGz(t *testing.T) {
+	logger := log.NewMockLog()
+	futl := NewFileUtil(logger)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "updater.tar.gz")
+	dest := filepath.Join(dir, "out")
+	writeTarGz(t, src, map[string]string{"updater": "binary"})
+
+	assert.NoError(t, futl.Uncompress(logger, src, dest))
+
+	content, err := ioutil.ReadFile(filepath.Join(dest, "updater"))
+	assert.NoError(t, err)
+	assert.Equal(t, "binary", string(content))
+}
+
+func TestUncompressRejectsZipOnUnix(t *testing.T) {
+	logger := log.NewMockLog()
+	futl := NewFileUtil(logger)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "updater.zip")
+	assert.NoError(t, ioutil.WriteFile(src, []byte("PK not a tarball"), 0600))
+
+	assert.Error(t, futl.Uncompress(logger, src, filepath.Join(dir, "out")))
+}
+
+func TestUncompressTarGzReportsProgress(t *testing.T) {
+	logger := log.NewMockLog()
+	futl := NewFileUtil(logger)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "updater.tar.gz")
+	writeTarGz(t, src, map[string]string{"updater": "binary"})
+	info, err := os.Stat(src)
+	assert.NoError(t, err)
+
+	var completed, total int64
+	err = futl.UncompressFormat(logger, CompressFormatTarGz, src, filepath.Join(dir, "out"), func(stage string, done, size int64) {
+		assert.Equal(t, ProgressStageExtract, stage)
+		assert.True(t, done > completed)
+		completed, total = done, size
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, info.Size(), total)
+	assert.Equal(t, info.Size(), completed)
+}
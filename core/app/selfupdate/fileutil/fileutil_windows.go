@@ -20,7 +20,10 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/log"
 )
 
+// DefaultCompressFormat is the compress format of installation packages on this platform
+const DefaultCompressFormat = CompressFormatZip
+
 // Uncompress unzips the installation package
 func (futl *Fileutil) Uncompress(log log.T, src, dest string) error {
-	return futl.Unzip(src, dest)
+	return futl.UncompressFormat(log, DefaultCompressFormat, src, dest, NoProgress)
 }
@@ -0,0 +1,58 @@
+// Copyright 2020 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build windows
+
+package fileutil
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+}
+
+func TestUncompressZip(t *testing.T) {
+	logger := log.NewMockLog()
+	futl := NewFileUtil(logger)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "updater.zip")
+	dest := filepath.Join(dir, "out")
+	writeZip(t, src, map[string]string{"updater.exe": "binary"})
+
+	assert.NoError(t, futl.Uncompress(logger, src, dest))
+
+	content, err := ioutil.ReadFile(filepath.Join(dest, "updater.exe"))
+	assert.NoError(t, err)
+	assert.Equal(t, "binary", string(content))
+}
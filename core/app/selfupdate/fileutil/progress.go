@@ -0,0 +1,76 @@
+// Copyright 2020 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fileutil
+
+import (
+	"io"
+)
+
+const (
+	// ProgressStageDownload reports the bytes of the package downloaded
+	ProgressStageDownload = "download"
+
+	// ProgressStageExtract reports the part of the package extracted
+	ProgressStageExtract = "extract"
+
+	// progressMinInterval is the fewest bytes read between two progress reports
+	progressMinInterval = 64 * 1024
+)
+
+// ProgressFunc is notified with the completed and total units of a stage, total is -1 when it's unknown
+type ProgressFunc func(stage string, completed, total int64)
+
+// NoProgress is the default ProgressFunc which discards all progress updates
+func NoProgress(stage string, completed, total int64) {}
+
+// ProgressReader reports the bytes read from the underlying reader,
+// at most once per percent of the total and once at the end of the stream
+type ProgressReader struct {
+	reader    io.Reader
+	stage     string
+	total     int64
+	completed int64
+	reported  int64
+	interval  int64
+	progress  ProgressFunc
+}
+
+// NewProgressReader wraps reader to report progress of the stage, nil progress discards the updates
+func NewProgressReader(reader io.Reader, stage string, total int64, progress ProgressFunc) *ProgressReader {
+	if progress == nil {
+		progress = NoProgress
+	}
+	interval := int64(progressMinInterval)
+	if total/100 > interval {
+		interval = total / 100
+	}
+	return &ProgressReader{
+		reader:   reader,
+		stage:    stage,
+		total:    total,
+		interval: interval,
+		progress: progress,
+	}
+}
+
+// Read reads from the underlying reader and reports progress once enough bytes were read
+func (r *ProgressReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.completed += int64(n)
+	if r.completed > r.reported && (r.completed-r.reported >= r.interval || err == io.EOF) {
+		r.reported = r.completed
+		r.progress(r.stage, r.completed, r.total)
+	}
+	return
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	logger "github.com/aws/amazon-ssm-agent/agent/log"
 	"github.com/aws/amazon-ssm-agent/agent/platform"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 	"github.com/aws/amazon-ssm-agent/agent/updateutil"
 	"github.com/aws/amazon-ssm-agent/agent/version"
 	"github.com/aws/amazon-ssm-agent/core/app/context"
@@ -57,6 +58,11 @@ type SelfUpdate struct {
 	fileManager          artifact.IArtifact
 	filsys               fileutil.IFileutil
 	updateSchedulerTimer chan bool
+	downloadMaxAttempts  int
+	downloadRetryDelay   time.Duration
+	downloadTimeout      time.Duration
+	clock                times.Clock
+	progress             fileutil.ProgressFunc
 }
 
 var regionGetter = platform.Region
@@ -85,12 +91,18 @@ func NewSelfUpdater(context context.ICoreAgentContext) *SelfUpdate {
 	selfupdateContext.Log().Info("Initializing self update ...")
 	fileManager := artifact.NewSelfUpdateArtifact(selfupdateContext.Log(), *context.AppConfig())
 	fileutl := fileutil.NewFileUtil(context.Log())
+	agentConfig := context.AppConfig().Agent
 
 	selfUpdateProvider := SelfUpdate{
 		context:              selfupdateContext,
 		fileManager:          fileManager,
 		filsys:               fileutl,
 		updateSchedulerTimer: make(chan bool, 1),
+		downloadMaxAttempts:  agentConfig.SelfUpdateDownloadMaxAttempts,
+		downloadRetryDelay:   time.Duration(agentConfig.SelfUpdateDownloadRetryDelaySeconds) * time.Second,
+		downloadTimeout:      time.Duration(agentConfig.SelfUpdateDownloadTimeoutSeconds) * time.Second,
+		clock:                times.DefaultClock,
+		progress:             fileutil.NoProgress,
 	}
 	updateInitialize = selfUpdateProvider.init
 	updateDownloadResource = selfUpdateProvider.downloadResource
@@ -158,6 +170,14 @@ func (u *SelfUpdate) Stop() {
 	}
 }
 
+// SetProgress registers the callback notified while the updater is downloaded and extracted
+func (u *SelfUpdate) SetProgress(progress fileutil.ProgressFunc) {
+	if progress == nil {
+		progress = fileutil.NoProgress
+	}
+	u.progress = progress
+}
+
 // Periodically Pulling manifest file and updater from regional S3 bucket.
 // Unzip updater and execute the updater
 func (u *SelfUpdate) updateFromS3WithDelay() {
@@ -289,6 +309,7 @@ func (u *SelfUpdate) downloadResource(region string) (err error) {
 	downloadInput := artifact.DownloadInput{
 		SourceURL:            sourceURL,
 		DestinationDirectory: downloadDirectory,
+		Progress:             u.progress,
 	}
 
 	if updaterDownloadOutput, err = u.downloadResourceFromS3(downloadInput); err != nil {
@@ -306,9 +327,18 @@ func (u *SelfUpdate) downloadResourceFromS3(
 	downloadInput artifact.DownloadInput) (downloadOutput artifact.DownloadOutput, err error) {
 	log := u.context.Log()
 
-	downloadOutput, err = u.fileManager.Download(downloadInput)
-	if err != nil {
-		return downloadOutput, fmt.Errorf("failed to download Download context %v, %v", downloadInput, err)
+	deadline := u.clock.Now().Add(u.downloadTimeout)
+	delay := u.downloadRetryDelay
+	for attempt := 1; ; attempt++ {
+		if downloadOutput, err = u.fileManager.Download(downloadInput); err == nil {
+			break
+		}
+		if !artifact.IsRetryable(err) || attempt >= u.downloadMaxAttempts || u.clock.Now().Add(delay).After(deadline) {
+			return downloadOutput, fmt.Errorf("failed to download Download context %v after %v attempts, %v", downloadInput, attempt, err)
+		}
+		log.Warnf("Attempt %v to download %v failed, retrying in %v, %v", attempt, downloadInput.SourceURL, delay, err)
+		<-u.clock.After(delay)
+		delay *= 2
 	}
 
 	log.Debugf("Succeed to download the contents")
@@ -378,7 +408,7 @@ func (u *SelfUpdate) unCompress(log logger.T,
 	dest := filepath.Join(appconfig.UpdaterArtifactsRoot, PackageName, PackageVersion)
 	log.Debugf("Uncompress destination file path is %v", dest)
 	log.Debugf("Source file path is %v", downloadOutput.LocalFilePath)
-	if uncompressErr := u.fileManager.Uncompress(downloadOutput.LocalFilePath, dest); uncompressErr != nil {
+	if uncompressErr := u.fileManager.UncompressFormat(CompressFormat, downloadOutput.LocalFilePath, dest, u.progress); uncompressErr != nil {
 		return fmt.Errorf("Failed to uncompress updater package for self update, %v, %v\n",
 			downloadOutput.LocalFilePath,
 			uncompressErr.Error())
@@ -17,15 +17,26 @@ package selfupdate
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
 	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/times"
 	context "github.com/aws/amazon-ssm-agent/core/app/context/mocks"
+	"github.com/aws/amazon-ssm-agent/core/app/selfupdate/fileutil"
+	"github.com/aws/amazon-ssm-agent/core/app/selfupdate/fileutil/artifact"
 	lock "github.com/nightlyone/lockfile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -41,7 +52,8 @@ type SelfUpdateTestSuite struct {
 // SetupTest will initialized the object for each test case before test function execution
 func (suite *SelfUpdateTestSuite) SetupTest() {
 	suite.logMock = log.NewMockLog()
-	suite.appconfigMock = &appconfig.SsmagentConfig{}
+	config := appconfig.DefaultConfig()
+	suite.appconfigMock = &config
 	suite.contextMock = &context.ICoreAgentContext{}
 	suite.contextMock.On("With", "[SelfUpdate]").Return(suite.contextMock)
 	suite.contextMock.On("Log").Return(suite.logMock)
@@ -228,6 +240,138 @@ func (suite *SelfUpdateTestSuite) TestLockWithMultipleUpdates() {
 	}
 }
 
+// flakyServer returns a server that fails the first failures requests with the status and serves content afterwards
+func flakyServer(failures int, status int, content string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&requests, 1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	return server, &requests
+}
+
+// stubClock makes the self updater wait for retries without delay, the returned clock records the delays
+func (suite *SelfUpdateTestSuite) stubClock() *times.MockedClock {
+	elapsed := make(chan struct{})
+	close(elapsed)
+	clock := times.NewMockedClock()
+	clock.On("Now").Return(time.Now())
+	clock.On("After", mock.Anything).Return(elapsed)
+	suite.selfUpdater.clock = clock
+	return clock
+}
+
+func (suite *SelfUpdateTestSuite) downloadFrom(server *httptest.Server) (artifact.DownloadOutput, error) {
+	dir, err := ioutil.TempDir("", "selfupdate")
+	assert.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+	output, err := suite.selfUpdater.downloadResourceFromS3(artifact.DownloadInput{
+		SourceURL:            server.URL + "/amazon-ssm-agent-updater.tar.gz",
+		DestinationDirectory: dir,
+	})
+	if err == nil {
+		content, readErr := ioutil.ReadFile(output.LocalFilePath)
+		assert.NoError(suite.T(), readErr)
+		assert.Equal(suite.T(), "updater", string(content))
+	}
+	return output, err
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadRetrySettingsAreConfigured() {
+	suite.appconfigMock.Agent.SelfUpdateDownloadMaxAttempts = 3
+	suite.appconfigMock.Agent.SelfUpdateDownloadRetryDelaySeconds = 10
+	suite.appconfigMock.Agent.SelfUpdateDownloadTimeoutSeconds = 60
+
+	selfUpdater := NewSelfUpdater(suite.contextMock)
+
+	assert.Equal(suite.T(), 3, selfUpdater.downloadMaxAttempts)
+	assert.Equal(suite.T(), 10*time.Second, selfUpdater.downloadRetryDelay)
+	assert.Equal(suite.T(), time.Minute, selfUpdater.downloadTimeout)
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadRetriesServerErrors() {
+	server, requests := flakyServer(2, http.StatusServiceUnavailable, "updater")
+	defer server.Close()
+	clock := suite.stubClock()
+
+	_, err := suite.downloadFrom(server)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int32(3), atomic.LoadInt32(requests))
+	clock.AssertCalled(suite.T(), "After", 2*time.Second)
+	clock.AssertCalled(suite.T(), "After", 4*time.Second)
+	clock.AssertNumberOfCalls(suite.T(), "After", 2)
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadGivesUpAfterMaxAttempts() {
+	server, requests := flakyServer(appconfig.DefaultSelfUpdateDownloadMaxAttempts, http.StatusInternalServerError, "updater")
+	defer server.Close()
+	suite.stubClock()
+
+	_, err := suite.downloadFrom(server)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), int32(appconfig.DefaultSelfUpdateDownloadMaxAttempts), atomic.LoadInt32(requests))
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadGivesUpAfterTimeout() {
+	server, requests := flakyServer(appconfig.DefaultSelfUpdateDownloadMaxAttempts, http.StatusInternalServerError, "updater")
+	defer server.Close()
+	suite.stubClock()
+	suite.selfUpdater.downloadTimeout = 0
+
+	_, err := suite.downloadFrom(server)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(requests))
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadDoesNotRetryMissingFile() {
+	server, requests := flakyServer(1, http.StatusNotFound, "updater")
+	defer server.Close()
+	suite.stubClock()
+
+	_, err := suite.downloadFrom(server)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(requests))
+}
+
+func (suite *SelfUpdateTestSuite) TestDownloadReportsIncreasingProgress() {
+	content := strings.Repeat("updater", 256*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "selfupdate")
+	assert.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+
+	var completed []int64
+	suite.selfUpdater.SetProgress(func(stage string, done, total int64) {
+		assert.Equal(suite.T(), fileutil.ProgressStageDownload, stage)
+		assert.Equal(suite.T(), int64(len(content)), total)
+		completed = append(completed, done)
+	})
+	_, err = suite.selfUpdater.downloadResourceFromS3(artifact.DownloadInput{
+		SourceURL:            server.URL + "/amazon-ssm-agent-updater.tar.gz",
+		DestinationDirectory: dir,
+		Progress:             suite.selfUpdater.progress,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), len(completed) > 1)
+	assert.True(suite.T(), len(completed) <= 101)
+	for i := 1; i < len(completed); i++ {
+		assert.True(suite.T(), completed[i] > completed[i-1])
+	}
+	assert.Equal(suite.T(), int64(len(content)), completed[len(completed)-1])
+}
+
 //Execute the test suite
 func TestSelfUpdateTestSuite(t *testing.T) {
 	suite.Run(t, new(SelfUpdateTestSuite))
@@ -21,6 +21,8 @@ package selfupdate
 import (
 	"os/exec"
 	"syscall"
+
+	"github.com/aws/amazon-ssm-agent/core/app/selfupdate/fileutil"
 )
 
 const (
@@ -47,7 +49,7 @@ const (
 	ManifestFileUrlCmd = "manifest.url"
 
 	// suffix for updater compress formate
-	CompressFormat = "tar.gz"
+	CompressFormat = fileutil.DefaultCompressFormat
 )
 
 func prepareProcess(command *exec.Cmd) {
@@ -18,7 +18,12 @@
 
 package selfupdate
 
-import "os/exec"
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/aws/amazon-ssm-agent/core/app/selfupdate/fileutil"
+)
 
 const (
 
@@ -47,8 +52,10 @@ const (
 	ManifestFileUrlCmd = "manifest-url"
 
 	// suffix for updater compress formate
-	CompressFormat = "zip"
+	CompressFormat = fileutil.DefaultCompressFormat
 )
 
 func prepareProcess(command *exec.Cmd) {
+	// start the updater in its own process group so that console signals sent to the agent don't reach it
+	command.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
 }
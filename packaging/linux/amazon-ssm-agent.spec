@@ -17,6 +17,7 @@ This package provides Amazon SSM Agent for managing EC2 Instances using SSM APIs
 %defattr(-,root,root,-)
 /etc/amazon/ssm/amazon-ssm-agent.json.template
 /etc/amazon/ssm/seelog.xml.template
+/etc/amazon/ssm/ssm-agent-manifest-public-key.pem
 /usr/bin/amazon-ssm-agent
 /usr/bin/ssm-agent-worker
 /usr/bin/ssm-cli